@@ -0,0 +1,97 @@
+// Package config implements the commands to validate and inspect the effective configuration
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+
+	backendconfig "github.com/opentofu/registry-ui/pkg/config"
+)
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Validate and inspect the configuration",
+		Description: `Loads the configuration like every other command: the file given with --config (or config.yaml in the
+working directory) overridden by REGISTRY_ environment variables. Variables ending in _FILE read the value from a file.`,
+		Commands: []*cli.Command{
+			{
+				Name:  "validate",
+				Usage: "Check the configuration without creating directories or connecting to any service",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return validate(ctx, cmd)
+				},
+			},
+			{
+				Name:  "show",
+				Usage: "Print the effective configuration with secrets redacted and where each value was set",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Output format: 'table' or 'json'",
+						Value: "table",
+						Validator: func(s string) error {
+							if s != "table" && s != "json" {
+								return fmt.Errorf("invalid format: %s (must be 'table' or 'json')", s)
+							}
+							return nil
+						},
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return show(ctx, cmd)
+				},
+			},
+		},
+	}
+}
+
+func validate(ctx context.Context, cmd *cli.Command) error {
+	cfg, _, err := backendconfig.Load(ctx, cmd.Root().String("config"))
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	fmt.Println("✓ Configuration is valid")
+	return nil
+}
+
+func show(ctx context.Context, cmd *cli.Command) error {
+	cfg, sources, err := backendconfig.Load(ctx, cmd.Root().String("config"))
+	if err != nil {
+		return err
+	}
+	// Validate fills in the defaults, show them even if the configuration is invalid
+	validateErr := cfg.Validate()
+
+	values := cfg.Values(sources)
+	if cmd.String("format") == "json" {
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal configuration: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "KEY\tSOURCE\tVALUE\n")
+		for _, v := range values {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, v.Source, v.Value)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if validateErr != nil {
+		return fmt.Errorf("invalid configuration: %w", validateErr)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Package generatesearchindex implements the command to generate search.ndjson from the database
+package generatesearchindex
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/search"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "generate-search-index",
+		Usage: "Generate search.ndjson from the database and upload it to the bucket",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "key",
+				Usage: "Bucket key to upload the search index to",
+				Value: "search.ndjson",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Write a snapshot of the current search items to this local file instead of recording and uploading them",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Collect the search items and print a summary without recording changes or uploading",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return run(ctx, cmd)
+		},
+	}
+}
+
+func run(ctx context.Context, cmd *cli.Command) error {
+	cfg := config.FromCLI(cmd)
+	ctx, span := telemetry.Tracer().Start(ctx, "cmd.generate_search_index")
+	defer span.End()
+
+	key := cmd.String("key")
+	output := cmd.String("output")
+	dryRun := cmd.Bool("dry-run")
+
+	span.SetAttributes(
+		attribute.String("search.key", key),
+		attribute.String("search.output", output),
+		attribute.Bool("dry_run", dryRun),
+	)
+
+	pool, err := cfg.DB.GetPool(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "Failed to connect to database", "error", err)
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	items, err := search.BuildItems(ctx, pool)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to build search items: %w", err)
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would publish %d search items. No changes made.\n", len(items))
+		return nil
+	}
+
+	// A local file is a snapshot of the current items, it doesn't record them so the published feed keeps its changes
+	if output != "" {
+		data, err := search.RenderItems(items, time.Now())
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("failed to generate search index: %w", err)
+		}
+		if err := os.WriteFile(output, data, 0o644); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("failed to write search index to %s: %w", output, err)
+		}
+		fmt.Printf("✓ Wrote search index to %s (%d items)\n", output, len(items))
+		return nil
+	}
+
+	stats, err := search.SyncItems(ctx, pool, items)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to record search items: %w", err)
+	}
+	slog.InfoContext(ctx, "Recorded search items",
+		"items", stats.Items, "added", stats.Added, "updated", stats.Updated, "deleted", stats.Deleted)
+
+	data, err := search.GenerateNDJSON(ctx, pool)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to generate search index: %w", err)
+	}
+
+	store, err := bucket.New(ctx, &cfg.Bucket)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize bucket storage: %w", err)
+	}
+
+	if err := search.UploadNDJSON(ctx, store, key, data); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to upload search index: %w", err)
+	}
+
+	fmt.Printf("✓ Uploaded search index to %s (%d items, %d added, %d updated, %d deleted)\n",
+		key, stats.Items, stats.Added, stats.Updated, stats.Deleted)
+	return nil
+}
@@ -1,40 +1,60 @@
-// Package rebuildglobalindexes implements the command to rebuild global provider and module indexes from the database
+// Package rebuildglobalindexes implements the command to rebuild global and trending provider and module indexes, the
+// module used-by indexes and the per-address version indexes from the database
 package rebuildglobalindexes
 
 import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"github.com/urfave/cli/v3"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/sync/errgroup"
 
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/opentofu/registry-ui/pkg/bucket"
 	"github.com/opentofu/registry-ui/pkg/config"
 	"github.com/opentofu/registry-ui/pkg/index"
+	"github.com/opentofu/registry-ui/pkg/module"
+	"github.com/opentofu/registry-ui/pkg/provider"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 func NewCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "rebuild-global-indexes",
-		Usage: "Rebuild global provider and module indexes from the database",
+		Usage: "Rebuild global, trending, module used-by and per-address version indexes from the database",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:    "providers",
 				Aliases: []string{"p"},
-				Usage:   "Rebuild providers global index",
+				Usage:   "Rebuild providers global and trending indexes",
 				Value:   true,
 			},
 			&cli.BoolFlag{
 				Name:    "modules",
 				Aliases: []string{"m"},
-				Usage:   "Rebuild modules global index",
+				Usage:   "Rebuild modules global, trending and used-by indexes",
 				Value:   true,
 			},
+			&cli.BoolFlag{
+				Name:  "version-indexes",
+				Usage: "Also regenerate the index.json and history.json of every provider and module, keeping their star and fork deltas current",
+				Value: true,
+			},
+			&cli.BoolFlag{
+				Name:  "changes",
+				Usage: "Also publish the changes.json of every provider and module version, backfilling versions indexed before changes were published",
+			},
+			&cli.BoolFlag{
+				Name:  "force-used-by",
+				Usage: "Upload the used-by.json of every module, not only the changed ones, restoring files missing from the bucket",
+			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			return run(ctx, cmd)
@@ -55,10 +75,16 @@ func run(ctx context.Context, cmd *cli.Command) error {
 
 	rebuildProviders := cmd.Bool("providers")
 	rebuildModules := cmd.Bool("modules")
+	versionIndexes := cmd.Bool("version-indexes")
+	forceUsedBy := cmd.Bool("force-used-by")
+	changes := cmd.Bool("changes")
 
 	slog.InfoContext(ctx, "Starting global index rebuild",
 		"providers", rebuildProviders,
-		"modules", rebuildModules)
+		"modules", rebuildModules,
+		"version_indexes", versionIndexes,
+		"force_used_by", forceUsedBy,
+		"changes", changes)
 
 	// Connect to database
 	pool, err := cfg.DB.GetPool(ctx)
@@ -70,47 +96,65 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	}
 	defer pool.Close()
 
-	// Create S3 client for uploads
-	s3Client, err := cfg.Bucket.GetClient(ctx)
+	// Create the storage for uploads
+	store, err := bucket.New(ctx, &cfg.Bucket)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("failed to create S3 client: %w", err)
+		return fmt.Errorf("failed to initialize bucket storage: %w", err)
 	}
 
-	// Create uploader
-	uploader := manager.NewUploader(s3Client)
+	vcsHost, err := vcs.New(cfg.VCS)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize VCS host: %w", err)
+	}
 
 	// Rebuild provider index if requested
 	if rebuildProviders {
-		if err := rebuildProviderIndex(ctx, pool, uploader, cfg.Bucket.BucketName); err != nil {
+		globalIndex, err := rebuildProviderIndex(ctx, pool, store, vcsHost)
+		if err != nil {
 			span.RecordError(err)
 			return fmt.Errorf("failed to rebuild provider index: %w", err)
 		}
+		if versionIndexes {
+			regenerateProviderVersionIndexes(ctx, pool, store, vcsHost, globalIndex.Providers, cfg.Concurrency.Provider)
+		}
+		if changes {
+			publishProviderVersionChanges(ctx, pool, store, globalIndex.Providers, cfg.Concurrency.Provider)
+		}
 	}
 
 	// Rebuild module index if requested
 	if rebuildModules {
-		if err := rebuildModuleIndex(ctx, pool, uploader, cfg.Bucket.BucketName); err != nil {
+		globalIndex, err := rebuildModuleIndex(ctx, pool, store, forceUsedBy)
+		if err != nil {
 			span.RecordError(err)
 			return fmt.Errorf("failed to rebuild module index: %w", err)
 		}
+		if versionIndexes {
+			regenerateModuleVersionIndexes(ctx, pool, store, vcsHost, globalIndex.Modules, cfg.Concurrency.Module)
+		}
+		if changes {
+			publishModuleVersionChanges(ctx, pool, store, globalIndex.Modules, cfg.Concurrency.Module)
+		}
 	}
 
 	slog.InfoContext(ctx, "Successfully rebuilt global indexes")
 	return nil
 }
 
-func rebuildProviderIndex(ctx context.Context, pool *pgxpool.Pool, uploader *manager.Uploader, bucketName string) error {
+func rebuildProviderIndex(ctx context.Context, pool *pgxpool.Pool, store bucket.Store, host vcs.Host) (*index.GlobalProviderIndex, error) {
 	ctx, span := telemetry.Tracer().Start(ctx, "cmd.rebuild_global_indexes.providers")
 	defer span.End()
 
 	slog.InfoContext(ctx, "Rebuilding global provider index from database")
 
 	// Query database and build global index
-	globalIndex, err := index.RebuildGlobalProviderIndex(ctx, pool)
+	globalIndex, err := index.RebuildGlobalProviderIndex(ctx, pool, host)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("failed to rebuild provider index: %w", err)
+		return nil, fmt.Errorf("failed to rebuild provider index: %w", err)
 	}
 
 	span.SetAttributes(attribute.Int("providers.count", len(globalIndex.Providers)))
@@ -119,19 +163,36 @@ func rebuildProviderIndex(ctx context.Context, pool *pgxpool.Pool, uploader *man
 
 	// Upload to S3
 	key := "providers/index.json"
-	if err := index.UploadGlobalProviderIndex(ctx, uploader, bucketName, key, globalIndex); err != nil {
+	if err := index.UploadGlobalProviderIndex(ctx, store, key, globalIndex); err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("failed to upload provider index to S3: %w", err)
+		return nil, fmt.Errorf("failed to upload provider index to S3: %w", err)
 	}
 
 	slog.InfoContext(ctx, "Successfully uploaded global provider index to S3",
 		"key", key,
 		"provider_count", len(globalIndex.Providers))
 
-	return nil
+	// The trending index ranks the entries of the global index, so it is rebuilt alongside it
+	trending, err := index.RebuildTrendingProviderIndex(ctx, pool, globalIndex, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to rebuild trending provider index: %w", err)
+	}
+
+	trendingKey := "providers/trending.json"
+	if err := index.UploadTrendingProviderIndex(ctx, store, trendingKey, trending); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to upload trending provider index to S3: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Successfully uploaded trending provider index to S3",
+		"key", trendingKey,
+		"provider_count", len(trending.Providers))
+
+	return globalIndex, nil
 }
 
-func rebuildModuleIndex(ctx context.Context, pool *pgxpool.Pool, uploader *manager.Uploader, bucketName string) error {
+func rebuildModuleIndex(ctx context.Context, pool *pgxpool.Pool, store bucket.Store, forceUsedBy bool) (*index.GlobalModuleIndex, error) {
 	ctx, span := telemetry.Tracer().Start(ctx, "cmd.rebuild_global_indexes.modules")
 	defer span.End()
 
@@ -141,7 +202,7 @@ func rebuildModuleIndex(ctx context.Context, pool *pgxpool.Pool, uploader *manag
 	globalIndex, err := index.RebuildGlobalModuleIndex(ctx, pool)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("failed to rebuild module index: %w", err)
+		return nil, fmt.Errorf("failed to rebuild module index: %w", err)
 	}
 
 	span.SetAttributes(attribute.Int("modules.count", len(globalIndex.Modules)))
@@ -150,14 +211,167 @@ func rebuildModuleIndex(ctx context.Context, pool *pgxpool.Pool, uploader *manag
 
 	// Upload to S3
 	key := "modules/index.json"
-	if err := index.UploadGlobalModuleIndex(ctx, uploader, bucketName, key, globalIndex); err != nil {
+	if err := index.UploadGlobalModuleIndex(ctx, store, key, globalIndex); err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("failed to upload module index to S3: %w", err)
+		return nil, fmt.Errorf("failed to upload module index to S3: %w", err)
 	}
 
 	slog.InfoContext(ctx, "Successfully uploaded global module index to S3",
 		"key", key,
 		"module_count", len(globalIndex.Modules))
 
-	return nil
+	// The trending index ranks the entries of the global index, so it is rebuilt alongside it
+	trending, err := index.RebuildTrendingModuleIndex(ctx, pool, globalIndex, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to rebuild trending module index: %w", err)
+	}
+
+	trendingKey := "modules/trending.json"
+	if err := index.UploadTrendingModuleIndex(ctx, store, trendingKey, trending); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to upload trending module index to S3: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Successfully uploaded trending module index to S3",
+		"key", trendingKey,
+		"module_count", len(trending.Modules))
+
+	usedBy, err := index.RebuildModuleUsedBy(ctx, pool, store, forceUsedBy)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to rebuild module used-by indexes: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Successfully rebuilt module used-by indexes",
+		"dependency_count", usedBy.Dependencies,
+		"module_count", usedBy.Modules,
+		"uploaded_count", usedBy.Uploaded)
+
+	return globalIndex, nil
+}
+
+// regenerateProviderVersionIndexes regenerates the index.json and history.json of every provider in the global index.
+// The star and fork deltas in them move with time, so they would freeze for providers without new versions otherwise.
+// Failures are logged rather than stopping the rebuild.
+func regenerateProviderVersionIndexes(ctx context.Context, pool *pgxpool.Pool, store bucket.Store, host vcs.Host, entries []index.ProviderEntry, concurrency int) {
+	ctx, span := telemetry.Tracer().Start(ctx, "cmd.rebuild_global_indexes.provider_version_indexes")
+	defer span.End()
+
+	var failed atomic.Int64
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for _, entry := range entries {
+		g.Go(func() error {
+			providerIndex, err := index.GenerateProviderVersionIndex(gctx, pool, host, entry.Addr.Namespace, entry.Addr.Name)
+			if err == nil {
+				err = index.UploadProviderVersionIndex(gctx, store, providerIndex)
+			}
+			if err != nil {
+				failed.Add(1)
+				slog.WarnContext(gctx, "Failed to regenerate provider version index",
+					"provider", entry.Addr.Display, "error", err)
+			}
+			return nil
+		})
+	}
+	g.Wait() //nolint:errcheck // errors are logged per provider
+
+	span.SetAttributes(
+		attribute.Int("providers.count", len(entries)),
+		attribute.Int64("providers.failed", failed.Load()),
+	)
+	slog.InfoContext(ctx, "Regenerated provider version indexes",
+		"provider_count", len(entries), "failed_count", failed.Load())
+}
+
+// regenerateModuleVersionIndexes regenerates the index.json and history.json of every module in the global index, see
+// regenerateProviderVersionIndexes
+func regenerateModuleVersionIndexes(ctx context.Context, pool *pgxpool.Pool, store bucket.Store, host vcs.Host, entries []index.ModuleEntry, concurrency int) {
+	ctx, span := telemetry.Tracer().Start(ctx, "cmd.rebuild_global_indexes.module_version_indexes")
+	defer span.End()
+
+	var failed atomic.Int64
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for _, entry := range entries {
+		g.Go(func() error {
+			moduleIndex, err := index.GenerateModuleVersionIndex(gctx, pool, host, entry.Addr.Namespace, entry.Addr.Name, entry.Addr.Target)
+			if err == nil {
+				err = index.UploadModuleVersionIndex(gctx, store, moduleIndex)
+			}
+			if err != nil {
+				failed.Add(1)
+				slog.WarnContext(gctx, "Failed to regenerate module version index",
+					"module", entry.Addr.Display, "error", err)
+			}
+			return nil
+		})
+	}
+	g.Wait() //nolint:errcheck // errors are logged per module
+
+	span.SetAttributes(
+		attribute.Int("modules.count", len(entries)),
+		attribute.Int64("modules.failed", failed.Load()),
+	)
+	slog.InfoContext(ctx, "Regenerated module version indexes",
+		"module_count", len(entries), "failed_count", failed.Load())
+}
+
+// publishProviderVersionChanges publishes the changes.json of every version of every provider in the global index.
+// Failures are logged rather than stopping the rebuild.
+func publishProviderVersionChanges(ctx context.Context, pool *pgxpool.Pool, store bucket.Store, entries []index.ProviderEntry, concurrency int) {
+	ctx, span := telemetry.Tracer().Start(ctx, "cmd.rebuild_global_indexes.provider_version_changes")
+	defer span.End()
+
+	var failed atomic.Int64
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for _, entry := range entries {
+		g.Go(func() error {
+			if err := provider.PublishAllVersionChanges(gctx, pool, store, entry.Addr.Namespace, entry.Addr.Name); err != nil {
+				failed.Add(1)
+				slog.WarnContext(gctx, "Failed to publish provider version changes",
+					"provider", entry.Addr.Display, "error", err)
+			}
+			return nil
+		})
+	}
+	g.Wait() //nolint:errcheck // errors are logged per provider
+
+	span.SetAttributes(
+		attribute.Int("providers.count", len(entries)),
+		attribute.Int64("providers.failed", failed.Load()),
+	)
+	slog.InfoContext(ctx, "Published provider version changes",
+		"provider_count", len(entries), "failed_count", failed.Load())
+}
+
+// publishModuleVersionChanges publishes the changes.json of every version of every module in the global index, see
+// publishProviderVersionChanges
+func publishModuleVersionChanges(ctx context.Context, pool *pgxpool.Pool, store bucket.Store, entries []index.ModuleEntry, concurrency int) {
+	ctx, span := telemetry.Tracer().Start(ctx, "cmd.rebuild_global_indexes.module_version_changes")
+	defer span.End()
+
+	var failed atomic.Int64
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for _, entry := range entries {
+		g.Go(func() error {
+			if err := module.PublishAllVersionChanges(gctx, pool, store, entry.Addr.Namespace, entry.Addr.Name, entry.Addr.Target); err != nil {
+				failed.Add(1)
+				slog.WarnContext(gctx, "Failed to publish module version changes",
+					"module", entry.Addr.Display, "error", err)
+			}
+			return nil
+		})
+	}
+	g.Wait() //nolint:errcheck // errors are logged per module
+
+	span.SetAttributes(
+		attribute.Int("modules.count", len(entries)),
+		attribute.Int64("modules.failed", failed.Load()),
+	)
+	slog.InfoContext(ctx, "Published module version changes",
+		"module_count", len(entries), "failed_count", failed.Load())
 }
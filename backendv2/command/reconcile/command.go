@@ -0,0 +1,257 @@
+// Package reconcile implements the command to compare the bucket with the database and clean up the differences
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/reconcile"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "reconcile",
+		Usage: "Find orphaned objects, missing objects and checksum mismatches between the bucket and the database",
+		Description: `Lists the provider and module prefixes of the bucket and compares them with the objects recorded in the
+database. Without --apply the command only reports the differences.
+
+With --apply, orphaned objects are deleted and versions with missing or mismatched objects are deleted from the
+database (like retry-version) so that the next sync scrapes them again.
+
+The indexers upload the files of a version before committing it to the database, so a sync that is running while
+reconcile lists the bucket looks like orphaned and missing objects. Objects modified and versions attempted within
+--grace-period are therefore skipped. Keep it longer than a single version takes to scrape.
+
+Checksums are compared with the ETags of the objects, which are only MD5 checksums for buckets without server side
+encryption or with SSE-S3. Set bucket.serverSideEncryption for SSE-KMS and SSE-C buckets to skip the comparison.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "type",
+				Aliases: []string{"t"},
+				Usage:   "Resource type: 'provider', 'module' or 'all'",
+				Value:   "all",
+				Validator: func(s string) error {
+					if s != "provider" && s != "module" && s != "all" {
+						return fmt.Errorf("invalid type: %s (must be 'provider', 'module' or 'all')", s)
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:    "namespace",
+				Aliases: []string{"n"},
+				Usage:   "Only reconcile a single namespace (e.g., hashicorp)",
+			},
+			&cli.BoolFlag{
+				Name:  "apply",
+				Usage: "Delete orphaned objects and reset versions with missing or mismatched objects so the next sync scrapes them again",
+			},
+			&cli.DurationFlag{
+				Name:  "grace-period",
+				Usage: "Skip objects modified and versions attempted within this period, a running sync may not have committed them yet",
+				Value: 2 * time.Hour,
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Write the JSON report to this file, use '-' for stdout",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return run(ctx, cmd)
+		},
+	}
+}
+
+func run(ctx context.Context, cmd *cli.Command) error {
+	cfg := config.FromCLI(cmd)
+	ctx, span := telemetry.Tracer().Start(ctx, "cmd.reconcile")
+	defer span.End()
+
+	resourceType := cmd.String("type")
+	namespace := cmd.String("namespace")
+	apply := cmd.Bool("apply")
+	output := cmd.String("output")
+	gracePeriod := cmd.Duration("grace-period")
+
+	span.SetAttributes(
+		attribute.String("resource.type", resourceType),
+		attribute.String("resource.namespace", namespace),
+		attribute.Bool("apply", apply),
+		attribute.String("grace_period", gracePeriod.String()),
+	)
+
+	pool, err := cfg.DB.GetPool(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "Failed to connect to database", "error", err)
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	store, err := bucket.New(ctx, &cfg.Bucket)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize bucket storage: %w", err)
+	}
+
+	state := reconcile.NewState()
+	var prefixes []string
+	if resourceType == "provider" || resourceType == "all" {
+		if err := reconcile.LoadProviderState(ctx, pool, state, namespace); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		prefixes = append(prefixes, prefixFor("providers", namespace))
+	}
+	if resourceType == "module" || resourceType == "all" {
+		if err := reconcile.LoadModuleState(ctx, pool, state, namespace); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		prefixes = append(prefixes, prefixFor("modules", namespace))
+	}
+
+	// Taken before listing, objects uploaded while listing are newer and skipped as well
+	settledBefore := time.Now().Add(-gracePeriod)
+	if err := reconcile.LoadInFlightVersions(ctx, pool, state, namespace, settledBefore); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	var objects []bucket.ObjectInfo
+	for _, prefix := range prefixes {
+		slog.InfoContext(ctx, "Listing bucket objects", "prefix", prefix)
+		listed, err := store.List(ctx, prefix)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("failed to list objects under %s: %w", prefix, err)
+		}
+		objects = append(objects, listed...)
+	}
+
+	report := reconcile.Compare(state, prefixes, objects, reconcile.Options{
+		CompareETags:  cfg.Bucket.ETagsAreMD5(),
+		SettledBefore: settledBefore,
+	})
+	span.SetAttributes(
+		attribute.Int("reconcile.objects_scanned", report.ObjectsScanned),
+		attribute.Int("reconcile.orphaned", len(report.Orphaned)),
+		attribute.Int("reconcile.missing", len(report.Missing)),
+		attribute.Int("reconcile.mismatched", len(report.Mismatched)),
+		attribute.Int("reconcile.skipped", report.Skipped),
+	)
+
+	if output != "" {
+		if err := writeReport(report, output); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+	if output != "-" {
+		printSummary(report)
+	}
+
+	if report.Clean() || !apply {
+		return nil
+	}
+
+	if len(report.Orphaned) > 0 {
+		keys := make([]string, len(report.Orphaned))
+		for i, issue := range report.Orphaned {
+			keys[i] = issue.Key
+		}
+		deleted, err := store.Delete(ctx, keys)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("failed to delete orphaned objects: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Deleted %d orphaned objects\n", deleted)
+	}
+
+	versions := report.BrokenVersions()
+	for _, v := range versions {
+		if err := reconcile.ResetVersion(ctx, pool, v); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		slog.InfoContext(ctx, "Reset version for retry", "type", v.Type, "version", v.String())
+	}
+	if len(versions) > 0 {
+		fmt.Fprintf(os.Stderr, "✓ Reset %d versions with missing or mismatched objects - they will be retried during next sync\n", len(versions))
+	}
+
+	return nil
+}
+
+func prefixFor(root, namespace string) string {
+	if namespace == "" {
+		return root + "/"
+	}
+	return root + "/" + namespace + "/"
+}
+
+func writeReport(report *reconcile.Report, output string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	if output == "-" {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write report to %s: %w", output, err)
+	}
+	return nil
+}
+
+func printSummary(report *reconcile.Report) {
+	fmt.Printf("Objects scanned: %d (expected from database: %d)\n", report.ObjectsScanned, report.ObjectsExpected)
+	if report.Skipped > 0 {
+		fmt.Printf("Skipped recently changed objects: %d\n", report.Skipped)
+	}
+	for _, group := range []struct {
+		label  string
+		issues []reconcile.Issue
+	}{
+		{"Orphaned objects", report.Orphaned},
+		{"Missing objects", report.Missing},
+		{"Checksum mismatches", report.Mismatched},
+	} {
+		fmt.Printf("%s: %d\n", group.label, len(group.issues))
+		for i, issue := range group.issues {
+			if i == 10 {
+				fmt.Printf("  ... and %d more\n", len(group.issues)-i)
+				break
+			}
+			fmt.Printf("  - %s\n", issue.Key)
+		}
+	}
+
+	if report.Clean() {
+		fmt.Printf("\n✓ Bucket and database are consistent\n")
+	}
+}
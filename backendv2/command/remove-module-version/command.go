@@ -0,0 +1,351 @@
+// Package removemoduleversion implements the command to remove a module version, or an entire module, from the
+// database and the bucket
+package removemoduleversion
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/index"
+	"github.com/opentofu/registry-ui/pkg/module"
+	"github.com/opentofu/registry-ui/pkg/search"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
+)
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "remove-module-version",
+		Usage: "Remove a module version (or an entire module with --all) from the database and S3",
+		Description: `Deletes the module version from the database, which cascades to its submodules, examples and licenses,
+deletes its files from the bucket and regenerates the index.json of the module, the changes.json of the version
+that followed the removed one, the global module index and the search index.
+
+With --all the module itself and all of its versions are removed and its index.json is deleted. The next sync adds
+the module back as long as it is listed in the registry, add it to blocklist.json and run sync-blocklist to keep it
+out.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "namespace",
+				Aliases:  []string{"n"},
+				Usage:    "Module namespace (e.g., terraform-aws-modules)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "name",
+				Usage:    "Module name (e.g., vpc)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "target",
+				Usage:    "Module target (e.g., aws)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    "version",
+				Aliases: []string{"v"},
+				Usage:   "Version to remove (e.g., v1.2.3)",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Remove the entire module with all of its versions",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Preview what will be deleted without actually deleting",
+			},
+		},
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			if (cmd.String("version") == "") == !cmd.Bool("all") {
+				return ctx, fmt.Errorf("exactly one of --version or --all is required")
+			}
+			return ctx, nil
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return run(ctx, cmd)
+		},
+	}
+}
+
+func run(ctx context.Context, cmd *cli.Command) error {
+	cfg := config.FromCLI(cmd)
+	ctx, span := telemetry.Tracer().Start(ctx, "cmd.remove_module_version")
+	defer span.End()
+
+	namespace := cmd.String("namespace")
+	name := cmd.String("name")
+	target := cmd.String("target")
+	version := cmd.String("version")
+	all := cmd.Bool("all")
+	dryRun := cmd.Bool("dry-run")
+
+	span.SetAttributes(
+		attribute.String("module.namespace", namespace),
+		attribute.String("module.name", name),
+		attribute.String("module.target", target),
+		attribute.String("module.version", version),
+		attribute.Bool("all", all),
+		attribute.Bool("dry_run", dryRun),
+	)
+
+	slog.InfoContext(ctx, "Removing module version",
+		"namespace", namespace, "name", name, "target", target, "version", version, "all", all, "dry_run", dryRun)
+
+	// Connect to database
+	pool, err := cfg.DB.GetPool(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "Failed to connect to database", "error", err)
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	vcsHost, err := vcs.New(cfg.VCS)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize VCS host: %w", err)
+	}
+
+	store, err := bucket.New(ctx, &cfg.Bucket)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize bucket storage: %w", err)
+	}
+
+	// Start transaction for consistent reads + delete
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "Failed to start transaction", "error", err)
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// Check if the version (or module) exists and count related records
+	counts, err := queryCounts(ctx, tx, namespace, name, target, version)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// List S3 objects, removing the whole module also removes its index.json
+	s3Prefix := objectPrefix(namespace, name, target, version)
+	s3Objects, err := listObjectKeys(ctx, store, s3Prefix)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to list S3 objects: %w", err)
+	}
+
+	printSummary(namespace, name, target, version, counts, s3Objects, s3Prefix)
+
+	if dryRun {
+		fmt.Printf("\n[DRY RUN] No changes made.\n")
+		return nil
+	}
+
+	// Delete from database (cascades to related tables)
+	err = deleteFromDB(ctx, tx, namespace, name, target, version, counts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// Delete from S3
+	if len(s3Objects) > 0 {
+		slog.InfoContext(ctx, "Deleting from S3", "prefix", s3Prefix, "count", len(s3Objects))
+		deleted, err := store.Delete(ctx, s3Objects)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("failed to delete S3 objects (DB already deleted): %w", err)
+		}
+		slog.InfoContext(ctx, "Deleted from S3", "count", deleted)
+	}
+
+	if err := regenerateIndexes(ctx, pool, store, vcsHost, namespace, name, target, version); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to regenerate indexes (DB and S3 already deleted): %w", err)
+	}
+
+	if all {
+		fmt.Printf("\n✓ Successfully removed module %s/%s/%s\n", namespace, name, target)
+		fmt.Printf("  The next sync adds it back unless it is added to blocklist.json and sync-blocklist is run\n")
+	} else {
+		fmt.Printf("\n✓ Successfully removed module version %s/%s/%s@%s\n", namespace, name, target, version)
+	}
+	return nil
+}
+
+type recordCounts struct {
+	versions   int
+	submodules int
+	examples   int
+	licenses   int
+}
+
+// queryCounts counts the records that will be deleted. An empty version counts the records of all versions.
+func queryCounts(ctx context.Context, tx pgx.Tx, namespace, name, target, version string) (recordCounts, error) {
+	var counts recordCounts
+	var exists bool
+	err := tx.QueryRow(ctx, `
+		SELECT
+			CASE WHEN $4 = ''
+				THEN EXISTS(SELECT 1 FROM modules
+					WHERE namespace = $1 AND name = $2 AND target = $3)
+				ELSE EXISTS(SELECT 1 FROM module_versions
+					WHERE module_namespace = $1 AND module_name = $2 AND module_target = $3 AND version = $4)
+			END,
+			(SELECT COUNT(*) FROM module_versions
+				WHERE module_namespace = $1 AND module_name = $2 AND module_target = $3 AND ($4 = '' OR version = $4)),
+			(SELECT COUNT(*) FROM module_submodules
+				WHERE module_namespace = $1 AND module_name = $2 AND module_target = $3 AND ($4 = '' OR version = $4)),
+			(SELECT COUNT(*) FROM module_examples
+				WHERE module_namespace = $1 AND module_name = $2 AND module_target = $3 AND ($4 = '' OR version = $4)),
+			(SELECT COUNT(*) FROM module_version_licenses
+				WHERE module_namespace = $1 AND module_name = $2 AND module_target = $3 AND ($4 = '' OR version = $4))`,
+		namespace, name, target, version).Scan(&exists, &counts.versions, &counts.submodules, &counts.examples, &counts.licenses)
+	if err != nil {
+		return counts, fmt.Errorf("failed to query module info: %w", err)
+	}
+
+	if !exists {
+		if version == "" {
+			return counts, fmt.Errorf("module %s/%s/%s not found in database", namespace, name, target)
+		}
+		return counts, fmt.Errorf("module version %s/%s/%s@%s not found in database", namespace, name, target, version)
+	}
+
+	return counts, nil
+}
+
+func deleteFromDB(ctx context.Context, tx pgx.Tx, namespace, name, target, version string, counts recordCounts) error {
+	slog.InfoContext(ctx, "Deleting from database", "namespace", namespace, "name", name, "target", target, "version", version)
+
+	var query string
+	var args []any
+	if version == "" {
+		query = `DELETE FROM modules WHERE namespace = $1 AND name = $2 AND target = $3`
+		args = []any{namespace, name, target}
+	} else {
+		query = `DELETE FROM module_versions WHERE module_namespace = $1 AND module_name = $2 AND module_target = $3 AND version = $4`
+		args = []any{namespace, name, target, version}
+	}
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete from database: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("no rows deleted from database")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Deleted from database",
+		"rows_affected", result.RowsAffected(),
+		"cascaded_versions", counts.versions,
+		"cascaded_submodules", counts.submodules,
+		"cascaded_examples", counts.examples,
+		"cascaded_licenses", counts.licenses)
+
+	return nil
+}
+
+// objectPrefix returns the bucket prefix of a module version, or of the whole module including its index.json when
+// version is empty
+func objectPrefix(namespace, name, target, version string) string {
+	prefix := fmt.Sprintf("modules/%s/%s/%s/", namespace, name, target)
+	if version != "" {
+		prefix += version + "/"
+	}
+	return prefix
+}
+
+// regenerateIndexes uploads the module's index.json without the removed version and the changes.json of the version
+// that followed it, unless the whole module was removed (empty version), then rebuilds the global module index and the
+// search index, which may still list the removed version
+func regenerateIndexes(ctx context.Context, pool *pgxpool.Pool, store bucket.Store, host vcs.Host, namespace, name, target, version string) error {
+	if version != "" {
+		// The successor of the removed version was compared against it, so it is compared against the version before
+		if err := module.PublishVersionChanges(ctx, pool, store, namespace, name, target, []string{version}); err != nil {
+			return err
+		}
+
+		moduleIndex, err := index.GenerateModuleVersionIndex(ctx, pool, host, namespace, name, target)
+		if err != nil {
+			return err
+		}
+		if err := index.UploadModuleVersionIndex(ctx, store, moduleIndex); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "Regenerated module version index",
+			"module", fmt.Sprintf("%s/%s/%s", namespace, name, target), "versions", len(moduleIndex.Versions))
+	}
+
+	globalIndex, err := index.RebuildGlobalModuleIndex(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if err := index.UploadGlobalModuleIndex(ctx, store, "modules/index.json", globalIndex); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Rebuilt global module index", "modules", len(globalIndex.Modules))
+
+	stats, err := search.Publish(ctx, pool, store, "search.ndjson")
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Regenerated search index", "items", stats.Items, "deleted", stats.Deleted)
+	return nil
+}
+
+func printSummary(namespace, name, target, version string, counts recordCounts, s3Objects []string, s3Prefix string) {
+	if version == "" {
+		fmt.Printf("\nModule: %s/%s/%s (all versions)\n", namespace, name, target)
+	} else {
+		fmt.Printf("\nModule version: %s/%s/%s@%s\n", namespace, name, target, version)
+	}
+	fmt.Printf("Database records to delete:\n")
+	if version == "" {
+		fmt.Printf("  - 1 modules record\n")
+	}
+	fmt.Printf("  - %d module_versions records\n", counts.versions)
+	fmt.Printf("  - %d module_submodules records\n", counts.submodules)
+	fmt.Printf("  - %d module_examples records\n", counts.examples)
+	fmt.Printf("  - %d module_version_licenses records\n", counts.licenses)
+	fmt.Printf("S3 objects to delete: %d (prefix: %s)\n", len(s3Objects), s3Prefix)
+}
+
+func listObjectKeys(ctx context.Context, store bucket.Store, prefix string) ([]string, error) {
+	objects, err := store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+	return keys, nil
+}
@@ -0,0 +1,91 @@
+package removemoduleversion
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+)
+
+func TestFlags(t *testing.T) {
+	address := []string{"--namespace", "terraform-aws-modules", "--name", "vpc", "--target", "aws"}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "version", args: []string{"--version", "v5.0.0"}},
+		{name: "all", args: []string{"--all"}},
+		{name: "neither", wantErr: true},
+		{name: "both", args: []string{"--version", "v5.0.0", "--all"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewCommand()
+			cmd.Action = func(context.Context, *cli.Command) error { return nil }
+			args := append(append([]string{cmd.Name}, address...), tt.args...)
+			err := cmd.Run(context.Background(), args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestObjectsToDelete(t *testing.T) {
+	store, err := bucket.NewDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{
+		"modules/terraform-aws-modules/vpc/aws/index.json",
+		"modules/terraform-aws-modules/vpc/aws/v5.0.0/index.json",
+		"modules/terraform-aws-modules/vpc/aws/v5.0.0/README.md",
+		"modules/terraform-aws-modules/vpc/aws/v5.0.0-rc1/index.json",
+		"modules/terraform-aws-modules/vpc/awscc/index.json",
+	} {
+		if err := store.Put(context.Background(), bucket.Object{Key: key, Body: []byte("{}")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		want    []string
+	}{
+		{
+			name:    "version",
+			version: "v5.0.0",
+			want:    []string{"modules/terraform-aws-modules/vpc/aws/v5.0.0/README.md", "modules/terraform-aws-modules/vpc/aws/v5.0.0/index.json"},
+		},
+		{
+			name: "all versions",
+			want: []string{
+				"modules/terraform-aws-modules/vpc/aws/index.json",
+				"modules/terraform-aws-modules/vpc/aws/v5.0.0-rc1/index.json",
+				"modules/terraform-aws-modules/vpc/aws/v5.0.0/README.md",
+				"modules/terraform-aws-modules/vpc/aws/v5.0.0/index.json",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listObjectKeys(context.Background(), store, objectPrefix("terraform-aws-modules", "vpc", "aws", tt.version))
+			if err != nil {
+				t.Fatalf("listObjectKeys() error = %v", err)
+			}
+			slices.Sort(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listObjectKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,4 +1,5 @@
-// Package removeproviderversion implements the command to remove a provider version from the database and S3
+// Package removeproviderversion implements the command to remove a provider version, or an entire provider, from
+// the database and S3
 package removeproviderversion
 
 import (
@@ -7,22 +8,32 @@ import (
 	"log/slog"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/urfave/cli/v3"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 
+	"github.com/opentofu/registry-ui/pkg/bucket"
 	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/index"
+	"github.com/opentofu/registry-ui/pkg/provider"
+	"github.com/opentofu/registry-ui/pkg/search"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 func NewCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "remove-provider-version",
-		Usage: "Remove a provider version from the database and S3",
+		Usage: "Remove a provider version (or an entire provider with --all) from the database and S3",
+		Description: `Deletes the provider version from the database, which cascades to its documents, licenses and schema,
+deletes its files from the bucket and regenerates the index.json of the provider, the changes.json of the version
+that followed the removed one, the global provider index and the search index.
+
+With --all the provider itself and all of its versions are removed and its index.json is deleted. The next sync adds
+the provider back as long as it is listed in the registry, add it to blocklist.json and run sync-blocklist to keep it
+out.`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:     "namespace",
@@ -36,16 +47,25 @@ func NewCommand() *cli.Command {
 				Required: true,
 			},
 			&cli.StringFlag{
-				Name:     "version",
-				Aliases:  []string{"v"},
-				Usage:    "Version to remove (e.g., 1.2.3)",
-				Required: true,
+				Name:    "version",
+				Aliases: []string{"v"},
+				Usage:   "Version to remove (e.g., 1.2.3)",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Remove the entire provider with all of its versions",
 			},
 			&cli.BoolFlag{
 				Name:  "dry-run",
 				Usage: "Preview what will be deleted without actually deleting",
 			},
 		},
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			if (cmd.String("version") == "") == !cmd.Bool("all") {
+				return ctx, fmt.Errorf("exactly one of --version or --all is required")
+			}
+			return ctx, nil
+		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			return run(ctx, cmd)
 		},
@@ -60,17 +80,19 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	namespace := cmd.String("namespace")
 	name := cmd.String("name")
 	version := cmd.String("version")
+	all := cmd.Bool("all")
 	dryRun := cmd.Bool("dry-run")
 
 	span.SetAttributes(
 		attribute.String("provider.namespace", namespace),
 		attribute.String("provider.name", name),
 		attribute.String("provider.version", version),
+		attribute.Bool("all", all),
 		attribute.Bool("dry_run", dryRun),
 	)
 
 	slog.InfoContext(ctx, "Removing provider version",
-		"namespace", namespace, "name", name, "version", version, "dry_run", dryRun)
+		"namespace", namespace, "name", name, "version", version, "all", all, "dry_run", dryRun)
 
 	// Connect to database
 	pool, err := cfg.DB.GetPool(ctx)
@@ -82,12 +104,18 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	}
 	defer pool.Close()
 
-	// Get S3 client
-	s3Client, err := cfg.Bucket.GetClient(ctx)
+	vcsHost, err := vcs.New(cfg.VCS)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return fmt.Errorf("failed to get S3 client: %w", err)
+		return fmt.Errorf("failed to initialize VCS host: %w", err)
+	}
+
+	store, err := bucket.New(ctx, &cfg.Bucket)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize bucket storage: %w", err)
 	}
 
 	// Start transaction for consistent reads + delete
@@ -100,24 +128,24 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	}
 	defer tx.Rollback(ctx) //nolint:errcheck
 
-	// Check if version exists and count related records
-	docCount, licenseCount, err := queryVersionInfo(ctx, tx, namespace, name, version)
+	// Check if the version (or provider) exists and count related records
+	versionCount, docCount, licenseCount, err := queryVersionInfo(ctx, tx, namespace, name, version)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 
-	// List S3 objects
-	s3Prefix := fmt.Sprintf("providers/%s/%s/%s/", namespace, name, version)
-	s3Objects, err := listS3Objects(ctx, s3Client, cfg.Bucket.BucketName, s3Prefix)
+	// List S3 objects, removing the whole provider also removes its index.json
+	s3Prefix := objectPrefix(namespace, name, version)
+	s3Objects, err := listObjectKeys(ctx, store, s3Prefix)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("failed to list S3 objects: %w", err)
 	}
 
-	printSummary(namespace, name, version, docCount, licenseCount, s3Objects, s3Prefix)
+	printSummary(namespace, name, version, versionCount, docCount, licenseCount, s3Objects, s3Prefix)
 
 	if dryRun {
 		fmt.Printf("\n[DRY RUN] No changes made.\n")
@@ -135,7 +163,7 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	// Delete from S3
 	if len(s3Objects) > 0 {
 		slog.InfoContext(ctx, "Deleting from S3", "prefix", s3Prefix, "count", len(s3Objects))
-		deleted, err := deleteS3Objects(ctx, s3Client, cfg.Bucket.BucketName, s3Objects)
+		deleted, err := store.Delete(ctx, s3Objects)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
@@ -144,39 +172,67 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		slog.InfoContext(ctx, "Deleted from S3", "count", deleted)
 	}
 
-	fmt.Printf("\n✓ Successfully removed provider version %s/%s@%s\n", namespace, name, version)
+	if err := regenerateIndexes(ctx, pool, store, vcsHost, namespace, name, version); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to regenerate indexes (DB and S3 already deleted): %w", err)
+	}
+
+	if all {
+		fmt.Printf("\n✓ Successfully removed provider %s/%s\n", namespace, name)
+		fmt.Printf("  The next sync adds it back unless it is added to blocklist.json and sync-blocklist is run\n")
+	} else {
+		fmt.Printf("\n✓ Successfully removed provider version %s/%s@%s\n", namespace, name, version)
+	}
 	return nil
 }
 
-func queryVersionInfo(ctx context.Context, tx pgx.Tx, namespace, name, version string) (docCount int, licenseCount int, err error) {
+// queryVersionInfo counts the records that will be deleted. An empty version counts the records of all versions.
+func queryVersionInfo(ctx context.Context, tx pgx.Tx, namespace, name, version string) (versionCount int, docCount int, licenseCount int, err error) {
 	var exists bool
 	err = tx.QueryRow(ctx, `
 		SELECT
-			EXISTS(SELECT 1 FROM provider_versions
-				WHERE provider_namespace = $1 AND provider_name = $2 AND version = $3),
+			CASE WHEN $3 = ''
+				THEN EXISTS(SELECT 1 FROM providers
+					WHERE namespace = $1 AND name = $2)
+				ELSE EXISTS(SELECT 1 FROM provider_versions
+					WHERE provider_namespace = $1 AND provider_name = $2 AND version = $3)
+			END,
+			(SELECT COUNT(*) FROM provider_versions
+				WHERE provider_namespace = $1 AND provider_name = $2 AND ($3 = '' OR version = $3)),
 			(SELECT COUNT(*) FROM provider_documents
-				WHERE provider_namespace = $1 AND provider_name = $2 AND version = $3),
+				WHERE provider_namespace = $1 AND provider_name = $2 AND ($3 = '' OR version = $3)),
 			(SELECT COUNT(*) FROM provider_version_licenses
-				WHERE provider_namespace = $1 AND provider_name = $2 AND version = $3)`,
-		namespace, name, version).Scan(&exists, &docCount, &licenseCount)
+				WHERE provider_namespace = $1 AND provider_name = $2 AND ($3 = '' OR version = $3))`,
+		namespace, name, version).Scan(&exists, &versionCount, &docCount, &licenseCount)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to query version info: %w", err)
+		return 0, 0, 0, fmt.Errorf("failed to query version info: %w", err)
 	}
 
 	if !exists {
-		return 0, 0, fmt.Errorf("provider version %s/%s@%s not found in database", namespace, name, version)
+		if version == "" {
+			return 0, 0, 0, fmt.Errorf("provider %s/%s not found in database", namespace, name)
+		}
+		return 0, 0, 0, fmt.Errorf("provider version %s/%s@%s not found in database", namespace, name, version)
 	}
 
-	return docCount, licenseCount, nil
+	return versionCount, docCount, licenseCount, nil
 }
 
 func deleteVersionFromDB(ctx context.Context, tx pgx.Tx, namespace, name, version string, docCount, licenseCount int) error {
 	slog.InfoContext(ctx, "Deleting from database", "namespace", namespace, "name", name, "version", version)
 
-	result, err := tx.Exec(ctx, `
-		DELETE FROM provider_versions
-		WHERE provider_namespace = $1 AND provider_name = $2 AND version = $3`,
-		namespace, name, version)
+	var query string
+	var args []any
+	if version == "" {
+		query = `DELETE FROM providers WHERE namespace = $1 AND name = $2`
+		args = []any{namespace, name}
+	} else {
+		query = `DELETE FROM provider_versions WHERE provider_namespace = $1 AND provider_name = $2 AND version = $3`
+		args = []any{namespace, name, version}
+	}
+
+	result, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete from database: %w", err)
 	}
@@ -198,67 +254,79 @@ func deleteVersionFromDB(ctx context.Context, tx pgx.Tx, namespace, name, versio
 	return nil
 }
 
-func printSummary(namespace string, name string, version string, docCount int, licenseCount int, s3Objects []string, s3Prefix string) {
-	fmt.Printf("\nProvider version: %s/%s@%s\n", namespace, name, version)
-	fmt.Printf("Database records to delete:\n")
-	fmt.Printf("  - 1 provider_versions record\n")
-	fmt.Printf("  - %d provider_documents records\n", docCount)
-	fmt.Printf("  - %d provider_version_licenses records\n", licenseCount)
-	fmt.Printf("S3 objects to delete: %d (prefix: %s)\n", len(s3Objects), s3Prefix)
+// objectPrefix returns the bucket prefix of a provider version, or of the whole provider including its index.json
+// when version is empty
+func objectPrefix(namespace, name, version string) string {
+	prefix := fmt.Sprintf("providers/%s/%s/", namespace, name)
+	if version != "" {
+		prefix += version + "/"
+	}
+	return prefix
 }
 
-func listS3Objects(ctx context.Context, client *s3.Client, bucket, prefix string) ([]string, error) {
-	var objects []string
-
-	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
-		Bucket: aws.String(bucket),
-		Prefix: aws.String(prefix),
-	})
+// regenerateIndexes uploads the provider's index.json without the removed version and the changes.json of the version
+// that followed it, unless the whole provider was removed (empty version), then rebuilds the global provider index and
+// the search index, which may still list the removed version
+func regenerateIndexes(ctx context.Context, pool *pgxpool.Pool, store bucket.Store, host vcs.Host, namespace, name, version string) error {
+	if version != "" {
+		// The successor of the removed version was compared against it, so it is compared against the version before
+		if err := provider.PublishVersionChanges(ctx, pool, store, namespace, name, []string{version}); err != nil {
+			return err
+		}
 
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
+		providerIndex, err := index.GenerateProviderVersionIndex(ctx, pool, host, namespace, name)
 		if err != nil {
-			return nil, err
+			return err
 		}
-
-		for _, obj := range page.Contents {
-			objects = append(objects, *obj.Key)
+		if err := index.UploadProviderVersionIndex(ctx, store, providerIndex); err != nil {
+			return err
 		}
+		slog.InfoContext(ctx, "Regenerated provider version index",
+			"provider", fmt.Sprintf("%s/%s", namespace, name), "versions", len(providerIndex.Versions))
 	}
 
-	return objects, nil
-}
-
-func deleteS3Objects(ctx context.Context, client *s3.Client, bucket string, keys []string) (int, error) {
-	if len(keys) == 0 {
-		return 0, nil
+	globalIndex, err := index.RebuildGlobalProviderIndex(ctx, pool, host)
+	if err != nil {
+		return err
 	}
+	if err := index.UploadGlobalProviderIndex(ctx, store, "providers/index.json", globalIndex); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Rebuilt global provider index", "providers", len(globalIndex.Providers))
 
-	var totalDeleted int
-
-	// Delete in batches of 1000 (S3 API limit)
-	for i := 0; i < len(keys); i += 1000 {
-		end := i + 1000
-		if end > len(keys) {
-			end = len(keys)
-		}
-
-		batch := keys[i:end]
-		objects := make([]types.ObjectIdentifier, len(batch))
-		for j, key := range batch {
-			objects[j] = types.ObjectIdentifier{Key: aws.String(key)}
-		}
+	stats, err := search.Publish(ctx, pool, store, "search.ndjson")
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Regenerated search index", "items", stats.Items, "deleted", stats.Deleted)
+	return nil
+}
 
-		_, err := client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
-			Bucket: aws.String(bucket),
-			Delete: &types.Delete{Objects: objects},
-		})
-		if err != nil {
-			return totalDeleted, err
-		}
+func printSummary(namespace string, name string, version string, versionCount int, docCount int, licenseCount int, s3Objects []string, s3Prefix string) {
+	if version == "" {
+		fmt.Printf("\nProvider: %s/%s (all versions)\n", namespace, name)
+	} else {
+		fmt.Printf("\nProvider version: %s/%s@%s\n", namespace, name, version)
+	}
+	fmt.Printf("Database records to delete:\n")
+	if version == "" {
+		fmt.Printf("  - 1 providers record\n")
+	}
+	fmt.Printf("  - %d provider_versions records\n", versionCount)
+	fmt.Printf("  - %d provider_documents records\n", docCount)
+	fmt.Printf("  - %d provider_version_licenses records\n", licenseCount)
+	fmt.Printf("S3 objects to delete: %d (prefix: %s)\n", len(s3Objects), s3Prefix)
+}
 
-		totalDeleted += len(batch)
+func listObjectKeys(ctx context.Context, store bucket.Store, prefix string) ([]string, error) {
+	objects, err := store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
 	}
 
-	return totalDeleted, nil
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+	return keys, nil
 }
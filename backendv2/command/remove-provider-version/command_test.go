@@ -0,0 +1,88 @@
+package removeproviderversion
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+)
+
+func TestFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "version", args: []string{"--namespace", "hashicorp", "--name", "aws", "--version", "5.0.0"}},
+		{name: "all", args: []string{"--namespace", "hashicorp", "--name", "aws", "--all"}},
+		{name: "neither", args: []string{"--namespace", "hashicorp", "--name", "aws"}, wantErr: true},
+		{name: "both", args: []string{"--namespace", "hashicorp", "--name", "aws", "--version", "5.0.0", "--all"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewCommand()
+			cmd.Action = func(context.Context, *cli.Command) error { return nil }
+			err := cmd.Run(context.Background(), append([]string{cmd.Name}, tt.args...))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestObjectsToDelete(t *testing.T) {
+	store, err := bucket.NewDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{
+		"providers/hashicorp/aws/index.json",
+		"providers/hashicorp/aws/v5.0.0/index.json",
+		"providers/hashicorp/aws/v5.0.0/resources/instance.md",
+		"providers/hashicorp/aws/v5.0.0-beta/index.json",
+		"providers/hashicorp/awscc/index.json",
+	} {
+		if err := store.Put(context.Background(), bucket.Object{Key: key, Body: []byte("{}")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		want    []string
+	}{
+		{
+			name:    "version",
+			version: "v5.0.0",
+			want:    []string{"providers/hashicorp/aws/v5.0.0/index.json", "providers/hashicorp/aws/v5.0.0/resources/instance.md"},
+		},
+		{
+			name: "all versions",
+			want: []string{
+				"providers/hashicorp/aws/index.json",
+				"providers/hashicorp/aws/v5.0.0-beta/index.json",
+				"providers/hashicorp/aws/v5.0.0/index.json",
+				"providers/hashicorp/aws/v5.0.0/resources/instance.md",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listObjectKeys(context.Background(), store, objectPrefix("hashicorp", "aws", tt.version))
+			if err != nil {
+				t.Fatalf("listObjectKeys() error = %v", err)
+			}
+			slices.Sort(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listObjectKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// Package serve implements the command to serve the generated registry files and the search API locally, the same
+// way the search worker does in production.
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/urfave/cli/v3"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/search"
+)
+
+const maxTopProvidersLimit = 500
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "Serve the bucket layout and the search API over HTTP for local frontend development",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "addr",
+				Usage: "Address to listen on",
+				Value: ":8787",
+			},
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "Serve files from this local directory instead of the configured bucket",
+			},
+			&cli.StringFlag{
+				Name:  "search-db",
+				Usage: "Connection string of the database holding the search entities table, the search endpoints are disabled without it",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return run(ctx, cmd)
+		},
+	}
+}
+
+func run(ctx context.Context, cmd *cli.Command) error {
+	cfg := config.FromCLI(cmd)
+
+	var store bucket.Store
+	var err error
+	if dir := cmd.String("dir"); dir != "" {
+		store, err = bucket.NewDir(dir)
+		slog.InfoContext(ctx, "Serving files from local directory", "dir", dir)
+	} else {
+		store, err = bucket.New(ctx, &cfg.Bucket)
+		slog.InfoContext(ctx, "Serving files from configured bucket", "type", cfg.Bucket.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to initialize bucket storage: %w", err)
+	}
+
+	// The entities table is populated by the search indexer in its own database, so the search endpoints are only
+	// served when that database is given and the table exists
+	var pool *pgxpool.Pool
+	if searchDB := cmd.String("search-db"); searchDB != "" {
+		pool, err = pgxpool.New(ctx, searchDB)
+		if err != nil {
+			return fmt.Errorf("failed to connect to search database: %w", err)
+		}
+		defer pool.Close()
+
+		var exists bool
+		if err := pool.QueryRow(ctx, `SELECT to_regclass('entities') IS NOT NULL`).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check the search entities table: %w", err)
+		}
+		if !exists {
+			slog.WarnContext(ctx, "Search database has no entities table, search endpoints are disabled (run the search indexer first)")
+			pool = nil
+		}
+	} else {
+		slog.InfoContext(ctx, "No --search-db given, search endpoints are disabled")
+	}
+
+	server := &http.Server{
+		Addr:              cmd.String("addr"),
+		Handler:           newHandler(store, pool),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Listening on %s\n", server.Addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
+
+// newHandler mirrors the routing of the search worker (search/worker/src/index.ts). The search endpoints answer
+// 503 Service Unavailable when pool is nil.
+func newHandler(store bucket.Store, pool *pgxpool.Pool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		switch p := r.URL.Path; {
+		case p == "/top/providers":
+			handleTopProviders(w, r, pool)
+		case p == "/registry/docs/search" || p == "/search":
+			handleSearch(w, r, pool)
+		case p == "/":
+			handleObject(w, r, store, "index.html")
+		case strings.HasPrefix(p, "/registry/docs/"):
+			handleObject(w, r, store, strings.TrimPrefix(p, "/registry/docs/"))
+		default:
+			handleObject(w, r, store, strings.TrimPrefix(p, "/"))
+		}
+	})
+}
+
+func handleTopProviders(w http.ResponseWriter, r *http.Request, pool *pgxpool.Pool) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		http.Error(w, `Query parameter "limit" is required`, http.StatusBadRequest)
+		return
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		http.Error(w, `Query parameter "limit" must be a positive integer`, http.StatusBadRequest)
+		return
+	}
+	if limit > maxTopProvidersLimit {
+		http.Error(w, fmt.Sprintf(`Query parameter "limit" must be less than or equal to %d`, maxTopProvidersLimit), http.StatusBadRequest)
+		return
+	}
+	if pool == nil {
+		searchUnavailable(w)
+		return
+	}
+
+	providers, err := search.TopProviders(r.Context(), pool, limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to query top providers", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, r, providers)
+}
+
+func handleSearch(w http.ResponseWriter, r *http.Request, pool *pgxpool.Pool) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		http.Error(w, `Query parameter "q" is required`, http.StatusBadRequest)
+		return
+	}
+	if pool == nil {
+		searchUnavailable(w)
+		return
+	}
+
+	results, err := search.Query(r.Context(), pool, query)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to run search query", "error", err, "query", query)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, r, results)
+}
+
+func searchUnavailable(w http.ResponseWriter) {
+	http.Error(w, "Search is disabled, start serve with --search-db pointing at the search indexer database", http.StatusServiceUnavailable)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write response", "error", err)
+	}
+}
+
+func handleObject(w http.ResponseWriter, r *http.Request, store bucket.Store, key string) {
+	obj, err := store.Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Failed to get object", "error", err, "key", key)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if obj.ContentType != "" {
+		w.Header().Set("Content-Type", obj.ContentType)
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if _, err := w.Write(obj.Body); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write object", "error", err, "key", key)
+	}
+}
@@ -0,0 +1,73 @@
+package serve
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+)
+
+func TestHandler(t *testing.T) {
+	store, err := bucket.NewDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, body := range map[string]string{
+		"index.html":           "<html></html>",
+		"providers/index.json": `{"providers":[]}`,
+		"providers/hashicorp/aws/v5.0.0/index.json": `{"docs":{}}`,
+	} {
+		if err := store.Put(context.Background(), bucket.Object{Key: key, Body: []byte(body)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without a search database the search endpoints are disabled
+	handler := newHandler(store, nil)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "root", target: "/", wantStatus: http.StatusOK, wantBody: "<html></html>"},
+		{name: "object", target: "/providers/index.json", wantStatus: http.StatusOK, wantBody: `{"providers":[]}`},
+		{name: "docs prefix", target: "/registry/docs/providers/hashicorp/aws/v5.0.0/index.json", wantStatus: http.StatusOK, wantBody: `{"docs":{}}`},
+		{name: "missing object", target: "/providers/missing.json", wantStatus: http.StatusNotFound},
+		{name: "escaping the directory", target: "/../../etc/passwd", wantStatus: http.StatusNotFound},
+		{name: "method not allowed", method: http.MethodPost, target: "/providers/index.json", wantStatus: http.StatusMethodNotAllowed},
+		{name: "search without query", target: "/registry/docs/search", wantStatus: http.StatusBadRequest},
+		{name: "search disabled", target: "/registry/docs/search?q=aws", wantStatus: http.StatusServiceUnavailable},
+		{name: "legacy search path disabled", target: "/search?q=aws", wantStatus: http.StatusServiceUnavailable},
+		{name: "top providers without limit", target: "/top/providers", wantStatus: http.StatusBadRequest},
+		{name: "top providers invalid limit", target: "/top/providers?limit=abc", wantStatus: http.StatusBadRequest},
+		{name: "top providers limit too large", target: "/top/providers?limit=501", wantStatus: http.StatusBadRequest},
+		{name: "top providers disabled", target: "/top/providers?limit=10", wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(method, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantBody != "" && strings.TrimSpace(rec.Body.String()) != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+				t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+// Package status implements the command to report the health of the index
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/registry"
+	"github.com/opentofu/registry-ui/pkg/status"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
+)
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Report scrape status counts, skip reasons, failures, stale repository stats and versions missing from the database",
+		Description: `Summarises the provider and module versions in the database and compares them with the registry.
+
+With --max-failed or --max-missing the command exits with an error when there are more failed versions or versions
+missing from the database than allowed, so it can be used to alert after a sync.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format: 'table' or 'json'",
+				Value: "table",
+				Validator: func(s string) error {
+					if s != "table" && s != "json" {
+						return fmt.Errorf("invalid format: %s (must be 'table' or 'json')", s)
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:  "recent",
+				Usage: "Number of most recently failed versions to list",
+				Value: 20,
+			},
+			&cli.DurationFlag{
+				Name:  "stale-after",
+				Usage: "Repository stats older than this are reported as stale",
+				Value: 12 * time.Hour,
+			},
+			&cli.BoolFlag{
+				Name:  "skip-registry",
+				Usage: "Don't update the registry checkout and compare it with the database",
+			},
+			&cli.IntFlag{
+				Name:  "max-failed",
+				Usage: "Exit with an error when more provider and module versions than this failed, -1 to disable",
+				Value: -1,
+			},
+			&cli.IntFlag{
+				Name:  "max-missing",
+				Usage: "Exit with an error when more registry versions than this are missing from the database, -1 to disable",
+				Value: -1,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return run(ctx, cmd)
+		},
+	}
+}
+
+func run(ctx context.Context, cmd *cli.Command) error {
+	cfg := config.FromCLI(cmd)
+	ctx, span := telemetry.Tracer().Start(ctx, "cmd.status")
+	defer span.End()
+
+	format := cmd.String("format")
+	recent := int(cmd.Int("recent"))
+	staleAfter := cmd.Duration("stale-after")
+	skipRegistry := cmd.Bool("skip-registry")
+
+	pool, err := cfg.DB.GetPool(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize database pool: %w", err)
+	}
+	defer pool.Close()
+
+	report := &status.Report{GeneratedAt: time.Now().UTC()}
+
+	report.Providers, err = status.LoadSummary(ctx, pool, status.Providers, recent)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	report.Modules, err = status.LoadSummary(ctx, pool, status.Modules, recent)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	report.RepositoryStats, err = status.LoadRepositoryStats(ctx, pool, staleAfter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if !skipRegistry {
+		if err := compareRegistry(ctx, cfg, report); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+
+	failed := report.Providers.Failed() + report.Modules.Failed()
+	missing := len(report.Providers.MissingVersions) + len(report.Modules.MissingVersions)
+	span.SetAttributes(
+		attribute.Int("status.failed", failed),
+		attribute.Int("status.missing", missing),
+	)
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printReport(os.Stdout, report, !skipRegistry)
+	}
+
+	if maxFailed := int(cmd.Int("max-failed")); maxFailed >= 0 && failed > maxFailed {
+		return fmt.Errorf("%d failed versions, more than the allowed %d", failed, maxFailed)
+	}
+	if maxMissing := int(cmd.Int("max-missing")); maxMissing >= 0 && !skipRegistry && missing > maxMissing {
+		return fmt.Errorf("%d registry versions missing from the database, more than the allowed %d", missing, maxMissing)
+	}
+
+	return nil
+}
+
+// compareRegistry fills in the versions listed in the registry that are not in the database
+func compareRegistry(ctx context.Context, cfg *config.BackendConfig, report *status.Report) error {
+	vcsHost, err := vcs.New(cfg.VCS)
+	if err != nil {
+		return fmt.Errorf("failed to initialize VCS host: %w", err)
+	}
+	registryClient, err := registry.New(cfg.RegistryPath, vcsHost)
+	if err != nil {
+		return fmt.Errorf("failed to create registry client: %w", err)
+	}
+	if err := registryClient.Update(ctx); err != nil {
+		return fmt.Errorf("failed to update registry: %w", err)
+	}
+
+	pool, err := cfg.DB.GetPool(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database pool: %w", err)
+	}
+
+	providers, err := registryClient.ListProviders(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list providers: %w", err)
+	}
+	listed := make(map[string][]string, len(providers))
+	for _, p := range providers {
+		listed[p.Namespace+"/"+p.Name] = p.Versions
+	}
+	stored, err := status.LoadStoredVersions(ctx, pool, status.Providers)
+	if err != nil {
+		return err
+	}
+	report.Providers.MissingVersions = status.MissingVersions(listed, stored)
+
+	modules, err := registryClient.ListModules(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list modules: %w", err)
+	}
+	listed = make(map[string][]string, len(modules))
+	for _, m := range modules {
+		listed[m.Namespace+"/"+m.Name+"/"+m.Target] = m.Versions
+	}
+	stored, err = status.LoadStoredVersions(ctx, pool, status.Modules)
+	if err != nil {
+		return err
+	}
+	report.Modules.MissingVersions = status.MissingVersions(listed, stored)
+
+	return nil
+}
+
+// maxListedMissing limits how many missing versions are printed in the table, the JSON output has all of them
+const maxListedMissing = 20
+
+func printReport(out io.Writer, report *status.Report, compared bool) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	for _, section := range []struct {
+		title   string
+		summary *status.Summary
+	}{
+		{"Providers", report.Providers},
+		{"Modules", report.Modules},
+	} {
+		s := section.summary
+		fmt.Fprintf(w, "%s\n", section.title)
+		fmt.Fprintf(w, "  STATUS\tVERSIONS\n")
+		for _, st := range slices.Sorted(maps.Keys(s.Statuses)) {
+			fmt.Fprintf(w, "  %s\t%d\n", st, s.Statuses[st])
+		}
+
+		if len(s.SkipReasons) > 0 {
+			fmt.Fprintf(w, "\n  SKIP REASON\tVERSIONS\n")
+			for _, reason := range slices.Sorted(maps.Keys(s.SkipReasons)) {
+				fmt.Fprintf(w, "  %s\t%d\n", reason, s.SkipReasons[reason])
+			}
+		}
+
+		if len(s.FailuresByClass) > 0 {
+			fmt.Fprintf(w, "\n  ERROR CLASS\tSOURCE\tVERSIONS\n")
+			for _, c := range s.FailuresByClass {
+				fmt.Fprintf(w, "  %s\t%s\t%d\n", c.Class, source(c.Upstream), c.Count)
+			}
+
+			fmt.Fprintf(w, "\n  ERROR CLASS\tERROR\tVERSIONS\n")
+			for _, e := range s.FailuresByError {
+				fmt.Fprintf(w, "  %s\t%s\t%d\n", e.Class, e.Error, e.Count)
+			}
+
+			fmt.Fprintf(w, "\n  RECENT FAILURE\tERROR CLASS\tATTEMPTS\tLAST ATTEMPT\n")
+			for _, f := range s.RecentFailures {
+				fmt.Fprintf(w, "  %s@%s\t%s\t%d\t%s\n", f.Address, f.Version, f.ErrorClass, f.Attempts, f.LastAttemptAt.UTC().Format(time.RFC3339))
+			}
+		}
+
+		if compared {
+			fmt.Fprintf(w, "\n  Missing from the database: %d\n", len(s.MissingVersions))
+			for i, v := range s.MissingVersions {
+				if i == maxListedMissing {
+					fmt.Fprintf(w, "    ... and %d more\n", len(s.MissingVersions)-maxListedMissing)
+					break
+				}
+				fmt.Fprintf(w, "    %s\n", v)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	rs := report.RepositoryStats
+	fmt.Fprintf(w, "Repository stats\n")
+	fmt.Fprintf(w, "  Repositories\t%d\n", rs.Repositories)
+	fmt.Fprintf(w, "  Stale (older than %s)\t%d\n", rs.StaleAfter, rs.Stale)
+	fmt.Fprintf(w, "  Never synced\t%d\n", rs.NeverSynced)
+	if rs.OldestSyncedAt != nil {
+		fmt.Fprintf(w, "  Oldest sync\t%s\n", rs.OldestSyncedAt.UTC().Format(time.RFC3339))
+	}
+}
+
+func source(upstream bool) string {
+	if upstream {
+		return "upstream"
+	}
+	return "backend"
+}
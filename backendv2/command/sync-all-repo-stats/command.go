@@ -103,7 +103,7 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	defer pool.Close()
 
 	// Connect to the db and get a list of the repositories we're tracking
-	githubClient := repository.NewClient(ctx, &cfg.GitHub)
+	githubClient := repository.NewClient(ctx, &cfg.GitHub, cfg.VCS.GitHubAPIURL(), pool)
 
 	repos, err := repository.ListRepositoriesForStatsSync(ctx, pool, staleAfter)
 	if err != nil {
@@ -141,18 +141,24 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		syncedCount += len(stats)
 		failedCount += len(batch) - len(stats)
 
+		// With several tokens the budget is what is left across all of them, the batch only reports its own token
+		remaining := rl.Remaining
+		if total, ok := githubClient.RateLimitRemaining(repository.ResourceGraphQL); ok {
+			remaining = total
+		}
+
 		slog.InfoContext(ctx, "Synced repository stats batch",
 			"from", start, "to", end, "of", len(repos),
-			"fetched", len(stats), "points_remaining", rl.Remaining)
+			"fetched", len(stats), "points_remaining", remaining)
 
 		// safety net: if the GraphQL budget approaches the reserve, fail fast
 		// with a non-zero exit code rather than starving any co-running job.
-		if rl.Remaining > 0 && rl.Remaining <= pointReserve {
-			err := fmt.Errorf("GraphQL rate limit near floor: remaining %d <= reserve %d, reset_at %s", rl.Remaining, pointReserve, rl.ResetAt)
+		if remaining > 0 && remaining <= pointReserve {
+			err := fmt.Errorf("GraphQL rate limit near floor: remaining %d <= reserve %d, reset_at %s", remaining, pointReserve, rl.ResetAt)
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 			slog.ErrorContext(ctx, "GraphQL rate limit near floor, aborting",
-				"remaining", rl.Remaining, "reserve", pointReserve, "reset_at", rl.ResetAt)
+				"remaining", remaining, "reserve", pointReserve, "reset_at", rl.ResetAt)
 			return err
 		}
 	}
@@ -0,0 +1,274 @@
+// Package syncblocklist implements the command to load blocklist.json into the database and refresh affected indexes
+package syncblocklist
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/opentofu/registry-ui/pkg/blocklist"
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/index"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
+)
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sync-blocklist",
+		Usage: "Load blocklist.json into the database and regenerate the version indexes of affected providers and modules",
+		Description: `Replaces the blocklist table with the entries of blocklist.json.
+
+Versions of blocked providers and modules are marked as skipped with the 'blocked' reason, and the files of versions
+published before the address was blocked are deleted from the bucket. Versions skipped because of an entry that was
+removed from the blocklist are deleted from the database, so the next sync scrapes them again.
+
+The global indexes and the search index are not touched, run rebuild-global-indexes and generate-search-index
+afterwards to drop blocked addresses from them.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "file",
+				Aliases: []string{"f"},
+				Usage:   "Path to the blocklist.json file",
+				Value:   "blocklist.json",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Parse the blocklist and print its entries without touching the database",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return run(ctx, cmd)
+		},
+	}
+}
+
+func run(ctx context.Context, cmd *cli.Command) error {
+	cfg := config.FromCLI(cmd)
+	ctx, span := telemetry.Tracer().Start(ctx, "cmd.sync_blocklist")
+	defer span.End()
+
+	file := cmd.String("file")
+	dryRun := cmd.Bool("dry-run")
+
+	span.SetAttributes(
+		attribute.String("blocklist.file", file),
+		attribute.Bool("dry_run", dryRun),
+	)
+
+	entries, err := blocklist.LoadFile(file)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	slog.InfoContext(ctx, "Loaded blocklist", "file", file, "entries", len(entries))
+
+	if dryRun {
+		for _, entry := range entries {
+			fmt.Printf("  %s %s: %s\n", entry.EntityType, entry.Address(), entry.Reason)
+		}
+		fmt.Printf("\n[DRY RUN] No changes made.\n")
+		return nil
+	}
+
+	pool, err := cfg.DB.GetPool(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "Failed to connect to database", "error", err)
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	added, removed, err := blocklist.Sync(ctx, pool, entries)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to sync blocklist: %w", err)
+	}
+
+	span.SetAttributes(
+		attribute.Int("blocklist.added", len(added)),
+		attribute.Int("blocklist.removed", len(removed)),
+	)
+	slog.InfoContext(ctx, "Synced blocklist to database", "added", len(added), "removed", len(removed))
+
+	// Versions skipped while their address was blocked are deleted so the next sync scrapes them again
+	reset, err := blocklist.ResetUnblockedVersions(ctx, pool)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	vcsHost, err := vcs.New(cfg.VCS)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize VCS host: %w", err)
+	}
+
+	store, err := bucket.New(ctx, &cfg.Bucket)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize bucket storage: %w", err)
+	}
+
+	// Every entry is checked, not just the added ones, so a run that failed halfway is completed by the next one
+	var blocked []blocklist.Entry
+	var purged int
+	for _, entry := range entries {
+		versions, err := blocklist.BlockVersions(ctx, pool, entry)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		blocked = append(blocked, entry)
+		purged += purgeVersions(ctx, store, versions)
+	}
+
+	span.SetAttributes(
+		attribute.Int64("blocklist.reset_versions", reset),
+		attribute.Int("blocklist.purged_versions", purged),
+	)
+
+	if len(added) == 0 && len(removed) == 0 && len(blocked) == 0 && reset == 0 {
+		fmt.Printf("✓ Blocklist is up to date (%d entries)\n", len(entries))
+		return nil
+	}
+
+	// Regenerate the per-address indexes so is_blocked and the version statuses reflect the new state
+	var regenerated int
+	seen := map[blocklist.Entry]bool{}
+	for _, entry := range slices.Concat(added, removed, blocked) {
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		count, err := regenerateIndexes(ctx, pool, store, vcsHost, entry)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("failed to regenerate indexes for %s %s: %w", entry.EntityType, entry.Address(), err)
+		}
+		regenerated += count
+	}
+
+	fmt.Printf("✓ Synced blocklist: %d added, %d removed, %d version indexes regenerated\n", len(added), len(removed), regenerated)
+	fmt.Printf("  %d published versions of blocked addresses removed from the bucket, %d versions of unblocked addresses reset\n", purged, reset)
+	fmt.Printf("Run rebuild-global-indexes and generate-search-index to update the global indexes and search.\n")
+	if reset > 0 {
+		fmt.Printf("Run sync-providers and sync-modules to scrape the versions of unblocked addresses.\n")
+	}
+	return nil
+}
+
+// purgeVersions deletes the files of versions published before their address was blocked and returns how many
+// versions were purged. Failures are logged, left over files can be cleaned up with the reconcile command.
+func purgeVersions(ctx context.Context, store bucket.Store, versions []blocklist.Version) int {
+	var purged int
+	for _, v := range versions {
+		prefix := fmt.Sprintf("providers/%s/%s/%s/", v.Namespace, v.Name, v.Version)
+		if v.EntityType == blocklist.EntityTypeModule {
+			prefix = fmt.Sprintf("modules/%s/%s/%s/%s/", v.Namespace, v.Name, v.Target, v.Version)
+		}
+		deleted, err := bucket.DeletePrefix(ctx, store, prefix)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to delete files of blocked version", "prefix", prefix, "error", err)
+			continue
+		}
+		if deleted > 0 {
+			purged++
+		}
+	}
+	return purged
+}
+
+// regenerateIndexes regenerates and uploads the version index of every known provider or module matched by entry
+func regenerateIndexes(ctx context.Context, pool *pgxpool.Pool, store bucket.Store, host vcs.Host, entry blocklist.Entry) (int, error) {
+	if entry.EntityType == blocklist.EntityTypeProvider {
+		rows, err := pool.Query(ctx, `
+			SELECT namespace, name
+			FROM providers
+			WHERE lower(namespace) = $1 AND ($2 = '' OR lower(name) = $2)`,
+			entry.Namespace, entry.Name)
+		if err != nil {
+			return 0, fmt.Errorf("failed to query providers: %w", err)
+		}
+		var addrs [][2]string
+		for rows.Next() {
+			var addr [2]string
+			if err := rows.Scan(&addr[0], &addr[1]); err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("failed to scan provider: %w", err)
+			}
+			addrs = append(addrs, addr)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+
+		for _, addr := range addrs {
+			providerIndex, err := index.GenerateProviderVersionIndex(ctx, pool, host, addr[0], addr[1])
+			if err != nil {
+				return 0, err
+			}
+			if err := index.UploadProviderVersionIndex(ctx, store, providerIndex); err != nil {
+				return 0, err
+			}
+			slog.InfoContext(ctx, "Regenerated provider version index",
+				"provider", fmt.Sprintf("%s/%s", addr[0], addr[1]), "is_blocked", providerIndex.IsBlocked)
+		}
+		return len(addrs), nil
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT namespace, name, target
+		FROM modules
+		WHERE lower(namespace) = $1 AND ($2 = '' OR (lower(name) = $2 AND lower(target) = $3))`,
+		entry.Namespace, entry.Name, entry.Target)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query modules: %w", err)
+	}
+	var addrs [][3]string
+	for rows.Next() {
+		var addr [3]string
+		if err := rows.Scan(&addr[0], &addr[1], &addr[2]); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan module: %w", err)
+		}
+		addrs = append(addrs, addr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, addr := range addrs {
+		moduleIndex, err := index.GenerateModuleVersionIndex(ctx, pool, host, addr[0], addr[1], addr[2])
+		if err != nil {
+			return 0, err
+		}
+		if err := index.UploadModuleVersionIndex(ctx, store, moduleIndex); err != nil {
+			return 0, err
+		}
+		slog.InfoContext(ctx, "Regenerated module version index",
+			"module", fmt.Sprintf("%s/%s/%s", addr[0], addr[1], addr[2]), "is_blocked", moduleIndex.IsBlocked)
+	}
+	return len(addrs), nil
+}
@@ -0,0 +1,431 @@
+// Package syncchanged implements the command to sync only the providers and modules that changed in the registry
+// since the last synced commit
+package syncchanged
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/git"
+	"github.com/opentofu/registry-ui/pkg/module"
+	modulestorage "github.com/opentofu/registry-ui/pkg/module/storage"
+	"github.com/opentofu/registry-ui/pkg/provider"
+	providerstorage "github.com/opentofu/registry-ui/pkg/provider/storage"
+	"github.com/opentofu/registry-ui/pkg/registry"
+	"github.com/opentofu/registry-ui/pkg/scrapeerr"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
+)
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sync-changed",
+		Usage: "Sync the providers and modules whose registry files changed since the last synced registry commit",
+		Description: `Updates the registry checkout and diffs it against the registry commit recorded by the last completed
+sync-changed, sync-providers or sync-modules run (without filter or version). Only providers and modules whose JSON
+files changed are synced, along with those that have failed versions due for a retry. When no commit was recorded
+yet, or the recorded commit is no longer in the local clone, every provider or module is synced instead.
+
+The new registry commit is recorded once every changed provider or module was synced, even when some of them failed.
+Their failed versions are picked up again by later runs once they are due for a retry.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "type",
+				Aliases: []string{"t"},
+				Usage:   "Resource type: 'provider', 'module' or 'all'",
+				Value:   "all",
+				Validator: func(s string) error {
+					if s != "provider" && s != "module" && s != "all" {
+						return fmt.Errorf("invalid type: %s (must be 'provider', 'module' or 'all')", s)
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "Diff against this registry commit instead of the recorded one",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only list the changed providers and modules, don't sync them",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return run(ctx, cmd)
+		},
+	}
+}
+
+func run(ctx context.Context, cmd *cli.Command) error {
+	cfg := config.FromCLI(cmd)
+	ctx, span := telemetry.Tracer().Start(ctx, "cmd.sync_changed")
+	defer span.End()
+
+	// Generate batch job ID for correlating all provider and module traces
+	batchJobID := uuid.New().String()
+
+	resourceType := cmd.String("type")
+	since := cmd.String("since")
+	dryRun := cmd.Bool("dry-run")
+
+	span.SetAttributes(
+		attribute.String("resource.type", resourceType),
+		attribute.String("since", since),
+		attribute.Bool("dry_run", dryRun),
+		attribute.String(telemetry.BatchJobIDKey, batchJobID),
+		attribute.String(telemetry.BatchJobNameKey, "sync-changed"),
+	)
+
+	pool, err := cfg.DB.GetPool(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize database pool: %w", err)
+	}
+	defer pool.Close()
+
+	vcsHost, err := vcs.New(cfg.VCS)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize VCS host: %w", err)
+	}
+
+	registryClient, err := registry.New(cfg.RegistryPath, vcsHost)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	err = registryClient.Update(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to update registry: %w", err)
+	}
+
+	head, err := registryClient.Head(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to resolve registry commit: %w", err)
+	}
+	span.SetAttributes(attribute.String("registry.commit", head))
+
+	if resourceType == "provider" || resourceType == "all" {
+		if err := syncChangedProviders(ctx, cfg, pool, registryClient, since, head, batchJobID, dryRun); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+	if resourceType == "module" || resourceType == "all" {
+		if err := syncChangedModules(ctx, cfg, pool, registryClient, since, head, batchJobID, dryRun); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// changesSince diffs the registry against since, or the commit recorded for kind when since is empty. It returns nil
+// changes when every entry has to be synced because there is nothing to diff against.
+func changesSince(ctx context.Context, pool *pgxpool.Pool, registryClient *registry.Client, kind, since string) (*registry.Changes, error) {
+	if since == "" {
+		var err error
+		since, err = registry.LastSyncedCommit(ctx, pool, kind)
+		if err != nil {
+			return nil, err
+		}
+		if since == "" {
+			slog.WarnContext(ctx, "No synced registry commit recorded, syncing everything", "kind", kind)
+			return nil, nil
+		}
+	}
+
+	changes, err := registryClient.ChangedSince(ctx, since)
+	if errors.Is(err, git.ErrCommitNotFound) {
+		slog.WarnContext(ctx, "Synced registry commit is not in the local clone, syncing everything",
+			"kind", kind, "commit", since)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff registry since %s: %w", since, err)
+	}
+	return changes, nil
+}
+
+func syncChangedProviders(ctx context.Context, cfg *config.BackendConfig, pool *pgxpool.Pool, registryClient *registry.Client, since, head, batchJobID string, dryRun bool) error {
+	changes, err := changesSince(ctx, pool, registryClient, registry.SyncStateProviders, since)
+	if err != nil {
+		return err
+	}
+
+	var providers []registry.Provider
+	if changes != nil {
+		providers = changes.Providers
+		for _, addr := range changes.RemovedProviders {
+			slog.WarnContext(ctx, "Provider was removed from the registry, use remove-provider-version --all to remove it",
+				"provider", addr)
+		}
+		retry, err := retryProviders(ctx, cfg, pool, registryClient, providers)
+		if err != nil {
+			return err
+		}
+		providers = append(providers, retry...)
+	} else {
+		providers, err = registryClient.ListProviders(ctx, "")
+		if err != nil {
+			return fmt.Errorf("failed to list providers: %w", err)
+		}
+	}
+
+	fmt.Printf("%d providers to sync\n", len(providers))
+	if dryRun {
+		for _, prov := range providers {
+			fmt.Printf("  %s/%s\n", prov.Namespace, prov.Name)
+		}
+		return nil
+	}
+
+	providerReader, err := provider.NewProviderReader(ctx, cfg, pool)
+	if err != nil {
+		return fmt.Errorf("failed to create provider reader: %w", err)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(cfg.Concurrency.Provider)
+
+	var (
+		successCount int
+		failCount    int
+		mu           sync.Mutex
+	)
+
+	for _, prov := range providers {
+		g.Go(func() error {
+			// Start a new trace for each provider, linked back to the batch job span
+			provCtx, provSpan := telemetry.LinkedSpanStart(gctx, fmt.Sprintf("provider.sync.%s/%s", prov.Namespace, prov.Name),
+				trace.WithAttributes(
+					attribute.String(telemetry.BatchJobIDKey, batchJobID),
+					attribute.String(telemetry.BatchJobNameKey, "sync-changed"),
+					attribute.String("provider.namespace", prov.Namespace),
+					attribute.String("provider.name", prov.Name),
+				),
+			)
+			defer provSpan.End()
+
+			syncErr := providerReader.ScrapeAllVersions(provCtx, &prov)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if syncErr != nil {
+				slog.ErrorContext(provCtx, "Failed to sync provider",
+					"provider", fmt.Sprintf("%s/%s", prov.Namespace, prov.Name), "error", syncErr)
+				provSpan.RecordError(syncErr)
+				failCount++
+			} else {
+				successCount++
+			}
+
+			return nil // Don't fail the entire operation if one provider fails
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("provider sync failed: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Changed provider sync completed",
+		"total", len(providers),
+		"success", successCount,
+		"failed", failCount)
+
+	if failCount > 0 {
+		fmt.Printf("%d providers failed, their failed versions are retried by later runs\n", failCount)
+	}
+	if err := registry.SetLastSyncedCommit(ctx, pool, registry.SyncStateProviders, head); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Synced %d providers up to registry commit %s\n", successCount, head)
+	return nil
+}
+
+func syncChangedModules(ctx context.Context, cfg *config.BackendConfig, pool *pgxpool.Pool, registryClient *registry.Client, since, head, batchJobID string, dryRun bool) error {
+	changes, err := changesSince(ctx, pool, registryClient, registry.SyncStateModules, since)
+	if err != nil {
+		return err
+	}
+
+	var modules []registry.Module
+	if changes != nil {
+		modules = changes.Modules
+		for _, addr := range changes.RemovedModules {
+			slog.WarnContext(ctx, "Module was removed from the registry, use remove-module-version --all to remove it",
+				"module", addr)
+		}
+		retry, err := retryModules(ctx, cfg, pool, registryClient, modules)
+		if err != nil {
+			return err
+		}
+		modules = append(modules, retry...)
+	} else {
+		modules, err = registryClient.ListModules(ctx, "")
+		if err != nil {
+			return fmt.Errorf("failed to list modules: %w", err)
+		}
+	}
+
+	fmt.Printf("%d modules to sync\n", len(modules))
+	if dryRun {
+		for _, mod := range modules {
+			fmt.Printf("  %s/%s/%s\n", mod.Namespace, mod.Name, mod.Target)
+		}
+		return nil
+	}
+
+	moduleReader, err := module.NewModuleReader(ctx, cfg, pool)
+	if err != nil {
+		return fmt.Errorf("failed to create module reader: %w", err)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(cfg.Concurrency.Module)
+
+	var (
+		successCount int
+		failCount    int
+		mu           sync.Mutex
+	)
+
+	for _, mod := range modules {
+		g.Go(func() error {
+			// Start a new trace for each module, linked back to the batch job span
+			modCtx, modSpan := telemetry.LinkedSpanStart(gctx, fmt.Sprintf("module.sync.%s/%s/%s", mod.Namespace, mod.Name, mod.Target),
+				trace.WithAttributes(
+					attribute.String(telemetry.BatchJobIDKey, batchJobID),
+					attribute.String(telemetry.BatchJobNameKey, "sync-changed"),
+					attribute.String("module.namespace", mod.Namespace),
+					attribute.String("module.name", mod.Name),
+					attribute.String("module.target", mod.Target),
+				),
+			)
+			defer modSpan.End()
+
+			scrapeErr := moduleReader.ScrapeAllVersions(modCtx, &mod)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if scrapeErr != nil {
+				slog.ErrorContext(modCtx, "Module sync failed",
+					"module", fmt.Sprintf("%s/%s/%s", mod.Namespace, mod.Name, mod.Target), "error", scrapeErr)
+				modSpan.RecordError(scrapeErr)
+				failCount++
+			} else {
+				successCount++
+			}
+
+			return nil // Don't fail the entire operation if one module fails
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("module sync failed: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Changed module sync completed",
+		"total", len(modules),
+		"success", successCount,
+		"failed", failCount)
+
+	if failCount > 0 {
+		fmt.Printf("%d modules failed, their failed versions are retried by later runs\n", failCount)
+	}
+	if err := registry.SetLastSyncedCommit(ctx, pool, registry.SyncStateModules, head); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Synced %d modules up to registry commit %s\n", successCount, head)
+	return nil
+}
+
+// retryProviders returns the providers that did not change in the registry but have failed versions that are due for
+// a retry
+func retryProviders(ctx context.Context, cfg *config.BackendConfig, pool *pgxpool.Pool, registryClient *registry.Client, changed []registry.Provider) ([]registry.Provider, error) {
+	failed, err := providerstorage.GetFailedProviderVersions(ctx, pool, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(changed))
+	for _, prov := range changed {
+		seen[prov.Namespace+"/"+prov.Name] = true
+	}
+
+	now := time.Now()
+	var providers []registry.Provider
+	for _, f := range failed {
+		addr := f.Namespace + "/" + f.Name
+		if seen[addr] || !scrapeerr.Parse(f.ErrorClass).Retryable() || !cfg.Retry.Eligible(f.Attempts, f.LastAttemptAt, now) {
+			continue
+		}
+		seen[addr] = true
+
+		prov, err := registryClient.GetProvider(ctx, f.Namespace, f.Name)
+		if err != nil {
+			slog.WarnContext(ctx, "Provider with failed versions is not in the registry", "provider", addr, "error", err)
+			continue
+		}
+		providers = append(providers, *prov)
+	}
+	return providers, nil
+}
+
+// retryModules returns the modules that did not change in the registry but have failed versions that are due for a
+// retry
+func retryModules(ctx context.Context, cfg *config.BackendConfig, pool *pgxpool.Pool, registryClient *registry.Client, changed []registry.Module) ([]registry.Module, error) {
+	failed, err := modulestorage.GetFailedModuleVersions(ctx, pool, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(changed))
+	for _, mod := range changed {
+		seen[mod.Namespace+"/"+mod.Name+"/"+mod.Target] = true
+	}
+
+	now := time.Now()
+	var modules []registry.Module
+	for _, f := range failed {
+		addr := f.Namespace + "/" + f.Name + "/" + f.Target
+		if seen[addr] || !scrapeerr.Parse(f.ErrorClass).Retryable() || !cfg.Retry.Eligible(f.Attempts, f.LastAttemptAt, now) {
+			continue
+		}
+		seen[addr] = true
+
+		mod, err := registryClient.GetModule(ctx, f.Namespace, f.Name, f.Target)
+		if err != nil {
+			slog.WarnContext(ctx, "Module with failed versions is not in the registry", "module", addr, "error", err)
+			continue
+		}
+		modules = append(modules, *mod)
+	}
+	return modules, nil
+}
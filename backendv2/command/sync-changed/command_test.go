@@ -0,0 +1,90 @@
+package syncchanged
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/registry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
+)
+
+func TestChangesSinceShallowClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_CONFIG_GLOBAL="+os.DevNull,
+			"GIT_CONFIG_NOSYSTEM=1",
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(dir, file, contents string) string {
+		t.Helper()
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git(dir, "add", "-A")
+		git(dir, "commit", "-q", "-m", "update "+file)
+		return git(dir, "rev-parse", "HEAD")
+	}
+
+	upstream := t.TempDir()
+	git(upstream, "init", "-q", "-b", "main")
+	first := commit(upstream, "providers/h/hashicorp/aws.json", `{"versions":[{"version":"5.0.0"}]}`)
+	second := commit(upstream, "providers/h/hashicorp/aws.json", `{"versions":[{"version":"5.1.0"},{"version":"5.0.0"}]}`)
+
+	shallow := filepath.Join(t.TempDir(), "registry")
+	git(upstream, "clone", "-q", "--depth", "1", "--branch", "main", "file://"+upstream, shallow)
+
+	host, err := vcs.New(config.VCSConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := registry.New(shallow, host)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		since        string
+		wantFullSync bool
+	}{
+		// The commit predates the depth-1 clone, so everything is synced instead of failing
+		{name: "commit missing from the clone", since: first, wantFullSync: true},
+		{name: "commit in the clone", since: second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The pool is only used to look up the last synced commit when since is empty
+			changes, err := changesSince(context.Background(), nil, client, registry.SyncStateProviders, tt.since)
+			if err != nil {
+				t.Fatalf("changesSince() error = %v", err)
+			}
+			if fullSync := changes == nil; fullSync != tt.wantFullSync {
+				t.Errorf("changesSince() full sync = %v, want %v", fullSync, tt.wantFullSync)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/opentofu/registry-ui/pkg/module"
 	"github.com/opentofu/registry-ui/pkg/registry"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 func NewCommand() *cli.Command {
@@ -86,7 +87,14 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("failed to create module reader: %w", err)
 	}
 
-	registryClient, err := registry.New(cfg.RegistryPath)
+	vcsHost, err := vcs.New(cfg.VCS)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize VCS host: %w", err)
+	}
+
+	registryClient, err := registry.New(cfg.RegistryPath, vcsHost)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -100,6 +108,15 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("failed to update registry: %w", err)
 	}
 
+	// A full sweep is the starting point for sync-changed, remember which registry commit it covered
+	fullSweep := filter == "" && specificVersion == ""
+	head, err := registryClient.Head(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to resolve registry commit: %w", err)
+	}
+
 	modules, err := registryClient.ListModules(ctx, filter)
 	if err != nil {
 		span.RecordError(err)
@@ -187,5 +204,15 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		"success", successCount,
 		"failed", failCount)
 
+	// Failed versions are retried by later runs, so the commit is recorded even when some modules failed
+	if fullSweep {
+		if err := registry.SetLastSyncedCommit(ctx, pool, registry.SyncStateModules, head); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		slog.InfoContext(ctx, "Recorded synced registry commit", "commit", head)
+	}
+
 	return nil
 }
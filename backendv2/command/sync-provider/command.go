@@ -15,6 +15,7 @@ import (
 	"github.com/opentofu/registry-ui/pkg/provider"
 	"github.com/opentofu/registry-ui/pkg/registry"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 func NewCommand() *cli.Command {
@@ -70,7 +71,14 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	// Fetch provider data from registry
-	registryClient, err := registry.New(cfg.RegistryPath)
+	vcsHost, err := vcs.New(cfg.VCS)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to initialize VCS host: %w", err)
+	}
+
+	registryClient, err := registry.New(cfg.RegistryPath, vcsHost)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
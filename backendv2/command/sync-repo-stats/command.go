@@ -19,7 +19,7 @@ import (
 func NewCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "sync-repo-stats",
-		Usage: "Sync repository statistics (stars, forks, etc.) from GitHub",
+		Usage: "Sync repository statistics (stars, forks, etc.) from the configured VCS host",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:     "namespace",
@@ -66,11 +66,14 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	}
 	defer pool.Close()
 
-	// Create GitHub client
-	githubClient := repository.NewClient(ctx, &cfg.GitHub)
+	// Create the metadata client for the configured VCS host
+	metadataSource := repository.NewMetadataSource(ctx, cfg, pool)
+	if metadataSource == nil {
+		return fmt.Errorf("repository metadata is not available for VCS type %q", cfg.VCS.Type)
+	}
 
 	// Sync repository metadata directly using org/repo name
-	err = repository.SyncRepositoryMetadata(ctx, pool, githubClient, org, repoName)
+	err = repository.SyncRepositoryMetadata(ctx, pool, metadataSource, org, repoName)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
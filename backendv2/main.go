@@ -11,14 +11,22 @@ import (
 
 	"github.com/urfave/cli/v3"
 
+	configcmd "github.com/opentofu/registry-ui/command/config"
 	dltofunightly "github.com/opentofu/registry-ui/command/dl-tofu-nightly"
+	generatesearchindex "github.com/opentofu/registry-ui/command/generate-search-index"
 	getmodulelicense "github.com/opentofu/registry-ui/command/get-module-license"
 	getproviderlicense "github.com/opentofu/registry-ui/command/get-provider-license"
 	rebuildglobalindexes "github.com/opentofu/registry-ui/command/rebuild-global-indexes"
+	"github.com/opentofu/registry-ui/command/reconcile"
+	removemoduleversion "github.com/opentofu/registry-ui/command/remove-module-version"
 	removeproviderversion "github.com/opentofu/registry-ui/command/remove-provider-version"
 	retryversion "github.com/opentofu/registry-ui/command/retry-version"
+	"github.com/opentofu/registry-ui/command/serve"
 	skipversion "github.com/opentofu/registry-ui/command/skip-version"
+	"github.com/opentofu/registry-ui/command/status"
 	syncallrepostats "github.com/opentofu/registry-ui/command/sync-all-repo-stats"
+	syncblocklist "github.com/opentofu/registry-ui/command/sync-blocklist"
+	syncchanged "github.com/opentofu/registry-ui/command/sync-changed"
 	syncmodule "github.com/opentofu/registry-ui/command/sync-module"
 	syncmodules "github.com/opentofu/registry-ui/command/sync-modules"
 	syncprovider "github.com/opentofu/registry-ui/command/sync-provider"
@@ -31,6 +39,7 @@ import (
 
 // configFreeCommands lists subcommands that don't require config, telemetry, or DB.
 var configFreeCommands = map[string]bool{
+	"config":          true,
 	"dl-tofu-nightly": true,
 }
 
@@ -46,13 +55,20 @@ func main() {
 		Name:     "registry-ui",
 		Usage:    "OpenTofu Registry Backend CLI",
 		Metadata: map[string]interface{}{},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Usage:   "Path to the config file, defaults to config.yaml in the working directory if it exists",
+			},
+		},
 		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
 			// Skip config/telemetry setup for commands that don't need it
 			if cmd.Args().Present() && configFreeCommands[cmd.Args().First()] {
 				return ctx, nil
 			}
 
-			backendConfig, err := config.LoadConfig(ctx)
+			backendConfig, err := config.LoadConfig(ctx, cmd.String("config"))
 			if err != nil {
 				return ctx, fmt.Errorf("error loading config: %w", err)
 			}
@@ -63,7 +79,16 @@ func main() {
 			if err != nil {
 				return ctx, fmt.Errorf("failed to initialize telemetry: %w", err)
 			}
-			shutdown = s
+
+			shutdownMetrics, err := telemetry.SetupMetrics(ctx, backendConfig.Telemetry)
+			if err != nil {
+				s()
+				return ctx, fmt.Errorf("failed to initialize metrics: %w", err)
+			}
+			shutdown = func() {
+				shutdownMetrics()
+				s()
+			}
 
 			if backendConfig.Telemetry.Enabled {
 				// Ensure that our OTLP exporters can connect correctly
@@ -93,6 +118,7 @@ func main() {
 			syncproviders.NewCommand(),
 			syncmodule.NewCommand(),
 			syncmodules.NewCommand(),
+			syncchanged.NewCommand(),
 			syncrepostats.NewCommand(),
 			syncallrepostats.NewCommand(),
 			getmodulelicense.NewCommand(),
@@ -101,8 +127,15 @@ func main() {
 			skipversion.NewCommand(),
 			retryversion.NewCommand(),
 			removeproviderversion.NewCommand(),
+			removemoduleversion.NewCommand(),
+			reconcile.NewCommand(),
+			status.NewCommand(),
+			syncblocklist.NewCommand(),
+			generatesearchindex.NewCommand(),
 			db.NewMigrateCommand(),
+			configcmd.NewCommand(),
 			dltofunightly.NewCommand(),
+			serve.NewCommand(),
 		},
 	}
 
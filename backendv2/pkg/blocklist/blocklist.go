@@ -0,0 +1,97 @@
+package blocklist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// SkipReason is stored in skip_reason for versions that were not scraped because their address is blocked.
+	SkipReason = "blocked"
+
+	EntityTypeProvider = "provider"
+	EntityTypeModule   = "module"
+)
+
+// Entry is a single blocklist rule. Name and Target are empty when the entry blocks an entire namespace.
+type Entry struct {
+	EntityType string
+	Namespace  string
+	Name       string
+	Target     string
+	Reason     string
+}
+
+// Address returns the entry in the same slash-separated form used in blocklist.json.
+func (e Entry) Address() string {
+	parts := []string{e.Namespace}
+	if e.Name != "" {
+		parts = append(parts, e.Name)
+	}
+	if e.Target != "" {
+		parts = append(parts, e.Target)
+	}
+	return strings.Join(parts, "/")
+}
+
+// file mirrors the blocklist.json format understood by the legacy backend: maps of address to reason, where the
+// address may be just a namespace to block everything published under it.
+type file struct {
+	Providers map[string]string `json:"providers"`
+	Modules   map[string]string `json:"modules"`
+}
+
+// LoadFile reads a blocklist.json file and returns its entries.
+func LoadFile(path string) ([]Entry, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blocklist %s: %w", path, err)
+	}
+	return Parse(contents)
+}
+
+// Parse parses the contents of a blocklist.json file. Addresses are lowercased, matching how the registry treats them.
+func Parse(contents []byte) ([]Entry, error) {
+	var f file
+	if err := json.Unmarshal(contents, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse blocklist: %w", err)
+	}
+
+	var entries []Entry
+	for addr, reason := range f.Providers {
+		parts := strings.Split(strings.ToLower(addr), "/")
+		entry := Entry{EntityType: EntityTypeProvider, Namespace: parts[0], Reason: reason}
+		switch len(parts) {
+		case 1:
+		case 2:
+			entry.Name = parts[1]
+		default:
+			return nil, fmt.Errorf("invalid provider address in blocklist: %q (expected namespace or namespace/name)", addr)
+		}
+		if entry.Namespace == "" || (len(parts) == 2 && entry.Name == "") {
+			return nil, fmt.Errorf("invalid provider address in blocklist: %q", addr)
+		}
+		entries = append(entries, entry)
+	}
+
+	for addr, reason := range f.Modules {
+		parts := strings.Split(strings.ToLower(addr), "/")
+		entry := Entry{EntityType: EntityTypeModule, Namespace: parts[0], Reason: reason}
+		switch len(parts) {
+		case 1:
+		case 3:
+			entry.Name = parts[1]
+			entry.Target = parts[2]
+		default:
+			return nil, fmt.Errorf("invalid module address in blocklist: %q (expected namespace or namespace/name/target)", addr)
+		}
+		if entry.Namespace == "" || (len(parts) == 3 && (entry.Name == "" || entry.Target == "")) {
+			return nil, fmt.Errorf("invalid module address in blocklist: %q", addr)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
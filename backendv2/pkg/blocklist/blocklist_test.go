@@ -0,0 +1,72 @@
+package blocklist
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Entry
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			input: `{"providers": {}, "modules": {}}`,
+			want:  nil,
+		},
+		{
+			name:  "provider address",
+			input: `{"providers": {"Example/Foo": "takedown"}}`,
+			want:  []Entry{{EntityType: EntityTypeProvider, Namespace: "example", Name: "foo", Reason: "takedown"}},
+		},
+		{
+			name:  "provider namespace",
+			input: `{"providers": {"example": "spam"}}`,
+			want:  []Entry{{EntityType: EntityTypeProvider, Namespace: "example", Reason: "spam"}},
+		},
+		{
+			name:  "module address",
+			input: `{"modules": {"example/vpc/aws": "takedown"}}`,
+			want:  []Entry{{EntityType: EntityTypeModule, Namespace: "example", Name: "vpc", Target: "aws", Reason: "takedown"}},
+		},
+		{
+			name:  "module namespace",
+			input: `{"modules": {"example": "spam"}}`,
+			want:  []Entry{{EntityType: EntityTypeModule, Namespace: "example", Reason: "spam"}},
+		},
+		{
+			name:    "module without target",
+			input:   `{"modules": {"example/vpc": "takedown"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "provider with too many parts",
+			input:   `{"providers": {"example/foo/bar": "takedown"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty name",
+			input:   `{"providers": {"example/": "takedown"}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Parse()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
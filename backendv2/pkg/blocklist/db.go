@@ -0,0 +1,268 @@
+package blocklist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// Queryable is an interface that both pgx.Tx and *pgxpool.Pool implement
+type Queryable interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Sync replaces the contents of the blocklist table with the given entries in a single transaction.
+// It returns the entries that were added and removed compared to what was stored before.
+func Sync(ctx context.Context, db *pgxpool.Pool, entries []Entry) (added []Entry, removed []Entry, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "blocklist.sync")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("blocklist.entries", len(entries)))
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	existing, err := List(ctx, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wanted := make(map[string]Entry, len(entries))
+	for _, entry := range entries {
+		wanted[entry.EntityType+":"+entry.Address()] = entry
+	}
+	stored := make(map[string]Entry, len(existing))
+	for _, entry := range existing {
+		stored[entry.EntityType+":"+entry.Address()] = entry
+	}
+
+	for key, entry := range stored {
+		if _, ok := wanted[key]; ok {
+			continue
+		}
+		_, err := tx.Exec(ctx, `
+			DELETE FROM blocklist
+			WHERE entity_type = $1 AND namespace = $2 AND name = $3 AND target = $4`,
+			entry.EntityType, entry.Namespace, entry.Name, entry.Target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to delete blocklist entry %s: %w", entry.Address(), err)
+		}
+		removed = append(removed, entry)
+	}
+
+	for key, entry := range wanted {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO blocklist (entity_type, namespace, name, target, reason)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (entity_type, namespace, name, target)
+			DO UPDATE SET
+				reason = EXCLUDED.reason,
+				updated_at = NOW()
+			WHERE blocklist.reason IS DISTINCT FROM EXCLUDED.reason`,
+			entry.EntityType, entry.Namespace, entry.Name, entry.Target, entry.Reason)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to store blocklist entry %s: %w", entry.Address(), err)
+		}
+		if _, ok := stored[key]; !ok {
+			added = append(added, entry)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	span.SetAttributes(
+		attribute.Int("blocklist.added", len(added)),
+		attribute.Int("blocklist.removed", len(removed)),
+	)
+
+	return added, removed, nil
+}
+
+// List returns every entry currently stored in the blocklist table
+func List(ctx context.Context, db Queryable) ([]Entry, error) {
+	rows, err := db.Query(ctx, `
+		SELECT entity_type, namespace, name, target, reason
+		FROM blocklist
+		ORDER BY entity_type, namespace, name, target`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blocklist: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		if err := rows.Scan(&entry.EntityType, &entry.Namespace, &entry.Name, &entry.Target, &entry.Reason); err != nil {
+			return nil, fmt.Errorf("failed to scan blocklist entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Version is a provider or module version matched by a blocklist entry. Target is empty for providers.
+type Version struct {
+	EntityType string
+	Namespace  string
+	Name       string
+	Target     string
+	Version    string
+}
+
+// BlockVersions marks the versions of the providers or modules matched by entry as skipped because their address is
+// blocked and returns the versions that weren't marked yet, whose files may still be published. Versions removed from
+// the registry are left alone.
+func BlockVersions(ctx context.Context, db Queryable, entry Entry) ([]Version, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "blocklist.block_versions")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("blocklist.entity_type", entry.EntityType),
+		attribute.String("blocklist.address", entry.Address()),
+	)
+
+	var (
+		query string
+		args  []any
+	)
+	if entry.EntityType == EntityTypeProvider {
+		query = `
+			UPDATE provider_versions
+			SET scrape_status = 'skipped',
+			    skip_reason = $3,
+			    error_message = NULL,
+			    error_class = NULL
+			WHERE lower(provider_namespace) = $1
+			  AND ($2 = '' OR lower(provider_name) = $2)
+			  AND scrape_status <> 'removed'
+			  AND skip_reason IS DISTINCT FROM $3
+			RETURNING provider_namespace, provider_name, '', version`
+		args = []any{entry.Namespace, entry.Name, SkipReason}
+	} else {
+		query = `
+			UPDATE module_versions
+			SET scrape_status = 'skipped',
+			    skip_reason = $4,
+			    error_message = NULL,
+			    error_class = NULL
+			WHERE lower(module_namespace) = $1
+			  AND ($2 = '' OR (lower(module_name) = $2 AND lower(module_target) = $3))
+			  AND scrape_status <> 'removed'
+			  AND skip_reason IS DISTINCT FROM $4
+			RETURNING module_namespace, module_name, module_target, version`
+		args = []any{entry.Namespace, entry.Name, entry.Target, SkipReason}
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark versions of %s as blocked: %w", entry.Address(), err)
+	}
+	defer rows.Close()
+
+	var versions []Version
+	for rows.Next() {
+		v := Version{EntityType: entry.EntityType}
+		if err := rows.Scan(&v.Namespace, &v.Name, &v.Target, &v.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan blocked version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("blocklist.versions", len(versions)))
+	return versions, nil
+}
+
+// ResetUnblockedVersions deletes the versions skipped because of a blocklist entry that no longer exists, so the next
+// sync scrapes them like new versions. It returns the number of versions reset.
+func ResetUnblockedVersions(ctx context.Context, db Queryable) (int64, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "blocklist.reset_unblocked_versions")
+	defer span.End()
+
+	providers, err := db.Exec(ctx, `
+		DELETE FROM provider_versions v
+		WHERE v.scrape_status = 'skipped'
+		  AND v.skip_reason = $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM blocklist b
+			WHERE b.entity_type = 'provider'
+			  AND b.namespace = lower(v.provider_namespace)
+			  AND (b.name = lower(v.provider_name) OR b.name = '')
+		  )`, SkipReason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset unblocked provider versions: %w", err)
+	}
+
+	modules, err := db.Exec(ctx, `
+		DELETE FROM module_versions v
+		WHERE v.scrape_status = 'skipped'
+		  AND v.skip_reason = $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM blocklist b
+			WHERE b.entity_type = 'module'
+			  AND b.namespace = lower(v.module_namespace)
+			  AND ((b.name = lower(v.module_name) AND b.target = lower(v.module_target)) OR (b.name = '' AND b.target = ''))
+		  )`, SkipReason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset unblocked module versions: %w", err)
+	}
+
+	reset := providers.RowsAffected() + modules.RowsAffected()
+	span.SetAttributes(attribute.Int64("blocklist.reset_versions", reset))
+	return reset, nil
+}
+
+// IsProviderBlocked returns true if the provider, or its whole namespace, is blocked along with the reason why.
+// An entry for the exact address takes precedence over a namespace-wide entry.
+func IsProviderBlocked(ctx context.Context, db Queryable, namespace, name string) (bool, string, error) {
+	return isBlocked(ctx, db, `
+		SELECT reason
+		FROM blocklist
+		WHERE entity_type = 'provider'
+		  AND namespace = lower($1)
+		  AND (name = lower($2) OR name = '')
+		ORDER BY name DESC
+		LIMIT 1`, namespace, name)
+}
+
+// IsModuleBlocked returns true if the module, or its whole namespace, is blocked along with the reason why.
+// An entry for the exact address takes precedence over a namespace-wide entry.
+func IsModuleBlocked(ctx context.Context, db Queryable, namespace, name, target string) (bool, string, error) {
+	return isBlocked(ctx, db, `
+		SELECT reason
+		FROM blocklist
+		WHERE entity_type = 'module'
+		  AND namespace = lower($1)
+		  AND ((name = lower($2) AND target = lower($3)) OR (name = '' AND target = ''))
+		ORDER BY name DESC
+		LIMIT 1`, namespace, name, target)
+}
+
+func isBlocked(ctx context.Context, db Queryable, query string, args ...any) (bool, string, error) {
+	var reason string
+	err := db.QueryRow(ctx, query, args...).Scan(&reason)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("failed to query blocklist: %w", err)
+	}
+	return true, reason, nil
+}
@@ -0,0 +1,2 @@
+// Package blocklist loads the registry blocklist and answers whether a provider or module address is blocked.
+package blocklist
@@ -0,0 +1,183 @@
+package bucket
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// Dir stores objects as files in a local directory, using the object key as the relative path. Object metadata
+// is not stored.
+type Dir struct {
+	directory string
+}
+
+// NewDir creates a store in directory, creating the directory if it doesn't exist
+func NewDir(directory string) (*Dir, error) {
+	stat, err := os.Stat(directory)
+	switch {
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(directory, 0o755); err != nil {
+			return nil, fmt.Errorf("storage directory %s does not exist and cannot be created: %w", directory, err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("storage directory %s is inaccessible: %w", directory, err)
+	case !stat.IsDir():
+		return nil, fmt.Errorf("storage location %s exists, but is not a directory", directory)
+	}
+
+	return &Dir{directory: directory}, nil
+}
+
+// path returns the file path of key, keys can't escape the directory
+func (d *Dir) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" {
+		return "", fmt.Errorf("invalid key: %q", key)
+	}
+	return filepath.Join(d.directory, filepath.FromSlash(cleaned)), nil
+}
+
+func (d *Dir) Put(ctx context.Context, obj Object) error {
+	target, err := d.path(obj.Key)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(target)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("cannot create directory %s for %s: %w", dir, obj.Key, err)
+	}
+
+	// Write to a temporary file first so readers never see a partially written file
+	tmp, err := os.CreateTemp(dir, ".tmp-"+filepath.Base(target)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", obj.Key, err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err := tmp.Write(obj.Body); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", obj.Key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", obj.Key, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", obj.Key, err)
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return err
+	}
+	telemetry.RecordUpload(ctx, config.BucketTypeFilesystem, len(obj.Body))
+	return nil
+}
+
+func (d *Dir) Get(_ context.Context, key string) (*Object, error) {
+	target, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, fmt.Errorf("%s: %w", key, fs.ErrNotExist)
+	}
+
+	body, err := os.ReadFile(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Object{
+		Key:         key,
+		Body:        body,
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+	}, nil
+}
+
+func (d *Dir) List(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+
+	// Only walk the deepest directory that can contain matching keys
+	root := d.directory
+	if dir := path.Dir(prefix); dir != "." && dir != "/" {
+		root = filepath.Join(d.directory, filepath.FromSlash(dir))
+	}
+
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(d.directory, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		body, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		hash := md5.Sum(body)
+		objects = append(objects, ObjectInfo{
+			Key:          key,
+			Size:         int64(len(body)),
+			ETag:         hex.EncodeToString(hash[:]),
+			LastModified: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+
+	return objects, nil
+}
+
+func (d *Dir) Delete(_ context.Context, keys []string) (int, error) {
+	var deleted int
+	for _, key := range keys {
+		target, err := d.path(key)
+		if err != nil {
+			return deleted, err
+		}
+		if err := os.Remove(target); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return deleted, fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
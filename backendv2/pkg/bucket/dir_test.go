@@ -0,0 +1,96 @@
+package bucket
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store, err := NewDir(filepath.Join(root, "bucket"))
+	if err != nil {
+		t.Fatalf("NewDir: %v", err)
+	}
+
+	objects := map[string]string{
+		"providers/hashicorp/aws/index.json":        `{"addr":"hashicorp/aws"}`,
+		"providers/hashicorp/aws/v1.0.0/index.json": `{}`,
+		"providers/hashicorp/azurerm/index.json":    `{}`,
+		"modules/index.json":                        `[]`,
+	}
+	for key, body := range objects {
+		if err := store.Put(ctx, Object{Key: key, Body: []byte(body), ContentType: "application/json"}); err != nil {
+			t.Fatalf("Put(%s): %v", key, err)
+		}
+	}
+
+	t.Run("get", func(t *testing.T) {
+		obj, err := store.Get(ctx, "providers/hashicorp/aws/index.json")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if string(obj.Body) != objects["providers/hashicorp/aws/index.json"] {
+			t.Errorf("unexpected body %q", obj.Body)
+		}
+		if obj.ContentType != "application/json" {
+			t.Errorf("unexpected content type %q", obj.ContentType)
+		}
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		for _, key := range []string{"providers/missing/index.json", "providers/hashicorp"} {
+			if _, err := store.Get(ctx, key); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Get(%s): expected fs.ErrNotExist, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("keys stay inside the directory", func(t *testing.T) {
+		if err := store.Put(ctx, Object{Key: "../escape.json", Body: []byte("{}")}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "escape.json")); !os.IsNotExist(err) {
+			t.Errorf("object was written outside of the store directory")
+		}
+		if _, err := store.Delete(ctx, []string{"escape.json"}); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+	})
+
+	tests := []struct {
+		prefix string
+		want   int
+	}{
+		{prefix: "providers/hashicorp/aws", want: 2},
+		{prefix: "providers/hashicorp/aws/", want: 2},
+		{prefix: "providers/", want: 3},
+		{prefix: "", want: 4},
+		{prefix: "providers/opentofu/", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run("list "+tt.prefix, func(t *testing.T) {
+			listed, err := store.List(ctx, tt.prefix)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(listed) != tt.want {
+				t.Errorf("expected %d objects, got %d: %v", tt.want, len(listed), listed)
+			}
+		})
+	}
+
+	t.Run("delete", func(t *testing.T) {
+		deleted, err := store.Delete(ctx, []string{"modules/index.json", "modules/missing.json"})
+		if err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if deleted != 1 {
+			t.Errorf("expected 1 deleted object, got %d", deleted)
+		}
+	})
+}
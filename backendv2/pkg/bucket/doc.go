@@ -0,0 +1,3 @@
+// Package bucket abstracts the storage of the generated registry files (the bucket layout served to the frontend).
+// Files are stored in an S3 compatible bucket in production, or in a local directory for laptops and air-gapped CI.
+package bucket
@@ -0,0 +1,142 @@
+package bucket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// S3 stores objects in an S3 compatible bucket
+type S3 struct {
+	client   *s3.Client
+	uploader *manager.Uploader
+	bucket   string
+}
+
+func NewS3(client *s3.Client, bucketName string) *S3 {
+	return &S3{
+		client:   client,
+		uploader: manager.NewUploader(client),
+		bucket:   bucketName,
+	}
+}
+
+func (s *S3) Put(ctx context.Context, obj Object) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "bucket.s3.put")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("bucket", s.bucket),
+		attribute.String("key", obj.Key),
+		attribute.String("content-type", obj.ContentType),
+		attribute.Int64("size", int64(len(obj.Body))),
+	)
+
+	input := &s3.PutObjectInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(obj.Key),
+		Body:     bytes.NewReader(obj.Body),
+		Metadata: obj.Metadata,
+	}
+	if obj.ContentType != "" {
+		input.ContentType = aws.String(obj.ContentType)
+	}
+
+	if _, err := s.uploader.Upload(ctx, input); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	telemetry.RecordUpload(ctx, config.BucketTypeS3, len(obj.Body))
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (*Object, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%s: %w", key, fs.ErrNotExist)
+		}
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	body, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+
+	return &Object{
+		Key:         key,
+		Body:        body,
+		ContentType: aws.ToString(out.ContentType),
+		Metadata:    out.Metadata,
+	}, nil
+}
+
+func (s *S3) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:          aws.ToString(obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				ETag:         strings.Trim(aws.ToString(obj.ETag), `"`),
+				LastModified: aws.ToTime(obj.LastModified),
+			})
+		}
+	}
+
+	return objects, nil
+}
+
+func (s *S3) Delete(ctx context.Context, keys []string) (int, error) {
+	var totalDeleted int
+
+	// Delete in batches of 1000 (S3 API limit)
+	for batch := range slices.Chunk(keys, 1000) {
+		objects := make([]types.ObjectIdentifier, len(batch))
+		for j, key := range batch {
+			objects[j] = types.ObjectIdentifier{Key: aws.String(key)}
+		}
+
+		_, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &types.Delete{Objects: objects},
+		})
+		if err != nil {
+			return totalDeleted, err
+		}
+
+		totalDeleted += len(batch)
+	}
+
+	return totalDeleted, nil
+}
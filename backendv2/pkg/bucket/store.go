@@ -0,0 +1,73 @@
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+)
+
+// Object is a file in the bucket layout
+type Object struct {
+	Key         string
+	Body        []byte
+	ContentType string
+	// Metadata is stored as object metadata where the backend supports it
+	Metadata map[string]string
+}
+
+// ObjectInfo describes a stored object without its contents
+type ObjectInfo struct {
+	Key  string
+	Size int64
+	// ETag is the MD5 checksum of the contents for objects uploaded in a single part to an unencrypted or SSE-S3
+	// encrypted bucket
+	ETag         string
+	LastModified time.Time
+}
+
+// Store reads and writes files of the bucket layout
+type Store interface {
+	// Put writes an object, replacing any existing object with the same key
+	Put(ctx context.Context, obj Object) error
+	// Get reads an object. It returns an error wrapping fs.ErrNotExist if the key does not exist.
+	Get(ctx context.Context, key string) (*Object, error)
+	// List returns all objects whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// Delete removes the given keys and returns the number of deleted objects. Missing keys are ignored.
+	Delete(ctx context.Context, keys []string) (int, error)
+}
+
+// New creates the store configured in cfg
+func New(ctx context.Context, cfg *config.BucketConfig) (Store, error) {
+	switch cfg.Type {
+	case config.BucketTypeFilesystem:
+		return NewDir(cfg.Directory)
+	case "", config.BucketTypeS3:
+		client, err := cfg.GetClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize S3 client: %w", err)
+		}
+		return NewS3(client, cfg.BucketName), nil
+	default:
+		return nil, fmt.Errorf("unsupported bucket type %q", cfg.Type)
+	}
+}
+
+// DeletePrefix deletes all objects whose key starts with prefix and returns the number of deleted objects
+func DeletePrefix(ctx context.Context, store Store, prefix string) (int, error) {
+	objects, err := store.List(ctx, prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list objects under %s: %w", prefix, err)
+	}
+	if len(objects) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+	return store.Delete(ctx, keys)
+}
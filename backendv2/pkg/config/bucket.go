@@ -17,19 +17,56 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+const (
+	BucketTypeS3         = "s3"
+	BucketTypeFilesystem = "filesystem"
+)
+
+const (
+	SSENone = "none"
+	SSES3   = "sse-s3"
+	SSEKMS  = "sse-kms"
+	SSEC    = "sse-c"
+)
+
 type BucketConfig struct {
+	// Type selects where generated files are stored: s3 (default) or filesystem
+	Type string `koanf:"type"`
+	// Directory is the local directory used by the filesystem type
+	Directory string `koanf:"directory"`
+
 	BucketName string `koanf:"name"`
 
 	AccessKeyID     string `koanf:"accesskeyid"`
-	SecretAccessKey string `koanf:"secretaccesskey"`
+	SecretAccessKey string `koanf:"secretaccesskey" secret:"true"`
 	Region          string `koanf:"region"`
 	Endpoint        string `koanf:"endpoint"` // S3-compatible endpoint URL, leave empty for AWS S3
+	// ServerSideEncryption is the default encryption of the bucket: none (default), sse-s3, sse-kms or sse-c. It
+	// doesn't change how objects are uploaded, but the ETags of SSE-KMS and SSE-C objects are not MD5 checksums.
+	ServerSideEncryption string `koanf:"serversideencryption"`
 
 	client     *s3.Client
 	httpClient *http.Client
 }
 
 func (c *BucketConfig) Validate() error {
+	switch c.Type {
+	case BucketTypeFilesystem:
+		if c.Directory == "" {
+			return fmt.Errorf("bucket.directory is required for the filesystem bucket type")
+		}
+		return nil
+	case "", BucketTypeS3:
+	default:
+		return fmt.Errorf("bucket.type must be one of %s or %s, got %q", BucketTypeS3, BucketTypeFilesystem, c.Type)
+	}
+
+	switch c.ServerSideEncryption {
+	case "", SSENone, SSES3, SSEKMS, SSEC:
+	default:
+		return fmt.Errorf("bucket.serverSideEncryption must be one of %s, %s, %s or %s, got %q", SSENone, SSES3, SSEKMS, SSEC, c.ServerSideEncryption)
+	}
+
 	if c.AccessKeyID == "" {
 		return fmt.Errorf("bucket.accessKeyID is required")
 	}
@@ -44,6 +81,14 @@ func (c *BucketConfig) Validate() error {
 	return nil
 }
 
+// ETagsAreMD5 reports whether the ETags of objects uploaded in a single part are the MD5 checksum of their contents
+func (c *BucketConfig) ETagsAreMD5() bool {
+	if c.Type == BucketTypeFilesystem {
+		return true
+	}
+	return c.ServerSideEncryption == "" || c.ServerSideEncryption == SSENone || c.ServerSideEncryption == SSES3
+}
+
 func (c *BucketConfig) GetClient(ctx context.Context) (*s3.Client, error) {
 	if c.client != nil {
 		return c.client, nil
@@ -2,9 +2,12 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
+	"reflect"
 	"strings"
 
 	"github.com/knadh/koanf/parsers/yaml"
@@ -13,8 +16,6 @@ import (
 	"github.com/knadh/koanf/v2"
 )
 
-var k = koanf.New(".")
-
 const EnvVarPrefix = "REGISTRY_"
 
 type BackendConfig struct {
@@ -24,11 +25,17 @@ type BackendConfig struct {
 	License     LicenseConfig     `koanf:"license"`
 	Concurrency ConcurrencyConfig `koanf:"concurrency"`
 	GitHub      GitHubConfig      `koanf:"github"`
+	VCS         VCSConfig         `koanf:"vcs"`
+
+	ProviderSchema  ProviderSchemaConfig  `koanf:"providerschema"`
+	RemovedVersions RemovedVersionsConfig `koanf:"removedversions"`
+	Retry           RetryConfig           `koanf:"retry"`
 
 	WorkDir      string `koanf:"workdir"`
 	RegistryPath string `koanf:"registrypath"`
 }
 
+// Validate checks the configuration and fills in defaults. It does not touch the filesystem, see EnsureDirs.
 func (c *BackendConfig) Validate() error {
 	if err := c.Bucket.Validate(); err != nil {
 		return err
@@ -46,69 +53,192 @@ func (c *BackendConfig) Validate() error {
 		return fmt.Errorf("registryPath is required")
 	}
 
-	// make the directory if it doesn't exist
-	err := os.MkdirAll(c.WorkDir, 0o755)
-	if err != nil {
-		return fmt.Errorf("could not create workDir: %w", err)
+	if err := c.License.Validate(); err != nil {
+		return err
 	}
 
-	err = os.MkdirAll(c.RegistryPath, 0o755)
-	if err != nil {
-		return fmt.Errorf("could not create registryPath: %w", err)
+	if err := c.Concurrency.Validate(); err != nil {
+		return err
 	}
 
-	if err := c.License.Validate(); err != nil {
+	// The GitHub token is only needed when repositories are hosted on GitHub
+	if c.VCS.Type == "" || c.VCS.Type == VCSTypeGitHub {
+		if err := c.GitHub.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.VCS.Validate(); err != nil {
 		return err
 	}
 
-	if err := c.Concurrency.Validate(); err != nil {
+	if err := c.ProviderSchema.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.RemovedVersions.Validate(); err != nil {
 		return err
 	}
 
-	if err := c.GitHub.Validate(); err != nil {
+	if err := c.Retry.Validate(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// EnsureDirs creates the work and registry directories if they don't exist
+func (c *BackendConfig) EnsureDirs() error {
+	if err := os.MkdirAll(c.WorkDir, 0o755); err != nil {
+		return fmt.Errorf("could not create workDir: %w", err)
+	}
+	if err := os.MkdirAll(c.RegistryPath, 0o755); err != nil {
+		return fmt.Errorf("could not create registryPath: %w", err)
+	}
+	return nil
+}
+
 const defaultConfigFile = "config.yaml"
 
-func LoadConfig(ctx context.Context) (*BackendConfig, error) {
+// FileEnvSuffix marks an environment variable whose value is the path of a file holding the setting, e.g.
+// REGISTRY_GITHUB_TOKEN_FILE=/run/secrets/github-token. Trailing newlines are removed from the contents.
+const FileEnvSuffix = "_FILE"
+
+// Sources maps each configuration key that was set explicitly to where it was set: the config file path or the
+// name of the environment variable. Keys missing from it have their default value.
+type Sources map[string]string
+
+// LoadConfig loads the configuration with Load, validates it and creates the directories it refers to
+func LoadConfig(ctx context.Context, path string) (*BackendConfig, error) {
+	backendConfig, _, err := Load(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := backendConfig.Validate(); err != nil {
+		slog.ErrorContext(ctx, "Configuration validation failed", "error", err)
+		return nil, err
+	}
+
+	if err := backendConfig.EnsureDirs(); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Configuration loaded and validated successfully")
+	return backendConfig, nil
+}
+
+// Load reads the config file at path and REGISTRY_ environment variables without validating the result.
+// When path is empty config.yaml in the working directory is used if it exists, an explicit path must exist.
+// Environment variables take precedence over the file, each override is logged as a warning.
+func Load(ctx context.Context, path string) (*BackendConfig, Sources, error) {
 	slog.InfoContext(ctx, "Loading configuration")
 
-	err := k.Load(file.Provider(defaultConfigFile), yaml.Parser())
-	if err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("failed to load config file %s: %w", defaultConfigFile, err)
+	k := koanf.New(".")
+	sources := Sources{}
+
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
 	}
-	if err != nil {
-		slog.InfoContext(ctx, "Config file not found, will use environment variables only", "filename", defaultConfigFile)
+
+	err := k.Load(file.Provider(path), yaml.Parser())
+	switch {
+	case err == nil:
+		for _, key := range k.Keys() {
+			sources[key] = path
+		}
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+		slog.InfoContext(ctx, "Config file not found, will use environment variables only", "filename", path)
+	default:
+		return nil, nil, fmt.Errorf("failed to load config file %s: %w", path, err)
 	}
 
 	// also load from env vars
+	envSources := map[string]string{}
+	var envErrs []error
 	err = k.Load(env.Provider(".", env.Opt{
 		Prefix: EnvVarPrefix,
-		TransformFunc: func(k, v string) (string, any) {
-			k = strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(k, EnvVarPrefix)), "_", ".")
-			return k, v
+		TransformFunc: func(name, v string) (string, any) {
+			key := envKey(strings.TrimSuffix(name, FileEnvSuffix))
+			if strings.HasSuffix(name, FileEnvSuffix) {
+				contents, err := os.ReadFile(v)
+				if err != nil {
+					envErrs = append(envErrs, fmt.Errorf("failed to read %s: %w", name, err))
+					return "", nil
+				}
+				v = strings.TrimRight(string(contents), "\r\n")
+			}
+			if other, ok := envSources[key]; ok {
+				envErrs = append(envErrs, fmt.Errorf("both %s and %s set %s, only one of them may be used", other, name, key))
+			}
+			envSources[key] = name
+			if listKeys[key] {
+				return key, splitList(v)
+			}
+			return key, v
 		},
 	}), nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if err := errors.Join(envErrs...); err != nil {
+		return nil, nil, err
 	}
 	slog.InfoContext(ctx, "Loaded environment variables with prefix", "prefix", EnvVarPrefix)
 
+	for key, name := range envSources {
+		if configFile, ok := sources[key]; ok {
+			slog.WarnContext(ctx, "Environment variable overrides config file value", "key", key, "env", name, "file", configFile)
+		}
+		sources[key] = name
+	}
+
 	var backendConfig BackendConfig
 	if err := k.Unmarshal("", &backendConfig); err != nil {
 		slog.ErrorContext(ctx, "Failed to unmarshal configuration", "error", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	if err := backendConfig.Validate(); err != nil {
-		slog.ErrorContext(ctx, "Configuration validation failed", "error", err)
-		return nil, err
+	return &backendConfig, sources, nil
+}
+
+// listKeys are the keys of list settings, their environment variables hold comma separated values
+var listKeys = collectListKeys(reflect.TypeFor[BackendConfig](), "", map[string]bool{})
+
+func collectListKeys(t reflect.Type, prefix string, keys map[string]bool) map[string]bool {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("koanf")
+		if !field.IsExported() || tag == "" || tag == "-" {
+			continue
+		}
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			collectListKeys(field.Type, key, keys)
+		case reflect.Slice:
+			keys[key] = true
+		}
 	}
+	return keys
+}
 
-	slog.InfoContext(ctx, "Configuration loaded and validated successfully")
-	return &backendConfig, nil
+func splitList(v string) []string {
+	var items []string
+	for item := range strings.SplitSeq(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envKey converts an environment variable name to its configuration key, e.g. REGISTRY_DB_CONNECTIONSTRING to
+// db.connectionstring
+func envKey(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(name, EnvVarPrefix)), "_", ".")
 }
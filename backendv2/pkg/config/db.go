@@ -6,10 +6,13 @@ import (
 
 	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
 type DBConfig struct {
-	ConnectionString string `koanf:"connectionstring"`
+	ConnectionString string `koanf:"connectionstring" secret:"true"`
 	pool             *pgxpool.Pool
 }
 
@@ -49,6 +52,60 @@ func (c *DBConfig) GetPool(ctx context.Context) (*pgxpool.Pool, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	registerPoolMetrics(pool)
+
 	c.pool = pool
 	return pool, nil
 }
+
+// registerPoolMetrics reports connection pool usage on every metric collection so pool saturation during
+// concurrent scraping is visible without tracing
+func registerPoolMetrics(pool *pgxpool.Pool) {
+	meter := otel.Meter("github.com/opentofu/registry-ui/pkg/config")
+
+	connections, err := meter.Int64ObservableGauge("db.client.connection.count",
+		metric.WithDescription("Connections in the pool by state"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		otel.Handle(err)
+		return
+	}
+	maxConnections, err := meter.Int64ObservableGauge("db.client.connection.max",
+		metric.WithDescription("Maximum number of connections in the pool"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		otel.Handle(err)
+		return
+	}
+	saturation, err := meter.Float64ObservableGauge("db.client.connection.saturation",
+		metric.WithDescription("Share of the maximum connections that are in use"),
+		metric.WithUnit("1"))
+	if err != nil {
+		otel.Handle(err)
+		return
+	}
+	emptyAcquires, err := meter.Int64ObservableCounter("db.client.connection.empty_acquires",
+		metric.WithDescription("Acquires that had to wait for a connection because the pool was empty"),
+		metric.WithUnit("{acquire}"))
+	if err != nil {
+		otel.Handle(err)
+		return
+	}
+
+	used := metric.WithAttributes(attribute.String("state", "used"))
+	idle := metric.WithAttributes(attribute.String("state", "idle"))
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stat := pool.Stat()
+		o.ObserveInt64(connections, int64(stat.AcquiredConns()), used)
+		o.ObserveInt64(connections, int64(stat.IdleConns()), idle)
+		o.ObserveInt64(maxConnections, int64(stat.MaxConns()))
+		if stat.MaxConns() > 0 {
+			o.ObserveFloat64(saturation, float64(stat.AcquiredConns())/float64(stat.MaxConns()))
+		}
+		o.ObserveInt64(emptyAcquires, stat.EmptyAcquireCount())
+		return nil
+	}, connections, maxConnections, saturation, emptyAcquires)
+	if err != nil {
+		otel.Handle(err)
+	}
+}
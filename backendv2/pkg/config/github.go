@@ -1,16 +1,96 @@
 package config
 
 import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 )
 
 type GitHubConfig struct {
-	Token string `koanf:"token"`
+	Token string `koanf:"token" secret:"true"`
+	// Tokens are additional personal access tokens. Requests are spread over all tokens and the app installation
+	// by remaining rate limit, so syncs keep running when one of them is exhausted.
+	Tokens []string `koanf:"tokens" secret:"true"`
+	// App authenticates as a GitHub App installation, its tokens are refreshed before they expire
+	App GitHubAppConfig `koanf:"app"`
+	// RateLimitReserve pauses all requests until the rate limit resets once every credential has no more than this
+	// many requests left, so concurrent callers never run into the limit. Defaults to 50.
+	RateLimitReserve int `koanf:"ratelimitreserve"`
+}
+
+type GitHubAppConfig struct {
+	ID             int64 `koanf:"id"`
+	InstallationID int64 `koanf:"installationid"`
+	// PrivateKey is the PEM encoded private key of the app, usually set with REGISTRY_GITHUB_APP_PRIVATEKEY_FILE
+	PrivateKey string `koanf:"privatekey" secret:"true"`
+}
+
+// Configured reports whether any GitHub credentials are set
+func (c *GitHubConfig) Configured() bool {
+	return c.Token != "" || len(c.Tokens) > 0 || c.App.Configured()
 }
 
 func (c *GitHubConfig) Validate() error {
-	if c.Token == "" {
-		return fmt.Errorf("github.token is required")
+	if !c.Configured() {
+		return fmt.Errorf("github.token, github.tokens or github.app is required")
+	}
+	if c.RateLimitReserve < 0 {
+		return fmt.Errorf("github.rateLimitReserve must be greater than or equal to 0")
+	}
+	if c.RateLimitReserve == 0 {
+		c.RateLimitReserve = 50
+	}
+	for i, token := range c.Tokens {
+		if token == "" {
+			return fmt.Errorf("github.tokens[%d] is empty", i)
+		}
+	}
+	if c.App.Configured() {
+		if err := c.App.Validate(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+// Configured reports whether any of the app settings are set
+func (c *GitHubAppConfig) Configured() bool {
+	return c.ID != 0 || c.InstallationID != 0 || c.PrivateKey != ""
+}
+
+func (c *GitHubAppConfig) Validate() error {
+	if c.ID <= 0 {
+		return fmt.Errorf("github.app.id is required")
+	}
+	if c.InstallationID <= 0 {
+		return fmt.Errorf("github.app.installationID is required")
+	}
+	if c.PrivateKey == "" {
+		return fmt.Errorf("github.app.privateKey is required")
+	}
+	if _, err := c.ParsePrivateKey(); err != nil {
+		return fmt.Errorf("github.app.privateKey is invalid: %w", err)
+	}
+	return nil
+}
+
+// ParsePrivateKey decodes the PEM encoded private key, GitHub issues PKCS#1 keys but PKCS#8 is accepted as well
+func (c *GitHubAppConfig) ParsePrivateKey() (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(c.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an RSA key, got %T", parsed)
+	}
+	return key, nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type ProviderSchemaConfig struct {
+	// Enabled turns on extracting provider schemas with `tofu providers schema -json` while indexing provider versions.
+	Enabled bool `koanf:"enabled"`
+
+	// DownloadURL replaces the scheme and host of the provider artifact download URLs from the registry,
+	// e.g. to point at a local file server in tests. Leave empty to download from the upstream release.
+	DownloadURL string `koanf:"downloadurl"`
+}
+
+func (c *ProviderSchemaConfig) Validate() error {
+	if c.DownloadURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.DownloadURL)
+	if err != nil {
+		return fmt.Errorf("providerSchema.downloadURL is invalid: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("providerSchema.downloadURL must be an absolute URL")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package config
+
+import "fmt"
+
+const (
+	// RemovedVersionsMark keeps versions removed from the registry in the database with the 'removed' status
+	RemovedVersionsMark = "mark"
+	// RemovedVersionsDelete deletes versions removed from the registry from the database and the bucket
+	RemovedVersionsDelete = "delete"
+)
+
+type RemovedVersionsConfig struct {
+	// Action decides what happens to versions that are in the database but no longer in the registry: mark (default)
+	// or delete. Either way they are no longer listed in the version indexes.
+	Action string `koanf:"action"`
+}
+
+func (c *RemovedVersionsConfig) Validate() error {
+	switch c.Action {
+	case "":
+		c.Action = RemovedVersionsMark
+	case RemovedVersionsMark, RemovedVersionsDelete:
+	default:
+		return fmt.Errorf("removedVersions.action must be one of %s or %s, got %q", RemovedVersionsMark, RemovedVersionsDelete, c.Action)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type RetryConfig struct {
+	// MaxAttempts is the number of times a version is scraped before a failure is considered permanent. Set to 1 to
+	// disable retries, failed versions can then only be retried with the retry-version command.
+	MaxAttempts int `koanf:"maxattempts"`
+	// Backoff is the wait after the first failed attempt, it doubles with every further attempt up to MaxBackoff
+	Backoff    time.Duration `koanf:"backoff"`
+	MaxBackoff time.Duration `koanf:"maxbackoff"`
+}
+
+func (c *RetryConfig) Validate() error {
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("retry.maxAttempts must be greater than or equal to 0")
+	}
+	if c.Backoff < 0 || c.MaxBackoff < 0 {
+		return fmt.Errorf("retry.backoff and retry.maxBackoff must not be negative")
+	}
+
+	// Set defaults for zero values
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 3
+	}
+	if c.Backoff == 0 {
+		c.Backoff = time.Hour
+	}
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = 24 * time.Hour
+	}
+	if c.MaxBackoff < c.Backoff {
+		return fmt.Errorf("retry.maxBackoff must be greater than or equal to retry.backoff")
+	}
+
+	return nil
+}
+
+// NextAttempt returns when a version that failed after the given number of attempts, the last one at lastAttempt,
+// may be scraped again. ok is false when the version has used up all of its attempts.
+func (c RetryConfig) NextAttempt(attempts int, lastAttempt time.Time) (next time.Time, ok bool) {
+	if attempts >= c.MaxAttempts {
+		return time.Time{}, false
+	}
+
+	delay := c.Backoff
+	for i := 1; i < attempts && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, c.MaxBackoff)
+
+	return lastAttempt.Add(delay), true
+}
+
+// Eligible reports whether a failed version should be scraped again at now
+func (c RetryConfig) Eligible(attempts int, lastAttempt, now time.Time) bool {
+	next, ok := c.NextAttempt(attempts, lastAttempt)
+	return ok && !now.Before(next)
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryConfigEligible(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 5, Backoff: time.Hour, MaxBackoff: 3 * time.Hour}
+	last := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		attempts int
+		elapsed  time.Duration
+		want     bool
+	}{
+		{"first failure before backoff", 1, 59 * time.Minute, false},
+		{"first failure after backoff", 1, time.Hour, true},
+		{"second failure doubles backoff", 2, 90 * time.Minute, false},
+		{"second failure after doubled backoff", 2, 2 * time.Hour, true},
+		{"backoff is capped", 4, 3 * time.Hour, true},
+		{"attempts exhausted", 5, 1000 * time.Hour, false},
+		{"unknown attempt count", 0, time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.Eligible(tt.attempts, last, last.Add(tt.elapsed)); got != tt.want {
+				t.Errorf("Eligible(%d, last, last+%s) = %v, want %v", tt.attempts, tt.elapsed, got, tt.want)
+			}
+		})
+	}
+}
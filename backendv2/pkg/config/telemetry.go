@@ -3,12 +3,17 @@ package config
 type TelemetryConfig struct {
 	Enabled     bool   `koanf:"enabled"`
 	Logging     bool   `koanf:"logging"`     // Enable/disable sending logs over OTEL
+	Metrics     bool   `koanf:"metrics"`     // Enable/disable sending metrics over OTEL
 	Exporter    string `koanf:"exporter"`    // "otlp" hopefully
 	Endpoint    string `koanf:"endpoint"`    // OTLP endpoint URL
 	ServiceName string `koanf:"servicename"` // Override service name
 	Insecure    bool   `koanf:"insecure"`    // Use HTTP instead of HTTPS
 
-	Headers map[string]string `koanf:"headers"` // Additional headers for exporter
+	Headers map[string]string `koanf:"headers" secret:"true"` // Additional headers for exporter
+
+	// PrometheusAddr serves metrics for Prometheus to scrape on this address (e.g. ":9464").
+	// It works independently of the OTLP exporter and is disabled when empty.
+	PrometheusAddr string `koanf:"prometheusaddr"`
 
 	// Distributed tracing context
 	TraceParent string `koanf:"traceparent"`
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the value of settings tagged `secret:"true"`
+const Redacted = "<redacted>"
+
+// SourceDefault is the source of values that were not set in the config file or environment
+const SourceDefault = "default"
+
+// Value is a single effective configuration setting
+type Value struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Values lists every setting of the configuration sorted by key, with secrets redacted and the source of each value
+// taken from sources
+func (c *BackendConfig) Values(sources Sources) []Value {
+	var values []Value
+	flatten(reflect.ValueOf(*c), "", false, func(key, value string) {
+		source, ok := sources[key]
+		if !ok {
+			source = SourceDefault
+		}
+		values = append(values, Value{Key: key, Value: value, Source: source})
+	})
+	slices.SortFunc(values, func(a, b Value) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return values
+}
+
+// flatten calls emit for each leaf of v using the koanf keys of the struct fields
+func flatten(v reflect.Value, prefix string, secret bool, emit func(key, value string)) {
+	if d, ok := v.Interface().(time.Duration); ok {
+		emit(prefix, redact(d.String(), secret && d != 0))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			field := t.Field(i)
+			tag := field.Tag.Get("koanf")
+			if !field.IsExported() || tag == "" || tag == "-" {
+				continue
+			}
+			key := tag
+			if prefix != "" {
+				key = prefix + "." + tag
+			}
+			flatten(v.Field(i), key, secret || field.Tag.Get("secret") == "true", emit)
+		}
+	case reflect.Map:
+		if v.Len() == 0 {
+			emit(prefix, "")
+			return
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+		for _, k := range keys {
+			flatten(v.MapIndex(k), prefix+"."+fmt.Sprint(k.Interface()), secret, emit)
+		}
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		emit(prefix, redact(strings.Join(items, ","), secret && v.Len() > 0))
+	default:
+		emit(prefix, redact(fmt.Sprint(v.Interface()), secret && !v.IsZero()))
+	}
+}
+
+func redact(value string, secret bool) string {
+	if secret {
+		return Redacted
+	}
+	return value
+}
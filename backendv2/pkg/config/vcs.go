@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultGitHubAPIURL is the REST API URL of github.com
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+const (
+	VCSTypeGitHub    = "github"
+	VCSTypeGitLab    = "gitlab"
+	VCSTypeBitbucket = "bitbucket"
+	VCSTypeGit       = "git"
+)
+
+type VCSConfig struct {
+	// Type is the kind of host provider and module repositories live on: github (default), gitlab, bitbucket or git.
+	// The git type only supports cloning, it produces no web links and fetches no repository metadata.
+	Type string `koanf:"type"`
+
+	// BaseURL is the web URL of the host, e.g. https://gitlab.example.com. Defaults to the public instance of the
+	// host type and is required for the git type. GitHub Enterprise Server is supported, the bitbucket type only
+	// supports Bitbucket Cloud.
+	BaseURL string `koanf:"baseurl"`
+
+	// Token authenticates repository metadata requests against GitLab and Bitbucket. GitHub uses github.token.
+	Token string `koanf:"token" secret:"true"`
+}
+
+func (c *VCSConfig) Validate() error {
+	switch c.Type {
+	case "", VCSTypeGitHub, VCSTypeGitLab, VCSTypeBitbucket:
+	case VCSTypeGit:
+		if c.BaseURL == "" {
+			return fmt.Errorf("vcs.baseURL is required for the git type")
+		}
+	default:
+		return fmt.Errorf("vcs.type must be one of github, gitlab, bitbucket or git, got %q", c.Type)
+	}
+
+	if c.BaseURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return fmt.Errorf("vcs.baseURL is invalid: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("vcs.baseURL must be an absolute URL")
+	}
+	// Bitbucket Server and Data Center have a different API and URL layout than Bitbucket Cloud
+	if c.Type == VCSTypeBitbucket && !isHost(u, "bitbucket.org") {
+		return fmt.Errorf("vcs.baseURL must be https://bitbucket.org for the bitbucket type, Bitbucket Server is not supported")
+	}
+	return nil
+}
+
+// GitHubAPIURL returns the REST API URL of the GitHub host: api.github.com for github.com and <baseURL>/api/v3 for
+// GitHub Enterprise Server
+func (c *VCSConfig) GitHubAPIURL() string {
+	if c.BaseURL == "" {
+		return DefaultGitHubAPIURL
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil || isHost(u, "github.com") {
+		return DefaultGitHubAPIURL
+	}
+	return strings.TrimSuffix(c.BaseURL, "/") + "/api/v3"
+}
+
+// isHost reports whether u points at host, with or without the www subdomain
+func isHost(u *url.URL, host string) bool {
+	hostname := strings.ToLower(u.Hostname())
+	return hostname == host || hostname == "www."+host
+}
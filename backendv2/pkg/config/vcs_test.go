@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestVCSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     VCSConfig
+		wantErr bool
+	}{
+		{name: "github.com", cfg: VCSConfig{}},
+		{name: "github enterprise", cfg: VCSConfig{Type: VCSTypeGitHub, BaseURL: "https://github.example.com"}},
+		{name: "bitbucket cloud", cfg: VCSConfig{Type: VCSTypeBitbucket, BaseURL: "https://bitbucket.org/"}},
+		{name: "bitbucket default", cfg: VCSConfig{Type: VCSTypeBitbucket}},
+		{name: "bitbucket server", cfg: VCSConfig{Type: VCSTypeBitbucket, BaseURL: "https://bitbucket.example.com"}, wantErr: true},
+		{name: "relative base url", cfg: VCSConfig{Type: VCSTypeGitLab, BaseURL: "gitlab.example.com"}, wantErr: true},
+		{name: "git without base url", cfg: VCSConfig{Type: VCSTypeGit}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVCSConfigGitHubAPIURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{baseURL: "", want: "https://api.github.com"},
+		{baseURL: "https://github.com", want: "https://api.github.com"},
+		{baseURL: "https://www.github.com/", want: "https://api.github.com"},
+		{baseURL: "https://github.example.com", want: "https://github.example.com/api/v3"},
+		{baseURL: "https://example.com/github/", want: "https://example.com/github/api/v3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			cfg := VCSConfig{Type: VCSTypeGitHub, BaseURL: tt.baseURL}
+			if got := cfg.GitHubAPIURL(); got != tt.want {
+				t.Errorf("GitHubAPIURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
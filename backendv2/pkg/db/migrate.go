@@ -1306,6 +1306,282 @@ ALTER TABLE provider_version_licenses
 ALTER TABLE module_version_licenses
 	DROP COLUMN IF EXISTS is_selected;`,
 	},
+	{
+		ID:          34,
+		Name:        "create_blocklist_table",
+		Description: "Create the blocklist table holding provider and module addresses (or whole namespaces) that must not be scraped or shown, loaded from the registry blocklist.json",
+		Up: `
+CREATE TABLE IF NOT EXISTS blocklist (
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('provider', 'module')),
+    namespace VARCHAR(255) NOT NULL,
+    -- Empty name/target means the entire namespace is blocked
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    target VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (entity_type, namespace, name, target)
+);
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_blocklist_updated_at
+    BEFORE UPDATE ON blocklist
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE blocklist IS 'Provider and module addresses that are blocked from being scraped and displayed, synced from blocklist.json';
+COMMENT ON COLUMN blocklist.entity_type IS 'Kind of address being blocked: provider or module';
+COMMENT ON COLUMN blocklist.namespace IS 'Lowercased namespace of the blocked address';
+COMMENT ON COLUMN blocklist.name IS 'Lowercased provider or module name, empty when the whole namespace is blocked';
+COMMENT ON COLUMN blocklist.target IS 'Lowercased module target, empty for providers and namespace-wide entries';
+COMMENT ON COLUMN blocklist.reason IS 'Human readable explanation shown to users for why the address is blocked';
+COMMENT ON COLUMN provider_versions.skip_reason IS 'Reason for skip: incompatible_license, no_license, processing_error, manual_skip, malformed_data, blocked';
+COMMENT ON COLUMN module_versions.skip_reason IS 'Reason for skip: incompatible_license, no_license, processing_error, manual_skip, malformed_data, blocked';`,
+		Down: `
+COMMENT ON COLUMN provider_versions.skip_reason IS 'Reason for skip: incompatible_license, no_license, processing_error, manual_skip, malformed_data';
+COMMENT ON COLUMN module_versions.skip_reason IS 'Reason for skip: incompatible_license, no_license, processing_error, manual_skip, malformed_data';
+DROP TRIGGER IF EXISTS update_blocklist_updated_at ON blocklist;
+DROP TABLE IF EXISTS blocklist;`,
+	},
+	{
+		ID:          35,
+		Name:        "create_search_index_items_table",
+		Description: "Create the search_index_items table tracking the contents of search.ndjson between runs so that changes and deletions can be emitted",
+		Up: `
+CREATE TABLE IF NOT EXISTS search_index_items (
+    id TEXT PRIMARY KEY,
+    item JSONB NOT NULL,
+    checksum VARCHAR(32) NOT NULL,
+    last_updated TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_index_items_deleted_at ON search_index_items(deleted_at);
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_search_index_items_updated_at
+    BEFORE UPDATE ON search_index_items
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE search_index_items IS 'Items published in search.ndjson, kept between runs to detect changed and deleted entries';
+COMMENT ON COLUMN search_index_items.id IS 'Search item ID, e.g. providers/hashicorp/aws/resources/instance or modules/terraform-aws-modules/vpc/aws';
+COMMENT ON COLUMN search_index_items.item IS 'Search item as published in the add entries of search.ndjson';
+COMMENT ON COLUMN search_index_items.checksum IS 'MD5 checksum of the item used to detect changes between runs';
+COMMENT ON COLUMN search_index_items.last_updated IS 'When the item contents last changed';
+COMMENT ON COLUMN search_index_items.deleted_at IS 'When the item disappeared from the registry, NULL while it is still present. Deletions are purged after 30 days';`,
+		Down: `
+DROP TRIGGER IF EXISTS update_search_index_items_updated_at ON search_index_items;
+DROP INDEX IF EXISTS idx_search_index_items_deleted_at;
+DROP TABLE IF EXISTS search_index_items;`,
+	},
+	{
+		ID:          36,
+		Name:        "create_provider_schemas_table",
+		Description: "Create the provider_schemas table holding the resource, data source, ephemeral resource and function schemas extracted with tofu providers schema -json",
+		Up: `
+CREATE TABLE IF NOT EXISTS provider_schemas (
+    provider_namespace VARCHAR(255) NOT NULL,
+    provider_name VARCHAR(255) NOT NULL,
+    version VARCHAR(255) NOT NULL,
+    schema_type VARCHAR(50) NOT NULL CHECK (schema_type IN ('provider', 'resource', 'data_source', 'ephemeral_resource', 'function')),
+    schema_name VARCHAR(255) NOT NULL,
+    schema JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (provider_namespace, provider_name, version, schema_type, schema_name),
+    FOREIGN KEY (provider_namespace, provider_name, version)
+        REFERENCES provider_versions(provider_namespace, provider_name, version) ON DELETE CASCADE
+);
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_provider_schemas_updated_at
+    BEFORE UPDATE ON provider_schemas
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE provider_schemas IS 'Schemas of provider versions extracted from the provider binary with tofu providers schema -json';
+COMMENT ON COLUMN provider_schemas.schema_type IS 'Kind of schema: provider (configuration), resource, data_source, ephemeral_resource or function';
+COMMENT ON COLUMN provider_schemas.schema_name IS 'Resource, data source, ephemeral resource or function name, the provider name for the provider configuration';
+COMMENT ON COLUMN provider_schemas.schema IS 'Schema as output by tofu: the block of attributes and nested blocks, or the function signature';`,
+		Down: `
+DROP TRIGGER IF EXISTS update_provider_schemas_updated_at ON provider_schemas;
+DROP TABLE IF EXISTS provider_schemas;`,
+	},
+	{
+		ID:          37,
+		Name:        "add_generated_to_provider_documents",
+		Description: "Mark provider documents that were generated from the provider schema instead of scraped from the repository",
+		Up: `
+ALTER TABLE provider_documents
+ADD COLUMN IF NOT EXISTS generated BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN provider_documents.generated IS 'True when the document was generated from the provider schema because the provider has no docs directory';`,
+		Down: `
+ALTER TABLE provider_documents
+DROP COLUMN IF EXISTS generated;`,
+	},
+	{
+		ID:          38,
+		Name:        "add_removed_version_status",
+		Description: "Document the removed scrape status for versions that are no longer listed in the registry",
+		Up: `
+CREATE INDEX IF NOT EXISTS idx_provider_versions_not_removed ON provider_versions(provider_namespace, provider_name) WHERE scrape_status <> 'removed';
+CREATE INDEX IF NOT EXISTS idx_module_versions_not_removed ON module_versions(module_namespace, module_name, module_target) WHERE scrape_status <> 'removed';
+
+COMMENT ON COLUMN provider_versions.scrape_status IS 'Scraping status: completed (successful), skipped (intentionally excluded), failed (processing error), removed (no longer listed in the registry)';
+COMMENT ON COLUMN module_versions.scrape_status IS 'Scraping status: completed (successful), skipped (intentionally excluded), failed (processing error), removed (no longer listed in the registry)';`,
+		Down: `
+-- Without the removed status these versions become manually skipped, which keeps them out of the indexes
+UPDATE provider_versions SET scrape_status = 'skipped', skip_reason = 'manual_skip' WHERE scrape_status = 'removed';
+UPDATE module_versions SET scrape_status = 'skipped', skip_reason = 'manual_skip' WHERE scrape_status = 'removed';
+
+DROP INDEX IF EXISTS idx_module_versions_not_removed;
+DROP INDEX IF EXISTS idx_provider_versions_not_removed;
+
+COMMENT ON COLUMN provider_versions.scrape_status IS 'Scraping status: completed (successful), skipped (intentionally excluded), failed (processing error)';
+COMMENT ON COLUMN module_versions.scrape_status IS 'Scraping status: completed (successful), skipped (intentionally excluded), failed (processing error)';`,
+	},
+	{
+		ID:          39,
+		Name:        "create_registry_sync_state_table",
+		Description: "Create the registry_sync_state table recording the last registry commit that was fully synced",
+		Up: `
+CREATE TABLE IF NOT EXISTS registry_sync_state (
+    kind VARCHAR(50) PRIMARY KEY CHECK (kind IN ('providers', 'modules')),
+    commit_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_registry_sync_state_updated_at
+    BEFORE UPDATE ON registry_sync_state
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE registry_sync_state IS 'Last commit of the registry repository whose providers or modules were synced without failures, used by sync-changed';
+COMMENT ON COLUMN registry_sync_state.kind IS 'Part of the registry the commit applies to: providers or modules';
+COMMENT ON COLUMN registry_sync_state.commit_hash IS 'Hash of the registry commit that was synced';`,
+		Down: `
+DROP TRIGGER IF EXISTS update_registry_sync_state_updated_at ON registry_sync_state;
+DROP TABLE IF EXISTS registry_sync_state;`,
+	},
+	{
+		ID:          40,
+		Name:        "add_attempt_count_to_versions",
+		Description: "Count the failed scraping attempts of provider and module versions so that they can be retried with a backoff",
+		Up: `
+ALTER TABLE provider_versions
+ADD COLUMN IF NOT EXISTS attempt_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE module_versions
+ADD COLUMN IF NOT EXISTS attempt_count INTEGER NOT NULL DEFAULT 0;
+
+-- Every version that failed before the column existed failed at least once
+UPDATE provider_versions SET attempt_count = 1 WHERE scrape_status = 'failed';
+UPDATE module_versions SET attempt_count = 1 WHERE scrape_status = 'failed';
+
+CREATE INDEX IF NOT EXISTS idx_provider_versions_failed ON provider_versions(provider_namespace, provider_name) WHERE scrape_status = 'failed';
+CREATE INDEX IF NOT EXISTS idx_module_versions_failed ON module_versions(module_namespace, module_name, module_target) WHERE scrape_status = 'failed';
+
+COMMENT ON COLUMN provider_versions.attempt_count IS 'Number of failed scraping attempts since the last successful one, failed versions are retried until it reaches retry.maxAttempts';
+COMMENT ON COLUMN module_versions.attempt_count IS 'Number of failed scraping attempts since the last successful one, failed versions are retried until it reaches retry.maxAttempts';`,
+		Down: `
+DROP INDEX IF EXISTS idx_module_versions_failed;
+DROP INDEX IF EXISTS idx_provider_versions_failed;
+
+ALTER TABLE module_versions
+DROP COLUMN IF EXISTS attempt_count;
+
+ALTER TABLE provider_versions
+DROP COLUMN IF EXISTS attempt_count;`,
+	},
+	{
+		ID:          41,
+		Name:        "add_error_class_to_versions",
+		Description: "Classify why provider and module versions failed to scrape, so upstream problems can be told apart from backend problems",
+		Up: `
+ALTER TABLE provider_versions
+ADD COLUMN IF NOT EXISTS error_class VARCHAR(50);
+
+ALTER TABLE module_versions
+ADD COLUMN IF NOT EXISTS error_class VARCHAR(50);
+
+-- Best effort classification of the failures recorded before the column existed
+UPDATE provider_versions SET error_class = CASE
+    WHEN error_message LIKE '%ref does not exist%' OR error_message LIKE '%reference not found%' THEN 'tag_missing'
+    WHEN error_message LIKE '%failed to checkout version%' THEN 'clone'
+    WHEN error_message LIKE '%failed to detect licenses%' THEN 'license_detection'
+    -- Also covers timeouts and failing submodules or examples, which are retried, so these are left unclassified
+    WHEN error_message LIKE '%failed to collect module data%' THEN 'unknown'
+    WHEN error_message LIKE '%S3%' OR error_message LIKE '%upload%' THEN 'upload'
+    WHEN error_message LIKE '%transaction%' OR error_message LIKE '%failed to store%' THEN 'db'
+    ELSE 'unknown'
+END
+WHERE scrape_status = 'failed';
+
+UPDATE module_versions SET error_class = CASE
+    WHEN error_message LIKE '%ref does not exist%' OR error_message LIKE '%reference not found%' THEN 'tag_missing'
+    WHEN error_message LIKE '%failed to checkout version%' THEN 'clone'
+    WHEN error_message LIKE '%failed to detect licenses%' THEN 'license_detection'
+    -- Also covers timeouts and failing submodules or examples, which are retried, so these are left unclassified
+    WHEN error_message LIKE '%failed to collect module data%' THEN 'unknown'
+    WHEN error_message LIKE '%S3%' OR error_message LIKE '%upload%' THEN 'upload'
+    WHEN error_message LIKE '%transaction%' OR error_message LIKE '%failed to store%' THEN 'db'
+    ELSE 'unknown'
+END
+WHERE scrape_status = 'failed';
+
+COMMENT ON COLUMN provider_versions.error_class IS 'Class of the failure for failed versions: clone, tag_missing, tofu_show, license_detection, upload, db or unknown';
+COMMENT ON COLUMN module_versions.error_class IS 'Class of the failure for failed versions: clone, tag_missing, tofu_show, license_detection, upload, db or unknown';`,
+		Down: `
+ALTER TABLE module_versions
+DROP COLUMN IF EXISTS error_class;
+
+ALTER TABLE provider_versions
+DROP COLUMN IF EXISTS error_class;`,
+	},
+	{
+		ID:          42,
+		Name:        "create_github_response_cache_table",
+		Description: "Create github_response_cache table to revalidate GitHub API responses across runs",
+		Up: `
+CREATE TABLE IF NOT EXISTS github_response_cache (
+    url TEXT PRIMARY KEY,
+    etag TEXT NOT NULL,
+    header JSONB NOT NULL DEFAULT '{}',
+    body BYTEA NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE github_response_cache IS 'Last GitHub REST API response with an ETag for each URL, such as the repository and license of every repository, sent as conditional requests by later runs';`,
+		Down: `
+DROP TABLE IF EXISTS github_response_cache;`,
+	},
+	{
+		ID:          43,
+		Name:        "create_module_used_by_files_table",
+		Description: "Track the used-by.json file uploaded for every module so unchanged files are not uploaded again",
+		Up: `
+CREATE TABLE IF NOT EXISTS module_used_by_files (
+    namespace VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    target VARCHAR(255) NOT NULL,
+    checksum VARCHAR(32) NOT NULL,
+    uploaded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (namespace, name, target),
+    -- A module that is removed and added again gets its file uploaded again
+    FOREIGN KEY (namespace, name, target)
+        REFERENCES modules(namespace, name, target) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE module_used_by_files IS 'used-by.json files uploaded by rebuild-global-indexes, one for every module including the ones without dependents';
+COMMENT ON COLUMN module_used_by_files.checksum IS 'MD5 checksum of the uploaded file used to detect changes between runs';`,
+		Down: `
+DROP TABLE IF EXISTS module_used_by_files;`,
+	},
 }
 
 func NewMigrateCommand() *cli.Command {
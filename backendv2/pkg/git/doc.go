@@ -7,6 +7,7 @@
 // Used for core git operations:
 //   - Repository cloning (EnsureCloned)
 //   - Fetching tags (FetchTags)
+//   - Diffing commits (ChangedFiles)
 //
 // # exec.Command (git CLI)
 //
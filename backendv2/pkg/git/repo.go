@@ -15,6 +15,7 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -142,7 +143,9 @@ func (r *Repo) EnsureCloned(ctx context.Context) error {
 		cloneOptions.Tags = git.NoTags
 	}
 
+	start := time.Now()
 	repository, err := git.PlainCloneContext(ctx, r.LocalPath, false, cloneOptions)
+	telemetry.RecordGitOperation(ctx, "clone", start, err)
 	if err != nil {
 		span.RecordError(err)
 
@@ -204,9 +207,11 @@ func (r *Repo) Update(ctx context.Context) error {
 	slog.DebugContext(ctx, "Updating repository", "url", r.URL, "path", r.LocalPath)
 
 	// Fetch latest from origin
+	start := time.Now()
 	err := r.repository.FetchContext(ctx, &git.FetchOptions{
 		RemoteName: "origin",
 	})
+	telemetry.RecordGitOperation(ctx, "fetch", start, ignoreUpToDate(err))
 	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
 		slog.WarnContext(ctx, "Fetch failed, re-cloning", "url", r.URL, "error", err)
 		return r.recloneAndCheckout(ctx)
@@ -285,12 +290,14 @@ func (r *Repo) FetchTags(ctx context.Context) error {
 
 	slog.DebugContext(ctx, "Fetching all tags from remote", "url", r.URL)
 
+	start := time.Now()
 	err := r.repository.FetchContext(ctx, &git.FetchOptions{
 		RemoteName: "origin",
 		RefSpecs: []config.RefSpec{
 			"+refs/tags/*:refs/tags/*",
 		},
 	})
+	telemetry.RecordGitOperation(ctx, "fetch_tags", start, ignoreUpToDate(err))
 
 	// git.NoErrAlreadyUpToDate is not an error, it just means we already have all tags
 	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
@@ -303,6 +310,14 @@ func (r *Repo) FetchTags(ctx context.Context) error {
 	return nil
 }
 
+// ignoreUpToDate treats a fetch that found nothing new as successful
+func ignoreUpToDate(err error) error {
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
 func (r *Repo) AddWorktree(ctx context.Context, ref, path string) error {
 	ctx, span := telemetry.Tracer().Start(ctx, "git.add_worktree")
 	defer span.End()
@@ -330,7 +345,7 @@ func (r *Repo) AddWorktree(ctx context.Context, ref, path string) error {
 	if _, err := r.repository.ResolveRevision(plumbing.Revision(ref)); err != nil {
 		span.RecordError(err)
 		slog.ErrorContext(ctx, "Ref does not exist in repository", "url", r.URL, "ref", ref, "error", err)
-		return fmt.Errorf("ref %s does not exist: %w", ref, err)
+		return fmt.Errorf("%w: %s: %w", ErrRefNotFound, ref, err)
 	}
 
 	if existingPathRaw, exists := r.worktrees.Load(ref); exists {
@@ -462,3 +477,94 @@ func (r *Repo) GetTagDate(ctx context.Context, tag string) (*time.Time, error) {
 	slog.DebugContext(ctx, "Successfully retrieved tag commit date", "url", r.URL, "tag", tag, "date", tagDate)
 	return &tagDate, nil
 }
+
+// ErrRefNotFound is returned by AddWorktree when the ref, usually a version tag, does not exist in the repository
+var ErrRefNotFound = errors.New("ref does not exist")
+
+// ErrCommitNotFound is returned by ChangedFiles when a commit is not present in the local clone, which happens when a
+// shallow clone was re-created after the commit was recorded
+var ErrCommitNotFound = errors.New("commit not found in local clone")
+
+// Head returns the hash of the commit currently checked out
+func (r *Repo) Head(ctx context.Context) (string, error) {
+	_, span := telemetry.Tracer().Start(ctx, "git.head")
+	defer span.End()
+
+	if err := r.requireCloned(ctx, span, "resolve HEAD"); err != nil {
+		return "", err
+	}
+
+	ref, err := r.repository.Head()
+	if err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return ref.Hash().String(), nil
+}
+
+// ChangedFiles returns the paths of the files that were added, modified or deleted between two commits
+func (r *Repo) ChangedFiles(ctx context.Context, from, to string) ([]string, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "git.changed_files")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("git.url", r.URL),
+		attribute.String("git.from", from),
+		attribute.String("git.to", to),
+	)
+
+	if err := r.requireCloned(ctx, span, "diff commits"); err != nil {
+		return nil, err
+	}
+
+	fromTree, err := r.commitTree(from)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	toTree, err := r.commitTree(to)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	changes, err := object.DiffTreeContext(ctx, fromTree, toTree)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to diff %s..%s: %w", from, to, err)
+	}
+
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		// Renames show up with both names, the file disappeared from one path and appeared at the other
+		if change.From.Name != "" {
+			files = append(files, change.From.Name)
+		}
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			files = append(files, change.To.Name)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("git.changed_files", len(files)))
+	return files, nil
+}
+
+// commitTree returns the root tree of a commit, or ErrCommitNotFound if the commit is not in the local clone
+func (r *Repo) commitTree(hash string) (*object.Tree, error) {
+	commit, err := r.repository.CommitObject(plumbing.NewHash(hash))
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrCommitNotFound, hash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+
+	tree, err := commit.Tree()
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: tree of %s", ErrCommitNotFound, hash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of commit %s: %w", hash, err)
+	}
+	return tree, nil
+}
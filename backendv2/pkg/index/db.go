@@ -11,7 +11,7 @@ import (
 // queryLatestRepositoryStats retrieves the latest repository statistics that we know about from the database
 func queryLatestRepositoryStats(ctx context.Context, db *pgxpool.Pool, org, name string) (*RepositoryStats, error) {
 	query := `
-		SELECT stars, forks, watchers, subscribers, topics
+		SELECT stars, COALESCE(forks, 0), watchers, subscribers, topics
 		FROM repository_stats
 		WHERE repo_organisation = $1 AND repo_name = $2
 		ORDER BY recorded_at DESC
@@ -70,6 +70,7 @@ func queryModuleVersions(ctx context.Context, db *pgxpool.Pool, namespace, name,
 		SELECT version, discovered_at
 		FROM module_versions
 		WHERE module_namespace = $1 AND module_name = $2 AND module_target = $3
+			AND scrape_status <> 'removed'
 		ORDER BY safe_to_semver(version) DESC`
 
 	rows, err := db.Query(ctx, query, namespace, name, target)
@@ -97,6 +98,7 @@ func queryProviderVersions(ctx context.Context, db *pgxpool.Pool, namespace, nam
 		SELECT version, discovered_at
 		FROM provider_versions
 		WHERE provider_namespace = $1 AND provider_name = $2
+			AND scrape_status <> 'removed'
 		ORDER BY safe_to_semver(version) DESC`
 
 	rows, err := db.Query(ctx, query, namespace, name)
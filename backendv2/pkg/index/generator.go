@@ -7,17 +7,20 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/opentofu/registry-ui/pkg/blocklist"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 // GenerateModuleVersionIndex creates a complete module version index from database data
-func GenerateModuleVersionIndex(ctx context.Context, db *pgxpool.Pool, namespace, name, target string) (*ModuleVersionIndex, error) {
-	repoOrg := namespace
-	repoName := name
-
+func GenerateModuleVersionIndex(ctx context.Context, db *pgxpool.Pool, host vcs.Host, namespace, name, target string) (*ModuleVersionIndex, error) {
 	ctx, span := telemetry.Tracer().Start(ctx, "index.generate_module_version")
 	defer span.End()
 
+	// Stats and metadata are stored under the module repository (terraform-<target>-<name>) by the module indexer
+	sourceRepo := vcs.ModuleRepo(namespace, name, target)
+	repoOrg, repoName := sourceRepo.Owner, sourceRepo.Name
+
 	// Query repository stats
 	stats, err := queryLatestRepositoryStats(ctx, db, repoOrg, repoName)
 	if err != nil {
@@ -30,12 +33,24 @@ func GenerateModuleVersionIndex(ctx context.Context, db *pgxpool.Pool, namespace
 		return nil, fmt.Errorf("failed to query repository metadata: %w", err)
 	}
 
+	// Query the stats history for history.json and the recent growth
+	history, deltas, err := loadRepositoryHistory(ctx, db, repoOrg, repoName, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository stats history: %w", err)
+	}
+
 	// Query all module versions
 	versions, err := queryModuleVersions(ctx, db, namespace, name, target)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query module versions: %w", err)
 	}
 
+	// Query blocklist status
+	blocked, blockedReason, err := blocklist.IsModuleBlocked(ctx, db, namespace, name, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blocklist: %w", err)
+	}
+
 	// Build index structure
 	description := ""
 	if repo.Description != nil {
@@ -51,11 +66,17 @@ func GenerateModuleVersionIndex(ctx context.Context, db *pgxpool.Pool, namespace
 		},
 		Description:        description,
 		Versions:           versions,
-		IsBlocked:          false,
+		IsBlocked:          blocked,
 		Popularity:         stats.Stars,
 		ForkCount:          stats.Forks,
 		UpstreamPopularity: 0, // Will be set below if this is a fork
 		UpstreamForkCount:  0, // Will be set below if this is a fork
+		RepositoryDeltas:   deltas,
+		history:            history,
+	}
+
+	if blocked {
+		index.BlockedReason = &blockedReason
 	}
 
 	//  Add fork information if applicable
@@ -68,9 +89,9 @@ func GenerateModuleVersionIndex(ctx context.Context, db *pgxpool.Pool, namespace
 			Target:    target,
 		}
 
-		// Set fork_of_link to GitHub URL
-		githubURL := fmt.Sprintf("https://github.com/%s/%s", *repo.ParentOrganisation, *repo.ParentName)
-		index.ForkOfLink = &githubURL
+		// Set fork_of_link to the parent repository on the configured host
+		forkOfLink := host.RepoURL(vcs.Repo{Owner: *repo.ParentOrganisation, Name: *repo.ParentName})
+		index.ForkOfLink = &forkOfLink
 
 		// Query upstream repository stats
 		upstreamStats, err := queryLatestRepositoryStats(ctx, db, *repo.ParentOrganisation, *repo.ParentName)
@@ -85,7 +106,7 @@ func GenerateModuleVersionIndex(ctx context.Context, db *pgxpool.Pool, namespace
 }
 
 // GenerateProviderVersionIndex creates a complete provider version index from database data
-func GenerateProviderVersionIndex(ctx context.Context, db *pgxpool.Pool, namespace, name string) (*ProviderVersionIndex, error) {
+func GenerateProviderVersionIndex(ctx context.Context, db *pgxpool.Pool, host vcs.Host, namespace, name string) (*ProviderVersionIndex, error) {
 	ctx, span := telemetry.Tracer().Start(ctx, "index.generate_provider_version")
 	defer span.End()
 
@@ -104,6 +125,12 @@ func GenerateProviderVersionIndex(ctx context.Context, db *pgxpool.Pool, namespa
 		return nil, fmt.Errorf("failed to query repository metadata: %w", err)
 	}
 
+	// Query the stats history for history.json and the recent growth
+	history, deltas, err := loadRepositoryHistory(ctx, db, repoOrg, repoName, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository stats history: %w", err)
+	}
+
 	// Query all provider versions
 	versions, err := queryProviderVersions(ctx, db, namespace, name)
 	if err != nil {
@@ -116,6 +143,12 @@ func GenerateProviderVersionIndex(ctx context.Context, db *pgxpool.Pool, namespa
 		return nil, fmt.Errorf("failed to query provider warnings: %w", err)
 	}
 
+	// Query blocklist status
+	blocked, blockedReason, err := blocklist.IsProviderBlocked(ctx, db, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blocklist: %w", err)
+	}
+
 	// Build index structure
 	description := ""
 	if repo.Description != nil {
@@ -131,11 +164,17 @@ func GenerateProviderVersionIndex(ctx context.Context, db *pgxpool.Pool, namespa
 		Description:        description,
 		Versions:           versions,
 		Warnings:           warnings,
-		IsBlocked:          false,
+		IsBlocked:          blocked,
 		Popularity:         stats.Stars,
 		ForkCount:          stats.Forks,
 		UpstreamPopularity: 0, // Will be set below if this is a fork
 		UpstreamForkCount:  0, // Will be set below if this is a fork
+		RepositoryDeltas:   deltas,
+		history:            history,
+	}
+
+	if blocked {
+		index.BlockedReason = &blockedReason
 	}
 
 	// Add fork information if applicable
@@ -152,9 +191,9 @@ func GenerateProviderVersionIndex(ctx context.Context, db *pgxpool.Pool, namespa
 			Name:      parentProviderName,
 		}
 
-		// Set fork_of_link to GitHub URL
-		githubURL := fmt.Sprintf("https://github.com/%s/%s", *repo.ParentOrganisation, *repo.ParentName)
-		index.ForkOfLink = &githubURL
+		// Set fork_of_link to the parent repository on the configured host
+		forkOfLink := host.RepoURL(vcs.Repo{Owner: *repo.ParentOrganisation, Name: *repo.ParentName})
+		index.ForkOfLink = &forkOfLink
 
 		// Query upstream repository stats
 		upstreamStats, err := queryLatestRepositoryStats(ctx, db, *repo.ParentOrganisation, *repo.ParentName)
@@ -189,6 +228,7 @@ func RebuildGlobalModuleIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalMod
 				array_agg(version ORDER BY safe_to_semver(version) DESC) as versions,
 				array_agg(discovered_at ORDER BY safe_to_semver(version) DESC) as discovered_dates
 			FROM module_versions
+			WHERE scrape_status <> 'removed'
 			GROUP BY module_namespace, module_name, module_target
 		)
 		SELECT
@@ -207,7 +247,9 @@ func RebuildGlobalModuleIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalMod
 			r.parent_name,
 
 			COALESCE(us.stars, 0) as upstream_stars,
-			COALESCE(us.forks, 0) as upstream_forks
+			COALESCE(us.forks, 0) as upstream_forks,
+
+			bl.reason as blocked_reason
 		FROM module_versions_agg mv
 		-- repositories.name stores the full GitHub repo name (e.g. "terraform-aws-vpc"),
 		-- while module_versions stores the short name (e.g. "vpc") and target (e.g. "aws") separately
@@ -220,6 +262,17 @@ func RebuildGlobalModuleIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalMod
 		LEFT JOIN latest_stats us 
 			ON us.repo_organisation = r.parent_organisation
 			AND us.repo_name = r.parent_name
+		-- An exact address entry takes precedence over a namespace-wide entry
+		LEFT JOIN LATERAL (
+			SELECT b.reason
+			FROM blocklist b
+			WHERE b.entity_type = 'module'
+				AND b.namespace = lower(mv.module_namespace)
+				AND ((b.name = lower(mv.module_name) AND b.target = lower(mv.module_target))
+					OR (b.name = '' AND b.target = ''))
+			ORDER BY b.name DESC
+			LIMIT 1
+		) bl ON true
 		ORDER BY mv.module_namespace, mv.module_name, mv.module_target`
 
 	rows, err := db.Query(ctx, query)
@@ -244,6 +297,7 @@ func RebuildGlobalModuleIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalMod
 			parentName         *string
 			upstreamStars      int
 			upstreamForks      int
+			blockedReason      *string
 		)
 
 		if err := rows.Scan(
@@ -260,6 +314,7 @@ func RebuildGlobalModuleIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalMod
 			&parentName,
 			&upstreamStars,
 			&upstreamForks,
+			&blockedReason,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan module row: %w", err)
 		}
@@ -290,6 +345,8 @@ func RebuildGlobalModuleIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalMod
 			Description:   desc,
 			LatestVersion: versions[0],
 			PublishedAt:   publishedAt,
+			IsBlocked:     blockedReason != nil,
+			BlockedReason: blockedReason,
 		}
 
 		modules = append(modules, entry)
@@ -303,7 +360,7 @@ func RebuildGlobalModuleIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalMod
 }
 
 // RebuildGlobalProviderIndex rebuilds the entire global provider index from the database
-func RebuildGlobalProviderIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalProviderIndex, error) {
+func RebuildGlobalProviderIndex(ctx context.Context, db *pgxpool.Pool, host vcs.Host) (*GlobalProviderIndex, error) {
 	ctx, span := telemetry.Tracer().Start(ctx, "index.rebuild_global_providers")
 	defer span.End()
 
@@ -322,6 +379,7 @@ func RebuildGlobalProviderIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalP
 				array_agg(version ORDER BY safe_to_semver(version) DESC) as versions,
 				array_agg(discovered_at ORDER BY safe_to_semver(version) DESC) as discovered_dates
 			FROM provider_versions
+			WHERE scrape_status <> 'removed'
 			GROUP BY provider_namespace, provider_name
 		)
 		SELECT
@@ -340,7 +398,9 @@ func RebuildGlobalProviderIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalP
 			pr.warnings,
 
 			COALESCE(us.stars, 0) as upstream_stars,
-			COALESCE(us.forks, 0) as upstream_forks
+			COALESCE(us.forks, 0) as upstream_forks,
+
+			bl.reason as blocked_reason
 		FROM provider_versions_agg pv
 		-- repositories.name stores the full GitHub repo name (e.g. "terraform-provider-aws"),
 		-- while provider_versions.provider_name stores the short name (e.g. "aws")
@@ -352,6 +412,16 @@ func RebuildGlobalProviderIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalP
 			AND pr.name = pv.provider_name
 		LEFT JOIN latest_stats us ON us.repo_organisation = r.parent_organisation
 			AND us.repo_name = r.parent_name
+		-- An exact address entry takes precedence over a namespace-wide entry
+		LEFT JOIN LATERAL (
+			SELECT b.reason
+			FROM blocklist b
+			WHERE b.entity_type = 'provider'
+				AND b.namespace = lower(pv.provider_namespace)
+				AND (b.name = lower(pv.provider_name) OR b.name = '')
+			ORDER BY b.name DESC
+			LIMIT 1
+		) bl ON true
 		ORDER BY pv.provider_namespace, pv.provider_name`
 
 	rows, err := db.Query(ctx, query)
@@ -376,6 +446,7 @@ func RebuildGlobalProviderIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalP
 			warnings           []string
 			upstreamStars      int
 			upstreamForks      int
+			blockedReason      *string
 		)
 
 		if err := rows.Scan(
@@ -392,6 +463,7 @@ func RebuildGlobalProviderIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalP
 			&warnings,
 			&upstreamStars,
 			&upstreamForks,
+			&blockedReason,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan provider row: %w", err)
 		}
@@ -422,8 +494,8 @@ func RebuildGlobalProviderIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalP
 			publishedAt = discoveredDates[0]
 		}
 
-		// Generate GitHub repository URL
-		repoURL := fmt.Sprintf("https://github.com/%s/terraform-provider-%s", namespace, name)
+		// Generate the repository URL on the configured host
+		repoURL := host.RepoURL(vcs.ProviderRepo(namespace, name))
 
 		entry := ProviderEntry{
 			Addr: ProviderAddr{
@@ -438,6 +510,8 @@ func RebuildGlobalProviderIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalP
 			Warnings:      warnings,
 			Popularity:    stars,
 			ForkCount:     forks,
+			IsBlocked:     blockedReason != nil,
+			BlockedReason: blockedReason,
 		}
 
 		// Add fork information if applicable
@@ -453,8 +527,8 @@ func RebuildGlobalProviderIndex(ctx context.Context, db *pgxpool.Pool) (*GlobalP
 				Name:      parentProviderName,
 			}
 
-			githubURL := fmt.Sprintf("https://github.com/%s/%s", *parentOrganisation, *parentName)
-			entry.ForkOfLink = &githubURL
+			forkOfLink := host.RepoURL(vcs.Repo{Owner: *parentOrganisation, Name: *parentName})
+			entry.ForkOfLink = &forkOfLink
 		}
 
 		providers = append(providers, entry)
@@ -0,0 +1,187 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// dailyHistory is how far back the history has one point per day, older points are reduced to one per week
+const dailyHistory = 90 * 24 * time.Hour
+
+// RepositoryHistory is the star and fork history of the repository of a provider or module, published as
+// history.json next to its index.json
+type RepositoryHistory struct {
+	Repository string `json:"repository"`
+	// Points are in chronological order: one per week (dated the Monday of the week) until 90 days ago, one per day
+	// after that. Each point holds the last values recorded in its period.
+	Points []HistoryPoint `json:"points"`
+}
+
+// HistoryPoint is the stars and forks of a repository on a date. Forks is null for points recorded before forks were
+// tracked.
+type HistoryPoint struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Stars int    `json:"stars"`
+	Forks *int   `json:"forks"`
+}
+
+// statsPoint is a single row of repository_stats
+type statsPoint struct {
+	RecordedAt time.Time
+	Stars      int
+	Forks      int
+	// OnlyStars is set when every stat but the stars is zero, like in the rows copied from repositories when the
+	// table was created
+	OnlyStars bool
+	// ForksUnknown is set for the rows copied from repositories when the table was created, which only had stars
+	ForksUnknown bool
+}
+
+// RepositoryDeltas is the change in stars and forks over the last 7 and 30 days. A delta is nil when there is no
+// datapoint from before the start of its window.
+type RepositoryDeltas struct {
+	StarsDelta7d  *int `json:"popularity_delta_7d,omitempty"`
+	StarsDelta30d *int `json:"popularity_delta_30d,omitempty"`
+	ForksDelta7d  *int `json:"fork_count_delta_7d,omitempty"`
+	ForksDelta30d *int `json:"fork_count_delta_30d,omitempty"`
+}
+
+// queryRepositoryStatsHistory retrieves all recorded stats of a repository in chronological order
+func queryRepositoryStatsHistory(ctx context.Context, db *pgxpool.Pool, org, name string) ([]statsPoint, error) {
+	rows, err := db.Query(ctx, `
+		SELECT recorded_at, COALESCE(stars, 0), COALESCE(forks, 0),
+		       COALESCE(forks, 0) = 0 AND COALESCE(watchers, 0) = 0 AND COALESCE(open_issues, 0) = 0
+		           AND COALESCE(subscribers, 0) = 0 AND COALESCE(topics, '{}') = '{}'
+		FROM repository_stats
+		WHERE repo_organisation = $1 AND repo_name = $2
+		ORDER BY recorded_at`, org, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository stats history: %w", err)
+	}
+	defer rows.Close()
+
+	var points []statsPoint
+	for rows.Next() {
+		var p statsPoint
+		if err := rows.Scan(&p.RecordedAt, &p.Stars, &p.Forks, &p.OnlyStars); err != nil {
+			return nil, fmt.Errorf("failed to scan repository stats: %w", err)
+		}
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	markMigratedForks(points)
+	return points, nil
+}
+
+// markMigratedForks marks the forks of the first point as unknown when it only has stars. Migration 12 copied the
+// stars of every repository into repository_stats, so that row always comes first. Stats that were actually synced
+// without forks or any other activity are left out of the fork history and deltas as well, which only loses a zero.
+func markMigratedForks(points []statsPoint) {
+	if len(points) > 0 && points[0].OnlyStars {
+		points[0].ForksUnknown = true
+	}
+}
+
+// loadRepositoryHistory builds the downsampled history and the deltas of a repository
+func loadRepositoryHistory(ctx context.Context, db *pgxpool.Pool, org, name string, now time.Time) (*RepositoryHistory, RepositoryDeltas, error) {
+	points, err := queryRepositoryStatsHistory(ctx, db, org, name)
+	if err != nil {
+		return nil, RepositoryDeltas{}, err
+	}
+
+	history, deltas := newRepositoryHistory(org, name, points, now)
+	return history, deltas, nil
+}
+
+// newRepositoryHistory builds the downsampled history and the deltas from the recorded stats of a repository
+func newRepositoryHistory(org, name string, points []statsPoint, now time.Time) (*RepositoryHistory, RepositoryDeltas) {
+	history := &RepositoryHistory{
+		Repository: org + "/" + name,
+		Points:     downsample(points, now),
+	}
+	return history, computeDeltas(points, now)
+}
+
+// downsample keeps the last point of each day within dailyHistory of now and the last point of each week before
+// that. points must be in chronological order.
+func downsample(points []statsPoint, now time.Time) []HistoryPoint {
+	dailyFrom := truncateDay(now.Add(-dailyHistory))
+
+	result := []HistoryPoint{}
+	for _, p := range points {
+		recorded := p.RecordedAt.UTC()
+		period := truncateDay(recorded)
+		if period.Before(dailyFrom) {
+			period = truncateWeek(recorded)
+		}
+		point := HistoryPoint{Date: period.Format(time.DateOnly), Stars: p.Stars}
+		if !p.ForksUnknown {
+			point.Forks = &p.Forks
+		}
+
+		// Later points of the same period replace earlier ones
+		if n := len(result); n > 0 && result[n-1].Date == point.Date {
+			result[n-1] = point
+			continue
+		}
+		result = append(result, point)
+	}
+	return result
+}
+
+// computeDeltas compares the latest point with the last point recorded at or before the start of each window.
+// Points with unknown forks are left out of the fork deltas. points must be in chronological order.
+func computeDeltas(points []statsPoint, now time.Time) RepositoryDeltas {
+	stars := func(p statsPoint) (int, bool) { return p.Stars, true }
+	forks := func(p statsPoint) (int, bool) { return p.Forks, !p.ForksUnknown }
+
+	var deltas RepositoryDeltas
+	deltas.StarsDelta7d = delta(points, now.Add(-7*24*time.Hour), stars)
+	deltas.StarsDelta30d = delta(points, now.Add(-30*24*time.Hour), stars)
+	deltas.ForksDelta7d = delta(points, now.Add(-7*24*time.Hour), forks)
+	deltas.ForksDelta30d = delta(points, now.Add(-30*24*time.Hour), forks)
+	return deltas
+}
+
+// delta returns the difference between the latest value and the last value recorded at or before start, skipping
+// points without a value, or nil when there is no such pair
+func delta(points []statsPoint, start time.Time, value func(statsPoint) (int, bool)) *int {
+	latest, baseline := -1, -1
+	for i := len(points) - 1; i >= 0; i-- {
+		if _, ok := value(points[i]); !ok {
+			continue
+		}
+		if latest < 0 {
+			latest = i
+		}
+		if !points[i].RecordedAt.After(start) {
+			baseline = i
+			break
+		}
+	}
+	if latest < 0 || baseline < 0 {
+		return nil
+	}
+
+	to, _ := value(points[latest])
+	from, _ := value(points[baseline])
+	d := to - from
+	return &d
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// truncateWeek returns the Monday of the week of t
+func truncateWeek(t time.Time) time.Time {
+	day := truncateDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,157 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentofu/registry-ui/pkg/vcs"
+)
+
+func TestDownsample(t *testing.T) {
+	// Friday
+	now := time.Date(2026, 6, 5, 12, 0, 0, 0, time.UTC)
+	at := func(daysAgo, hour int) time.Time {
+		return time.Date(2026, 6, 5-daysAgo, hour, 0, 0, 0, time.UTC)
+	}
+
+	points := []statsPoint{
+		// Monday 2026-02-23 and Wednesday 2026-02-25 fall in the same week before the daily window
+		{RecordedAt: at(102, 8), Stars: 1, Forks: 0},
+		{RecordedAt: at(100, 8), Stars: 2, Forks: 1},
+		// The next week
+		{RecordedAt: at(95, 8), Stars: 3, Forks: 1},
+		// Two points on the same day within the daily window
+		{RecordedAt: at(1, 8), Stars: 10, Forks: 2},
+		{RecordedAt: at(1, 20), Stars: 11, Forks: 2},
+		{RecordedAt: at(0, 8), Stars: 12, Forks: 3},
+	}
+
+	want := []HistoryPoint{
+		{Date: "2026-02-23", Stars: 2, Forks: intPtr(1)},
+		{Date: "2026-03-02", Stars: 3, Forks: intPtr(1)},
+		{Date: "2026-06-04", Stars: 11, Forks: intPtr(2)},
+		{Date: "2026-06-05", Stars: 12, Forks: intPtr(3)},
+	}
+
+	if got := downsample(points, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("downsample() = %+v, want %+v", got, want)
+	}
+	if got := downsample(nil, now); got == nil || len(got) != 0 {
+		t.Errorf("downsample(nil) = %#v, want an empty slice", got)
+	}
+
+	// Points from before forks were tracked have no forks
+	migrated := []statsPoint{{RecordedAt: at(200, 8), Stars: 4, ForksUnknown: true}}
+	if got, want := downsample(migrated, now), []HistoryPoint{{Date: "2025-11-17", Stars: 4}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("downsample(migrated) = %+v, want %+v", got, want)
+	}
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestComputeDeltas(t *testing.T) {
+	now := time.Date(2026, 6, 5, 12, 0, 0, 0, time.UTC)
+	ago := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	tests := []struct {
+		name   string
+		points []statsPoint
+		want   RepositoryDeltas
+	}{
+		{"no history", nil, RepositoryDeltas{}},
+		{
+			"shorter than a week",
+			[]statsPoint{{RecordedAt: ago(3), Stars: 5}, {RecordedAt: ago(0), Stars: 8}},
+			RepositoryDeltas{},
+		},
+		{
+			"between a week and a month",
+			[]statsPoint{{RecordedAt: ago(10), Stars: 5, Forks: 1}, {RecordedAt: ago(7), Stars: 6, Forks: 1}, {RecordedAt: ago(0), Stars: 9, Forks: 2}},
+			RepositoryDeltas{StarsDelta7d: intPtr(3), ForksDelta7d: intPtr(1)},
+		},
+		{
+			"longer than a month",
+			[]statsPoint{{RecordedAt: ago(40), Stars: 1}, {RecordedAt: ago(8), Stars: 20}, {RecordedAt: ago(1), Stars: 18}},
+			RepositoryDeltas{StarsDelta7d: intPtr(-2), StarsDelta30d: intPtr(17), ForksDelta7d: intPtr(0), ForksDelta30d: intPtr(0)},
+		},
+		{
+			"migrated stats without forks",
+			[]statsPoint{{RecordedAt: ago(40), Stars: 1, ForksUnknown: true}, {RecordedAt: ago(8), Stars: 20, Forks: 5}, {RecordedAt: ago(1), Stars: 18, Forks: 6}},
+			RepositoryDeltas{StarsDelta7d: intPtr(-2), StarsDelta30d: intPtr(17), ForksDelta7d: intPtr(1)},
+		},
+		{
+			"only migrated stats",
+			[]statsPoint{{RecordedAt: ago(40), Stars: 1, ForksUnknown: true}},
+			RepositoryDeltas{StarsDelta7d: intPtr(0), StarsDelta30d: intPtr(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeDeltas(tt.points, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("computeDeltas() = %s, want %s", formatDeltas(got), formatDeltas(tt.want))
+			}
+		})
+	}
+}
+
+func formatDeltas(d RepositoryDeltas) string {
+	f := func(p *int) any {
+		if p == nil {
+			return "nil"
+		}
+		return *p
+	}
+	return fmt.Sprint("stars 7d=", f(d.StarsDelta7d), " 30d=", f(d.StarsDelta30d), " forks 7d=", f(d.ForksDelta7d), " 30d=", f(d.ForksDelta30d))
+}
+
+func TestNewRepositoryHistoryOfModule(t *testing.T) {
+	now := time.Date(2026, 6, 5, 12, 0, 0, 0, time.UTC)
+	points := []statsPoint{
+		{RecordedAt: now.AddDate(0, 0, -8), Stars: 6000, Forks: 4000},
+		{RecordedAt: now.AddDate(0, 0, -1), Stars: 6010, Forks: 4003},
+	}
+
+	// The module indexer records the stats of terraform-aws-modules/vpc/aws under its repository
+	repo := vcs.ModuleRepo("terraform-aws-modules", "vpc", "aws")
+	history, deltas := newRepositoryHistory(repo.Owner, repo.Name, points, now)
+
+	if want := "terraform-aws-modules/terraform-aws-vpc"; history.Repository != want {
+		t.Errorf("Repository = %q, want %q", history.Repository, want)
+	}
+	if len(history.Points) != 2 {
+		t.Errorf("Points = %+v, want 2 points", history.Points)
+	}
+	if want := (RepositoryDeltas{StarsDelta7d: intPtr(10), ForksDelta7d: intPtr(3)}); !reflect.DeepEqual(deltas, want) {
+		t.Errorf("deltas = %s, want %s", formatDeltas(deltas), formatDeltas(want))
+	}
+}
+
+func TestMarkMigratedForks(t *testing.T) {
+	at := time.Date(2026, 6, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		points []statsPoint
+		want   []bool
+	}{
+		{"no stats", nil, nil},
+		{"copied stars first", []statsPoint{{RecordedAt: at, Stars: 5, OnlyStars: true}, {RecordedAt: at.Add(time.Hour), Stars: 6, Forks: 1}}, []bool{true, false}},
+		{"synced stats first", []statsPoint{{RecordedAt: at, Stars: 5, Forks: 2}, {RecordedAt: at.Add(time.Hour), Stars: 6, OnlyStars: true}}, []bool{false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markMigratedForks(tt.points)
+			var got []bool
+			for _, p := range tt.points {
+				got = append(got, p.ForksUnknown)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForksUnknown = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,22 +1,19 @@
 package index
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/opentofu/registry-ui/pkg/bucket"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
 )
 
-// UploadModuleVersionIndex uploads a module version index to S3
-func UploadModuleVersionIndex(ctx context.Context, uploader *manager.Uploader, bucketName string, index *ModuleVersionIndex) error {
-	key := fmt.Sprintf("modules/%s/%s/%s/index.json",
+// UploadModuleVersionIndex uploads a module version index and the repository history to the bucket
+func UploadModuleVersionIndex(ctx context.Context, store bucket.Store, index *ModuleVersionIndex) error {
+	prefix := fmt.Sprintf("modules/%s/%s/%s/",
 		index.Addr.Namespace, index.Addr.Name, index.Addr.Target)
 
 	jsonData, err := json.MarshalIndent(index, "", "  ")
@@ -24,12 +21,15 @@ func UploadModuleVersionIndex(ctx context.Context, uploader *manager.Uploader, b
 		return fmt.Errorf("failed to marshal module index: %w", err)
 	}
 
-	return uploadToS3(ctx, uploader, bucketName, key, jsonData, "application/json")
+	if err := upload(ctx, store, prefix+"index.json", jsonData, "application/json"); err != nil {
+		return err
+	}
+	return uploadHistory(ctx, store, prefix+"history.json", index.history)
 }
 
-// UploadProviderVersionIndex uploads a provider version index to S3
-func UploadProviderVersionIndex(ctx context.Context, uploader *manager.Uploader, bucketName string, index *ProviderVersionIndex) error {
-	key := fmt.Sprintf("providers/%s/%s/index.json",
+// UploadProviderVersionIndex uploads a provider version index and the repository history to the bucket
+func UploadProviderVersionIndex(ctx context.Context, store bucket.Store, index *ProviderVersionIndex) error {
+	prefix := fmt.Sprintf("providers/%s/%s/",
 		index.Addr.Namespace, index.Addr.Name)
 
 	jsonData, err := json.MarshalIndent(index, "", "  ")
@@ -37,49 +37,58 @@ func UploadProviderVersionIndex(ctx context.Context, uploader *manager.Uploader,
 		return fmt.Errorf("failed to marshal provider index: %w", err)
 	}
 
-	return uploadToS3(ctx, uploader, bucketName, key, jsonData, "application/json")
+	if err := upload(ctx, store, prefix+"index.json", jsonData, "application/json"); err != nil {
+		return err
+	}
+	return uploadHistory(ctx, store, prefix+"history.json", index.history)
+}
+
+// uploadHistory uploads the repository history, indexes that weren't generated from the database have none
+func uploadHistory(ctx context.Context, store bucket.Store, key string, history *RepositoryHistory) error {
+	if history == nil {
+		return nil
+	}
+
+	// The history is only charted, so skip indentation to keep it compact
+	jsonData, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal repository history: %w", err)
+	}
+
+	return upload(ctx, store, key, jsonData, "application/json")
 }
 
-// uploadGlobalModuleIndex uploads the global module index to S3
-func uploadGlobalModuleIndex(ctx context.Context, uploader *manager.Uploader, bucketName, key string, globalIndex *GlobalModuleIndex) error {
+// uploadGlobalModuleIndex uploads the global module index to the bucket
+func uploadGlobalModuleIndex(ctx context.Context, store bucket.Store, key string, globalIndex *GlobalModuleIndex) error {
 	jsonData, err := json.MarshalIndent(globalIndex, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal global module index: %w", err)
 	}
 
-	return uploadToS3(ctx, uploader, bucketName, key, jsonData, "application/json")
+	return upload(ctx, store, key, jsonData, "application/json")
 }
 
-// uploadGlobalProviderIndex uploads the global provider index to S3
-func uploadGlobalProviderIndex(ctx context.Context, uploader *manager.Uploader, bucketName, key string, globalIndex *GlobalProviderIndex) error {
+// uploadGlobalProviderIndex uploads the global provider index to the bucket
+func uploadGlobalProviderIndex(ctx context.Context, store bucket.Store, key string, globalIndex *GlobalProviderIndex) error {
 	jsonData, err := json.MarshalIndent(globalIndex, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal global provider index: %w", err)
 	}
 
-	return uploadToS3(ctx, uploader, bucketName, key, jsonData, "application/json")
+	return upload(ctx, store, key, jsonData, "application/json")
 }
 
-// uploadToS3 uploads data to S3 with the specified content type
-func uploadToS3(ctx context.Context, uploader *manager.Uploader, bucketName, key string, data []byte, contentType string) error {
-	// TODO: find a centralized way to upload files with decent OTEL in there, for now we'll just keep this method in this package
-	ctx, span := telemetry.Tracer().Start(ctx, "index.upload_to_s3")
+// upload stores data in the bucket with the specified content type
+func upload(ctx context.Context, store bucket.Store, key string, data []byte, contentType string) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "index.upload")
 	defer span.End()
 
-	toUpload := &s3.PutObjectInput{
-		Bucket:      aws.String(bucketName),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(data),
-		ContentType: aws.String(contentType),
-	}
-
-	span.SetAttributes(attribute.String("bucket", bucketName),
-		attribute.String("key", key),
+	span.SetAttributes(attribute.String("key", key),
 		attribute.String("content-type", contentType),
 		attribute.Int64("size", int64(len(data))),
 	)
 
-	_, err := uploader.Upload(ctx, toUpload)
+	err := store.Put(ctx, bucket.Object{Key: key, Body: data, ContentType: contentType})
 	if err != nil {
 		span.RecordError(err)
 		return err
@@ -88,11 +97,31 @@ func uploadToS3(ctx context.Context, uploader *manager.Uploader, bucketName, key
 }
 
 // UploadGlobalModuleIndex is an exported wrapper for uploadGlobalModuleIndex
-func UploadGlobalModuleIndex(ctx context.Context, uploader *manager.Uploader, bucketName, key string, globalIndex *GlobalModuleIndex) error {
-	return uploadGlobalModuleIndex(ctx, uploader, bucketName, key, globalIndex)
+func UploadGlobalModuleIndex(ctx context.Context, store bucket.Store, key string, globalIndex *GlobalModuleIndex) error {
+	return uploadGlobalModuleIndex(ctx, store, key, globalIndex)
 }
 
 // UploadGlobalProviderIndex is an exported wrapper for uploadGlobalProviderIndex
-func UploadGlobalProviderIndex(ctx context.Context, uploader *manager.Uploader, bucketName, key string, globalIndex *GlobalProviderIndex) error {
-	return uploadGlobalProviderIndex(ctx, uploader, bucketName, key, globalIndex)
+func UploadGlobalProviderIndex(ctx context.Context, store bucket.Store, key string, globalIndex *GlobalProviderIndex) error {
+	return uploadGlobalProviderIndex(ctx, store, key, globalIndex)
+}
+
+// UploadTrendingProviderIndex uploads the trending provider index to the bucket
+func UploadTrendingProviderIndex(ctx context.Context, store bucket.Store, key string, trending *TrendingProviderIndex) error {
+	jsonData, err := json.MarshalIndent(trending, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trending provider index: %w", err)
+	}
+
+	return upload(ctx, store, key, jsonData, "application/json")
+}
+
+// UploadTrendingModuleIndex uploads the trending module index to the bucket
+func UploadTrendingModuleIndex(ctx context.Context, store bucket.Store, key string, trending *TrendingModuleIndex) error {
+	jsonData, err := json.MarshalIndent(trending, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trending module index: %w", err)
+	}
+
+	return upload(ctx, store, key, jsonData, "application/json")
 }
@@ -0,0 +1,232 @@
+package index
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+const (
+	// trendingLimit is the maximum number of entries in a trending index
+	trendingLimit = 50
+	// trendingReleaseWindow is how far back releases count towards the trending score
+	trendingReleaseWindow = 30 * 24 * time.Hour
+	// maxTrendingReleases caps the release boost so frequent publishers don't outrank actual adoption
+	maxTrendingReleases = 4
+)
+
+// TrendingProviderIndex represents the providers/trending.json file
+type TrendingProviderIndex struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	Providers   []TrendingProvider `json:"providers"`
+}
+
+// TrendingProvider is a provider entry in the trending index, in descending order of score
+type TrendingProvider struct {
+	ProviderEntry
+	TrendingSignals
+}
+
+// TrendingModuleIndex represents the modules/trending.json file
+type TrendingModuleIndex struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Modules     []TrendingModule `json:"modules"`
+}
+
+// TrendingModule is a module entry in the trending index, in descending order of score
+type TrendingModule struct {
+	ModuleEntry
+	TrendingSignals
+}
+
+// TrendingSignals are the inputs and result of the trending score
+type TrendingSignals struct {
+	Score              float64 `json:"trending_score"`
+	PopularityDelta7d  int     `json:"popularity_delta_7d"`
+	PopularityDelta30d int     `json:"popularity_delta_30d"`
+	RecentVersions     int     `json:"recent_versions"` // versions published in the last 30 days
+
+	// stars is the current star count, used to weigh the growth against the size of the repository
+	stars int
+}
+
+// trendingScore scores how quickly a repository is gaining adoption. Star growth is weighed against the size of the
+// repository, so a small project doubling its stars isn't drowned out by the largest ones, with the last week counting
+// more than the rest of the month. Recent releases boost the growth but never make up for a lack of it.
+func trendingScore(s TrendingSignals) float64 {
+	growth7d := float64(max(s.PopularityDelta7d, 0))
+	growth30d := float64(max(s.PopularityDelta30d, 0))
+	if growth30d == 0 && growth7d == 0 {
+		return 0
+	}
+
+	base := max(float64(s.stars)-growth30d, 0)
+	growth := (3*growth7d + growth30d) / math.Log2(base+2)
+	boost := 1 + 0.25*float64(min(s.RecentVersions, maxTrendingReleases))
+
+	// Rounded so the published file doesn't churn on floating point noise
+	return math.Round(growth*boost*100) / 100
+}
+
+// RebuildTrendingProviderIndex ranks the given global index entries by trending score. Blocked providers and providers
+// without recent growth are left out.
+func RebuildTrendingProviderIndex(ctx context.Context, db *pgxpool.Pool, global *GlobalProviderIndex, now time.Time) (*TrendingProviderIndex, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "index.rebuild_trending_providers")
+	defer span.End()
+
+	query := trendingSignalsQuery(
+		`SELECT provider_namespace AS namespace, provider_name AS name, '' AS target, discovered_at
+		FROM provider_versions WHERE scrape_status <> 'removed'`,
+		`'terraform-provider-' || v.name`)
+
+	signals, err := queryTrendingSignals(ctx, db, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query provider trending signals: %w", err)
+	}
+
+	trending := &TrendingProviderIndex{GeneratedAt: now, Providers: []TrendingProvider{}}
+	for _, entry := range global.Providers {
+		s, ok := signals[trendingKey{entry.Addr.Namespace, entry.Addr.Name, ""}]
+		if !ok || entry.IsBlocked {
+			continue
+		}
+		if s.Score = trendingScore(s); s.Score > 0 {
+			trending.Providers = append(trending.Providers, TrendingProvider{ProviderEntry: entry, TrendingSignals: s})
+		}
+	}
+
+	slices.SortStableFunc(trending.Providers, func(a, b TrendingProvider) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	if len(trending.Providers) > trendingLimit {
+		trending.Providers = trending.Providers[:trendingLimit]
+	}
+
+	span.SetAttributes(attribute.Int("providers.count", len(trending.Providers)))
+	return trending, nil
+}
+
+// RebuildTrendingModuleIndex ranks the given global index entries by trending score. Blocked modules and modules
+// without recent growth are left out.
+func RebuildTrendingModuleIndex(ctx context.Context, db *pgxpool.Pool, global *GlobalModuleIndex, now time.Time) (*TrendingModuleIndex, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "index.rebuild_trending_modules")
+	defer span.End()
+
+	query := trendingSignalsQuery(
+		`SELECT module_namespace AS namespace, module_name AS name, module_target AS target, discovered_at
+		FROM module_versions WHERE scrape_status <> 'removed'`,
+		`'terraform-' || v.target || '-' || v.name`)
+
+	signals, err := queryTrendingSignals(ctx, db, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query module trending signals: %w", err)
+	}
+
+	trending := &TrendingModuleIndex{GeneratedAt: now, Modules: []TrendingModule{}}
+	for _, entry := range global.Modules {
+		s, ok := signals[trendingKey{entry.Addr.Namespace, entry.Addr.Name, entry.Addr.Target}]
+		if !ok || entry.IsBlocked {
+			continue
+		}
+		if s.Score = trendingScore(s); s.Score > 0 {
+			trending.Modules = append(trending.Modules, TrendingModule{ModuleEntry: entry, TrendingSignals: s})
+		}
+	}
+
+	slices.SortStableFunc(trending.Modules, func(a, b TrendingModule) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	if len(trending.Modules) > trendingLimit {
+		trending.Modules = trending.Modules[:trendingLimit]
+	}
+
+	span.SetAttributes(attribute.Int("modules.count", len(trending.Modules)))
+	return trending, nil
+}
+
+// trendingKey is the address of a provider or module, target is empty for providers
+type trendingKey struct {
+	namespace string
+	name      string
+	target    string
+}
+
+// trendingSignalsQuery builds the query for the trending signals of every provider or module. versions selects the
+// namespace, name, target and discovered_at of each version, and repoName derives the repository name from them.
+//
+// The star deltas compare the latest stats with the last stats recorded at or before the start of each window, the
+// same way as the deltas in the version indexes. Repositories without stats from before a window have no delta for it.
+func trendingSignalsQuery(versions, repoName string) string {
+	return fmt.Sprintf(`
+		WITH versions AS (%s),
+		latest_stats AS (
+			SELECT DISTINCT ON (repo_organisation, repo_name)
+				repo_organisation, repo_name, COALESCE(stars, 0) AS stars
+			FROM repository_stats
+			ORDER BY repo_organisation, repo_name, recorded_at DESC
+		),
+		stats_7d AS (
+			SELECT DISTINCT ON (repo_organisation, repo_name)
+				repo_organisation, repo_name, COALESCE(stars, 0) AS stars
+			FROM repository_stats
+			WHERE recorded_at <= $1::timestamptz - interval '7 days'
+			ORDER BY repo_organisation, repo_name, recorded_at DESC
+		),
+		stats_30d AS (
+			SELECT DISTINCT ON (repo_organisation, repo_name)
+				repo_organisation, repo_name, COALESCE(stars, 0) AS stars
+			FROM repository_stats
+			WHERE recorded_at <= $1::timestamptz - interval '30 days'
+			ORDER BY repo_organisation, repo_name, recorded_at DESC
+		),
+		addresses AS (
+			SELECT namespace, name, target,
+				count(*) FILTER (WHERE discovered_at > $2) AS recent_versions
+			FROM versions
+			GROUP BY namespace, name, target
+		)
+		SELECT
+			v.namespace,
+			v.name,
+			v.target,
+			v.recent_versions,
+			COALESCE(s.stars, 0) AS stars,
+			COALESCE(s.stars - s7.stars, 0) AS delta_7d,
+			COALESCE(s.stars - s30.stars, 0) AS delta_30d
+		FROM addresses v
+		LEFT JOIN latest_stats s ON s.repo_organisation = v.namespace AND s.repo_name = %[2]s
+		LEFT JOIN stats_7d s7 ON s7.repo_organisation = v.namespace AND s7.repo_name = %[2]s
+		LEFT JOIN stats_30d s30 ON s30.repo_organisation = v.namespace AND s30.repo_name = %[2]s`,
+		versions, repoName)
+}
+
+// queryTrendingSignals runs a query built by trendingSignalsQuery
+func queryTrendingSignals(ctx context.Context, db *pgxpool.Pool, query string, now time.Time) (map[trendingKey]TrendingSignals, error) {
+	rows, err := db.Query(ctx, query, now, now.Add(-trendingReleaseWindow))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	signals := map[trendingKey]TrendingSignals{}
+	for rows.Next() {
+		var (
+			key trendingKey
+			s   TrendingSignals
+		)
+		if err := rows.Scan(&key.namespace, &key.name, &key.target,
+			&s.RecentVersions, &s.stars, &s.PopularityDelta7d, &s.PopularityDelta30d); err != nil {
+			return nil, fmt.Errorf("failed to scan trending signals: %w", err)
+		}
+		signals[key] = s
+	}
+	return signals, rows.Err()
+}
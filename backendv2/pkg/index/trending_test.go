@@ -0,0 +1,36 @@
+package index
+
+import "testing"
+
+func TestTrendingScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals TrendingSignals
+		want    float64
+	}{
+		{"no growth", TrendingSignals{stars: 1000, RecentVersions: 3}, 0},
+		{"losing stars", TrendingSignals{stars: 1000, PopularityDelta7d: -5, PopularityDelta30d: -10}, 0},
+		// (3*2 + 6) / log2(0 + 2)
+		{"new repository", TrendingSignals{stars: 6, PopularityDelta7d: 2, PopularityDelta30d: 6}, 12},
+		// (3*10 + 30) / log2(1022 + 2)
+		{"large repository", TrendingSignals{stars: 1052, PopularityDelta7d: 10, PopularityDelta30d: 30}, 6},
+		// 6 * (1 + 0.25*2)
+		{"recent releases", TrendingSignals{stars: 1052, PopularityDelta7d: 10, PopularityDelta30d: 30, RecentVersions: 2}, 9},
+		// 6 * (1 + 0.25*4)
+		{"release boost is capped", TrendingSignals{stars: 1052, PopularityDelta7d: 10, PopularityDelta30d: 30, RecentVersions: 20}, 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trendingScore(tt.signals); got != tt.want {
+				t.Errorf("trendingScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	small := trendingScore(TrendingSignals{stars: 60, PopularityDelta7d: 20, PopularityDelta30d: 50})
+	large := trendingScore(TrendingSignals{stars: 50050, PopularityDelta7d: 20, PopularityDelta30d: 50})
+	if small <= large {
+		t.Errorf("small repository doubling its stars scored %v, not above the same growth on a large repository %v", small, large)
+	}
+}
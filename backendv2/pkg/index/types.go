@@ -16,6 +16,10 @@ type ModuleVersionIndex struct {
 	ForkOfLink         *string       `json:"fork_of_link,omitempty"` // GitHub URL to parent
 	UpstreamPopularity int           `json:"upstream_popularity"`    // parent repo stars
 	UpstreamForkCount  int           `json:"upstream_fork_count"`    // parent repo forks
+	RepositoryDeltas
+
+	// history is uploaded as history.json next to index.json
+	history *RepositoryHistory
 }
 
 // ModuleAddr represents a module address in the registry
@@ -47,6 +51,10 @@ type ProviderVersionIndex struct {
 	ForkOfLink         *string       `json:"fork_of_link,omitempty"` // GitHub URL to parent
 	UpstreamPopularity int           `json:"upstream_popularity"`    // parent repo stars
 	UpstreamForkCount  int           `json:"upstream_fork_count"`    // parent repo forks
+	RepositoryDeltas
+
+	// history is uploaded as history.json next to index.json
+	history *RepositoryHistory
 }
 
 // ProviderAddr represents a provider address in the registry
@@ -67,6 +75,8 @@ type ModuleEntry struct {
 	Description   string     `json:"description,omitempty"`
 	LatestVersion string     `json:"latest_version"`
 	PublishedAt   time.Time  `json:"published_at"`
+	IsBlocked     bool       `json:"is_blocked"`
+	BlockedReason *string    `json:"blocked_reason,omitempty"`
 }
 
 // GlobalProviderIndex represents the global provider index file
@@ -86,6 +96,8 @@ type ProviderEntry struct {
 	ForkCount     int           `json:"fork_count"`             // Repository fork count
 	ForkOf        *ProviderAddr `json:"fork_of,omitempty"`      // Parent provider if this is a fork
 	ForkOfLink    *string       `json:"fork_of_link,omitempty"` // GitHub URL to parent repo
+	IsBlocked     bool          `json:"is_blocked"`
+	BlockedReason *string       `json:"blocked_reason,omitempty"`
 }
 
 // RepositoryStats holds repository statistics from GitHub
@@ -0,0 +1,388 @@
+package index
+
+import (
+	"cmp"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// registryHosts are the hostnames of module sources that refer to modules in this registry. Sources without a hostname
+// default to the OpenTofu registry.
+var registryHosts = map[string]bool{
+	"registry.opentofu.org": true,
+	"registry.terraform.io": true,
+}
+
+var (
+	moduleNamePattern   = regexp.MustCompile(`^[0-9A-Za-z](?:[0-9A-Za-z-_]{0,62}[0-9A-Za-z])?$`)
+	moduleTargetPattern = regexp.MustCompile(`^[0-9A-Za-z]{1,64}$`)
+)
+
+// ModuleUsedBy represents the used-by.json file of a module: the modules whose latest version calls it
+type ModuleUsedBy struct {
+	Addr   ModuleAddr        `json:"addr"`
+	UsedBy []ModuleDependent `json:"used_by"`
+}
+
+// ModuleDependent is a module call to a registry module
+type ModuleDependent struct {
+	Addr              ModuleAddr `json:"addr"`                         // The calling module
+	Version           string     `json:"version"`                      // The latest version of the calling module
+	Submodule         string     `json:"submodule,omitempty"`          // The submodule making the call, empty for the root module
+	Name              string     `json:"name"`                         // The name of the module block
+	VersionConstraint string     `json:"version_constraint,omitempty"` // The version constraint of the module block
+}
+
+// UsedByStats summarises a rebuild of the used-by indexes
+type UsedByStats struct {
+	Dependencies int // Registry module calls found
+	Modules      int // Modules with at least one dependent
+	Uploaded     int // used-by.json files uploaded because they changed, or all of them when forced
+}
+
+// usedByFile is the rendered used-by.json of a module
+type usedByFile struct {
+	UsedBy   *ModuleUsedBy
+	Data     []byte
+	Checksum string // MD5 of Data
+}
+
+// moduleKey is the lowercase address of a module, registry addresses are case-insensitive
+type moduleKey struct {
+	namespace string
+	name      string
+	target    string
+}
+
+// moduleDependency is a registry module call resolved to the module it calls
+type moduleDependency struct {
+	Dependency ModuleAddr
+	Dependent  ModuleDependent
+}
+
+// moduleCall is a module call found in the latest version of a module, before its source is resolved
+type moduleCall struct {
+	Dependent ModuleDependent
+	Source    string
+}
+
+// RebuildModuleUsedBy resolves the registry module calls in the latest completed version of every module and uploads
+// the used-by.json of every module whose file changed since it was last uploaded. Every module gets a file, with an
+// empty used_by when nothing calls it. With force every file is uploaded, which restores files missing from the bucket.
+//
+// The checksum of a file is only recorded once it was uploaded, so files that failed to upload are uploaded again on
+// the next run.
+func RebuildModuleUsedBy(ctx context.Context, db *pgxpool.Pool, store bucket.Store, force bool) (*UsedByStats, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "index.rebuild_module_used_by")
+	defer span.End()
+
+	span.SetAttributes(attribute.Bool("force", force))
+
+	known, calls, checksums, err := queryUsedByInputs(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies := resolveModuleCalls(calls, known)
+	usedBy := groupUsedBy(dependencies)
+	files, err := usedByFiles(usedBy, known)
+	if err != nil {
+		return nil, err
+	}
+	pending := pendingUsedBy(files, checksums, force)
+
+	// The transaction is not held open during the uploads, which can take a while for the whole registry
+	var uploadErr error
+	uploaded := make([]usedByFile, 0, len(pending))
+	for _, file := range pending {
+		addr := file.UsedBy.Addr
+		key := fmt.Sprintf("modules/%s/%s/%s/used-by.json", addr.Namespace, addr.Name, addr.Target)
+		if err := upload(ctx, store, key, file.Data, "application/json"); err != nil {
+			span.RecordError(err)
+			uploadErr = fmt.Errorf("failed to upload %s: %w", key, err)
+			break
+		}
+		uploaded = append(uploaded, file)
+	}
+
+	if err := storeUsedByChecksums(ctx, db, uploaded); err != nil {
+		return nil, err
+	}
+	if uploadErr != nil {
+		return nil, uploadErr
+	}
+
+	stats := &UsedByStats{Dependencies: len(dependencies), Modules: len(usedBy), Uploaded: len(uploaded)}
+	span.SetAttributes(
+		attribute.Int("dependencies.count", stats.Dependencies),
+		attribute.Int("modules.count", stats.Modules),
+		attribute.Int("uploaded.count", stats.Uploaded),
+	)
+	return stats, nil
+}
+
+// parseRegistrySource parses a registry module source such as hashicorp/consul/aws or
+// registry.opentofu.org/hashicorp/consul/aws//modules/server. Local paths, other hosts and go-getter style sources are
+// not registry modules of this registry.
+func parseRegistrySource(source string) (moduleKey, bool) {
+	if strings.Contains(source, "::") || strings.Contains(source, "?") {
+		return moduleKey{}, false
+	}
+	// A subdirectory of the module package is still a call to the module
+	if i := strings.Index(source, "//"); i >= 0 {
+		source = source[:i]
+	}
+
+	parts := strings.Split(source, "/")
+	if len(parts) == 4 {
+		if !registryHosts[strings.ToLower(parts[0])] {
+			return moduleKey{}, false
+		}
+		parts = parts[1:]
+	}
+	if len(parts) != 3 {
+		return moduleKey{}, false
+	}
+	if !moduleNamePattern.MatchString(parts[0]) || !moduleNamePattern.MatchString(parts[1]) || !moduleTargetPattern.MatchString(parts[2]) {
+		return moduleKey{}, false
+	}
+
+	return moduleKey{
+		namespace: strings.ToLower(parts[0]),
+		name:      strings.ToLower(parts[1]),
+		target:    strings.ToLower(parts[2]),
+	}, true
+}
+
+// resolveModuleCalls keeps the calls to modules known to the registry, calls of a module to itself are left out
+func resolveModuleCalls(calls []moduleCall, known map[moduleKey]ModuleAddr) []moduleDependency {
+	var dependencies []moduleDependency
+	for _, call := range calls {
+		key, ok := parseRegistrySource(call.Source)
+		if !ok {
+			continue
+		}
+		addr, ok := known[key]
+		if !ok || addr == call.Dependent.Addr {
+			continue
+		}
+		dependencies = append(dependencies, moduleDependency{Dependency: addr, Dependent: call.Dependent})
+	}
+	return dependencies
+}
+
+// groupUsedBy groups dependencies by the module they call, with the dependents of each module in a stable order
+func groupUsedBy(dependencies []moduleDependency) map[ModuleAddr][]ModuleDependent {
+	usedBy := map[ModuleAddr][]ModuleDependent{}
+	for _, d := range dependencies {
+		usedBy[d.Dependency] = append(usedBy[d.Dependency], d.Dependent)
+	}
+	for _, dependents := range usedBy {
+		slices.SortFunc(dependents, func(a, b ModuleDependent) int {
+			return cmp.Or(
+				cmp.Compare(a.Addr.Display, b.Addr.Display),
+				cmp.Compare(a.Submodule, b.Submodule),
+				cmp.Compare(a.Name, b.Name),
+			)
+		})
+	}
+	return usedBy
+}
+
+// usedByFiles renders the used-by.json of every known module, modules without dependents get an empty used_by
+func usedByFiles(usedBy map[ModuleAddr][]ModuleDependent, known map[moduleKey]ModuleAddr) ([]usedByFile, error) {
+	files := make([]usedByFile, 0, len(known))
+	for _, addr := range known {
+		dependents := usedBy[addr]
+		if dependents == nil {
+			dependents = []ModuleDependent{}
+		}
+		module := &ModuleUsedBy{Addr: addr, UsedBy: dependents}
+
+		data, err := json.MarshalIndent(module, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal used-by index of %s: %w", addr.Display, err)
+		}
+		hash := md5.Sum(data)
+		files = append(files, usedByFile{UsedBy: module, Data: data, Checksum: hex.EncodeToString(hash[:])})
+	}
+
+	slices.SortFunc(files, func(a, b usedByFile) int {
+		return cmp.Compare(a.UsedBy.Addr.Display, b.UsedBy.Addr.Display)
+	})
+	return files, nil
+}
+
+// pendingUsedBy returns the files whose checksum differs from the last uploaded one, or all files when force is set
+func pendingUsedBy(files []usedByFile, checksums map[ModuleAddr]string, force bool) []usedByFile {
+	if force {
+		return files
+	}
+	var pending []usedByFile
+	for _, file := range files {
+		if checksums[file.UsedBy.Addr] != file.Checksum {
+			pending = append(pending, file)
+		}
+	}
+	return pending
+}
+
+func newModuleAddr(namespace, name, target string) ModuleAddr {
+	return ModuleAddr{
+		Display:   fmt.Sprintf("%s/%s/%s", namespace, name, target),
+		Namespace: namespace,
+		Name:      name,
+		Target:    target,
+	}
+}
+
+// queryUsedByInputs reads the modules, their calls and the checksums of the uploaded files in one transaction, so they
+// are consistent with each other
+func queryUsedByInputs(ctx context.Context, db *pgxpool.Pool) (map[moduleKey]ModuleAddr, []moduleCall, map[ModuleAddr]string, error) {
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	known, err := queryKnownModules(ctx, tx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	calls, err := queryLatestModuleCalls(ctx, tx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	checksums, err := queryUsedByChecksums(ctx, tx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return known, calls, checksums, nil
+}
+
+// queryKnownModules retrieves the address of every module, keyed by its lowercase address
+func queryKnownModules(ctx context.Context, tx pgx.Tx) (map[moduleKey]ModuleAddr, error) {
+	rows, err := tx.Query(ctx, `SELECT namespace, name, target FROM modules`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query modules: %w", err)
+	}
+	defer rows.Close()
+
+	known := map[moduleKey]ModuleAddr{}
+	for rows.Next() {
+		var namespace, name, target string
+		if err := rows.Scan(&namespace, &name, &target); err != nil {
+			return nil, fmt.Errorf("failed to scan module: %w", err)
+		}
+		key := moduleKey{strings.ToLower(namespace), strings.ToLower(name), strings.ToLower(target)}
+		known[key] = newModuleAddr(namespace, name, target)
+	}
+	return known, rows.Err()
+}
+
+// queryLatestModuleCalls retrieves the module calls of the root module and the submodules of the latest completed
+// version of every module
+func queryLatestModuleCalls(ctx context.Context, tx pgx.Tx) ([]moduleCall, error) {
+	// Empty dependency lists are stored as JSON null rather than an empty array
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (module_namespace, module_name, module_target)
+				module_namespace, module_name, module_target, version, tofu_json
+			FROM module_versions
+			WHERE scrape_status = 'completed' AND tofu_json IS NOT NULL
+			ORDER BY module_namespace, module_name, module_target, safe_to_semver(version) DESC
+		),
+		components AS (
+			SELECT module_namespace, module_name, module_target, version, '' AS submodule,
+				tofu_json->'dependencies' AS dependencies
+			FROM latest
+			UNION ALL
+			SELECT l.module_namespace, l.module_name, l.module_target, l.version, s.key,
+				s.value->'dependencies'
+			FROM latest l, jsonb_each(l.tofu_json->'submodules') s
+			WHERE jsonb_typeof(l.tofu_json->'submodules') = 'object'
+		)
+		SELECT c.module_namespace, c.module_name, c.module_target, c.version, c.submodule,
+			COALESCE(d->>'name', ''), COALESCE(d->>'source', ''), COALESCE(d->>'version_constraint', '')
+		FROM components c, jsonb_array_elements(c.dependencies) d
+		WHERE jsonb_typeof(c.dependencies) = 'array'`
+
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query module calls: %w", err)
+	}
+	defer rows.Close()
+
+	var calls []moduleCall
+	for rows.Next() {
+		var (
+			namespace, name, target string
+			call                    moduleCall
+		)
+		if err := rows.Scan(&namespace, &name, &target, &call.Dependent.Version, &call.Dependent.Submodule,
+			&call.Dependent.Name, &call.Source, &call.Dependent.VersionConstraint); err != nil {
+			return nil, fmt.Errorf("failed to scan module call: %w", err)
+		}
+		call.Dependent.Addr = newModuleAddr(namespace, name, target)
+		calls = append(calls, call)
+	}
+	return calls, rows.Err()
+}
+
+// queryUsedByChecksums retrieves the checksums of the used-by files uploaded by previous rebuilds
+func queryUsedByChecksums(ctx context.Context, tx pgx.Tx) (map[ModuleAddr]string, error) {
+	rows, err := tx.Query(ctx, `SELECT namespace, name, target, checksum FROM module_used_by_files`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query module used-by files: %w", err)
+	}
+	defer rows.Close()
+
+	checksums := map[ModuleAddr]string{}
+	for rows.Next() {
+		var namespace, name, target, checksum string
+		if err := rows.Scan(&namespace, &name, &target, &checksum); err != nil {
+			return nil, fmt.Errorf("failed to scan module used-by file: %w", err)
+		}
+		checksums[newModuleAddr(namespace, name, target)] = checksum
+	}
+	return checksums, rows.Err()
+}
+
+// storeUsedByChecksums records the checksums of the uploaded used-by files
+func storeUsedByChecksums(ctx context.Context, db *pgxpool.Pool, files []usedByFile) error {
+	batch := &pgx.Batch{}
+	for _, file := range files {
+		addr := file.UsedBy.Addr
+		batch.Queue(`
+			INSERT INTO module_used_by_files (namespace, name, target, checksum, uploaded_at)
+			VALUES ($1, $2, $3, $4, NOW())
+			ON CONFLICT (namespace, name, target) DO UPDATE SET
+				checksum = EXCLUDED.checksum,
+				uploaded_at = EXCLUDED.uploaded_at`,
+			addr.Namespace, addr.Name, addr.Target, file.Checksum)
+	}
+
+	if batch.Len() > 0 {
+		if err := db.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("failed to store module used-by files: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package index
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRegistrySource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   moduleKey
+		ok     bool
+	}{
+		{"terraform-aws-modules/vpc/aws", moduleKey{"terraform-aws-modules", "vpc", "aws"}, true},
+		{"Terraform-AWS-Modules/VPC/AWS", moduleKey{"terraform-aws-modules", "vpc", "aws"}, true},
+		{"registry.opentofu.org/hashicorp/consul/aws", moduleKey{"hashicorp", "consul", "aws"}, true},
+		{"registry.terraform.io/hashicorp/consul/aws//modules/server", moduleKey{"hashicorp", "consul", "aws"}, true},
+		{"hashicorp/consul/aws//modules/server", moduleKey{"hashicorp", "consul", "aws"}, true},
+		{"app.terraform.io/example-corp/k8s-cluster/azurerm", moduleKey{}, false},
+		{"./modules/network", moduleKey{}, false},
+		{"../network", moduleKey{}, false},
+		{"github.com/hashicorp/example", moduleKey{}, false},
+		{"github.com/hashicorp/example/modules", moduleKey{}, false},
+		{"git::https://example.com/network.git", moduleKey{}, false},
+		{"git::https://example.com/vpc/aws/module.git?ref=v1.2.0", moduleKey{}, false},
+		{"https://example.com/vpc-module.zip", moduleKey{}, false},
+		{"s3::https://s3-eu-west-1.amazonaws.com/examplecorp-modules/vpc.zip", moduleKey{}, false},
+		{"hashicorp/consul/aws-v2", moduleKey{}, false},
+		{"", moduleKey{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got, ok := parseRegistrySource(tt.source)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseRegistrySource(%q) = %+v, %v, want %+v, %v", tt.source, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestUsedByFiles(t *testing.T) {
+	vpc := newModuleAddr("terraform-aws-modules", "vpc", "aws")
+	eks := newModuleAddr("terraform-aws-modules", "eks", "aws")
+	rds := newModuleAddr("terraform-aws-modules", "rds", "aws")
+
+	known := map[moduleKey]ModuleAddr{}
+	for _, addr := range []ModuleAddr{vpc, eks, rds} {
+		known[moduleKey{addr.Namespace, addr.Name, addr.Target}] = addr
+	}
+
+	calls := []moduleCall{
+		{Dependent: ModuleDependent{Addr: eks, Version: "20.0.0", Name: "vpc"}, Source: "terraform-aws-modules/vpc/aws"},
+		{Dependent: ModuleDependent{Addr: eks, Version: "20.0.0", Submodule: "fargate", Name: "network"}, Source: "registry.opentofu.org/terraform-aws-modules/vpc/aws"},
+		{Dependent: ModuleDependent{Addr: eks, Version: "20.0.0", Name: "local"}, Source: "./modules/local"},
+		{Dependent: ModuleDependent{Addr: eks, Version: "20.0.0", Name: "unknown"}, Source: "example/unknown/aws"},
+		{Dependent: ModuleDependent{Addr: vpc, Version: "5.0.0", Name: "self"}, Source: "terraform-aws-modules/vpc/aws"},
+	}
+
+	files, err := usedByFiles(groupUsedBy(resolveModuleCalls(calls, known)), known)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every known module gets a file, an empty used_by rather than none for the unused ones
+	want := []*ModuleUsedBy{
+		{Addr: eks, UsedBy: []ModuleDependent{}},
+		{Addr: rds, UsedBy: []ModuleDependent{}},
+		{Addr: vpc, UsedBy: []ModuleDependent{
+			{Addr: eks, Version: "20.0.0", Name: "vpc"},
+			{Addr: eks, Version: "20.0.0", Submodule: "fargate", Name: "network"},
+		}},
+	}
+	var got []*ModuleUsedBy
+	for _, file := range files {
+		got = append(got, file.UsedBy)
+
+		var decoded ModuleUsedBy
+		if err := json.Unmarshal(file.Data, &decoded); err != nil {
+			t.Fatalf("used-by.json of %s: %v", file.UsedBy.Addr.Display, err)
+		}
+		if !reflect.DeepEqual(&decoded, file.UsedBy) {
+			t.Errorf("used-by.json of %s = %s, want %+v", file.UsedBy.Addr.Display, file.Data, file.UsedBy)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("usedByFiles() = %+v, want %+v", got, want)
+	}
+	if !strings.Contains(string(files[1].Data), `"used_by": []`) {
+		t.Errorf("used-by.json of an unused module = %s, want an empty used_by", files[1].Data)
+	}
+}
+
+func TestPendingUsedBy(t *testing.T) {
+	vpc := newModuleAddr("terraform-aws-modules", "vpc", "aws")
+	eks := newModuleAddr("terraform-aws-modules", "eks", "aws")
+	rds := newModuleAddr("terraform-aws-modules", "rds", "aws")
+	files := []usedByFile{
+		{UsedBy: &ModuleUsedBy{Addr: eks}, Checksum: "a"},
+		{UsedBy: &ModuleUsedBy{Addr: rds}, Checksum: "b"},
+		{UsedBy: &ModuleUsedBy{Addr: vpc}, Checksum: "c"},
+	}
+
+	tests := []struct {
+		name      string
+		checksums map[ModuleAddr]string
+		force     bool
+		want      []ModuleAddr
+	}{
+		{name: "first rebuild", want: []ModuleAddr{eks, rds, vpc}},
+		{name: "unchanged", checksums: map[ModuleAddr]string{eks: "a", rds: "b", vpc: "c"}},
+		{name: "changed and new modules", checksums: map[ModuleAddr]string{eks: "a", rds: "old"}, want: []ModuleAddr{rds, vpc}},
+		{name: "forced", checksums: map[ModuleAddr]string{eks: "a", rds: "b", vpc: "c"}, force: true, want: []ModuleAddr{eks, rds, vpc}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []ModuleAddr
+			for _, file := range pendingUsedBy(files, tt.checksums, tt.force) {
+				got = append(got, file.UsedBy.Addr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pendingUsedBy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
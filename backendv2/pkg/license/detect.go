@@ -15,15 +15,18 @@ import (
 	"github.com/opentofu/registry-ui/pkg/config"
 	"github.com/opentofu/registry-ui/pkg/repository"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 type Detector struct {
 	config       config.LicenseConfig
 	licenseMap   map[string]struct{}
 	githubClient *repository.Client
+	host         vcs.Host
 }
 
-func New(licenseConfig config.LicenseConfig, githubClient *repository.Client) (*Detector, error) {
+// New creates a license detector. githubClient is optional and only used as a fallback for GitHub hosted repositories.
+func New(licenseConfig config.LicenseConfig, githubClient *repository.Client, host vcs.Host) (*Detector, error) {
 	licenseMap := map[string]struct{}{}
 	for _, license := range licenseConfig.CompatibleLicenses {
 		licenseMap[strings.ToLower(license)] = struct{}{}
@@ -32,10 +35,11 @@ func New(licenseConfig config.LicenseConfig, githubClient *repository.Client) (*
 		licenseMap:   licenseMap,
 		config:       licenseConfig,
 		githubClient: githubClient,
+		host:         host,
 	}, nil
 }
 
-func (d *Detector) Detect(ctx context.Context, directory string, repoURL string) (List, error) {
+func (d *Detector) Detect(ctx context.Context, directory string, repo vcs.Repo) (List, error) {
 	ctx, span := telemetry.Tracer().Start(ctx, "license.detect")
 	defer span.End()
 
@@ -43,7 +47,7 @@ func (d *Detector) Detect(ctx context.Context, directory string, repoURL string)
 		attribute.Float64("license.confidence_threshold", float64(d.config.ConfidenceThreshold)),
 		attribute.Float64("license.confidence_override_threshold", float64(d.config.ConfidenceOverrideThreshold)),
 		attribute.Int("license.compatible_licenses_count", len(d.config.CompatibleLicenses)),
-		attribute.String("license.repo_url", repoURL),
+		attribute.String("license.repo", repo.String()),
 	)
 
 	slog.DebugContext(ctx, "Starting license detection",
@@ -58,7 +62,7 @@ func (d *Detector) Detect(ctx context.Context, directory string, repoURL string)
 
 	var result []License
 	if len(matches) > 0 {
-		filesWithLicenses := d.buildLicenseFileMap(matches, repoURL)
+		filesWithLicenses := d.buildLicenseFileMap(matches, repo)
 		licenseFiles := d.filterAndSortLicenseFiles(ctx, filesWithLicenses)
 		result = d.collectResults(ctx, span, licenseFiles, filesWithLicenses)
 	}
@@ -72,8 +76,8 @@ func (d *Detector) Detect(ctx context.Context, directory string, repoURL string)
 	}
 
 	// No licenses found locally, try GitHub API fallback
-	slog.InfoContext(ctx, "No licenses detected locally, trying GitHub API fallback", "repo_url", repoURL)
-	githubLicense, err := d.detectLicenseFromGitHub(ctx, repoURL)
+	slog.InfoContext(ctx, "No licenses detected locally, trying GitHub API fallback", "repo", repo.String())
+	githubLicense, err := d.detectLicenseFromGitHub(ctx, repo)
 	if err != nil {
 		slog.WarnContext(ctx, "GitHub API license detection failed", "error", err)
 		return nil, nil
@@ -135,7 +139,7 @@ func (d *Detector) detectLicenseInDirectory(ctx context.Context, directory strin
 	return allMatches, nil
 }
 
-func (d *Detector) buildLicenseFileMap(matches []licensedb.Match, repoURL string) map[string][]License {
+func (d *Detector) buildLicenseFileMap(matches []licensedb.Match, repo vcs.Repo) map[string][]License {
 	filesWithLicenses := make(map[string][]License)
 
 	for _, match := range matches {
@@ -154,7 +158,7 @@ func (d *Detector) buildLicenseFileMap(matches []licensedb.Match, repoURL string
 			Confidence:   match.Confidence,
 			IsCompatible: isCompatible,
 			File:         match.File,
-			Link:         generateGitHubLink(repoURL, match.File),
+			Link:         d.host.BlobURL(repo, "main", match.File),
 		})
 	}
 
@@ -7,42 +7,23 @@ import (
 
 	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/opentofu/registry-ui/pkg/config"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
-// generateGitHubLink creates a GitHub blob URL for the given repository URL and file path
-func generateGitHubLink(repoURL, filePath string) string {
-	if repoURL == "" || filePath == "" {
-		return ""
-	}
-
-	// Convert git URLs to GitHub web URLs
-	// Handle both https://github.com/owner/repo.git and git@github.com:owner/repo.git formats
-	var githubURL string
-	if after, ok := strings.CutPrefix(repoURL, "git@github.com:"); ok {
-		// git@github.com:owner/repo.git -> https://github.com/owner/repo
-		githubURL = "https://github.com/" + strings.TrimSuffix(after, ".git")
-	} else if strings.Contains(repoURL, "github.com") {
-		// https://github.com/owner/repo.git -> https://github.com/owner/repo
-		githubURL = strings.TrimSuffix(repoURL, ".git")
-	} else {
-		return ""
-	}
-
-	// Create blob URL pointing to main branch
-	return githubURL + "/blob/main/" + filePath
-}
-
 // detectLicenseFromGitHub detects license using GitHub API
-func (d *Detector) detectLicenseFromGitHub(ctx context.Context, repoURL string) (*License, error) {
+func (d *Detector) detectLicenseFromGitHub(ctx context.Context, repo vcs.Repo) (*License, error) {
 	ctx, span := telemetry.Tracer().Start(ctx, "license.detect_from_github")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("repoURL", repoURL))
-	if d.githubClient == nil {
+	if d.githubClient == nil || d.host.Type() != config.VCSTypeGitHub {
 		return nil, fmt.Errorf("github client not available")
 	}
 
+	repoURL := d.host.RepoURL(repo)
+	span.SetAttributes(attribute.String("repoURL", repoURL))
+
 	spdxID, err := d.githubClient.DetectLicenseFromGitHub(ctx, repoURL)
 	if err != nil {
 		span.RecordError(err)
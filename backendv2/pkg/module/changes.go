@@ -0,0 +1,299 @@
+package module
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	goversion "github.com/hashicorp/go-version"
+)
+
+// ChangeKind classifies a single difference between two module versions
+type ChangeKind string
+
+const (
+	ChangeVariableAdded          ChangeKind = "variable_added"
+	ChangeVariableRemoved        ChangeKind = "variable_removed"
+	ChangeVariableTypeChanged    ChangeKind = "variable_type_changed"
+	ChangeVariableDefaultRemoved ChangeKind = "variable_default_removed"
+	ChangeVariableDefaultChanged ChangeKind = "variable_default_changed"
+	ChangeOutputAdded            ChangeKind = "output_added"
+	ChangeOutputRemoved          ChangeKind = "output_removed"
+	ChangeProviderAdded          ChangeKind = "provider_added"
+	ChangeProviderRemoved        ChangeKind = "provider_removed"
+	ChangeProviderTightened      ChangeKind = "provider_constraint_tightened"
+	ChangeProviderRelaxed        ChangeKind = "provider_constraint_changed"
+)
+
+// Change is a single difference between two module versions
+type Change struct {
+	Kind     ChangeKind `json:"kind"`
+	Name     string     `json:"name"`
+	Breaking bool       `json:"breaking"`
+	Before   any        `json:"before,omitempty"`
+	After    any        `json:"after,omitempty"`
+}
+
+// VersionChanges is the content of changes.json: the differences of a module version relative to the previous version
+type VersionChanges struct {
+	Version         string   `json:"version"`
+	PreviousVersion string   `json:"previous_version,omitempty"`
+	Breaking        bool     `json:"breaking"`
+	Changes         []Change `json:"changes"`
+}
+
+// DiffModuleData compares the root module of two versions and classifies every change as breaking or non-breaking.
+// previous may be nil for the first version of a module, in which case there are no changes.
+func DiffModuleData(previousVersion string, previous *ModuleData, version string, current *ModuleData) *VersionChanges {
+	result := &VersionChanges{
+		Version:         version,
+		PreviousVersion: previousVersion,
+		Changes:         []Change{},
+	}
+	if previous == nil || current == nil {
+		return result
+	}
+
+	result.Changes = append(result.Changes, diffVariables(previous.Variables, current.Variables)...)
+	result.Changes = append(result.Changes, diffOutputs(previous.Outputs, current.Outputs)...)
+	result.Changes = append(result.Changes, diffProviders(previous.Providers, current.Providers)...)
+
+	for _, change := range result.Changes {
+		if change.Breaking {
+			result.Breaking = true
+			break
+		}
+	}
+
+	return result
+}
+
+func diffVariables(previous, current map[string]Variable) []Change {
+	var changes []Change
+
+	for _, name := range sortedUnion(previous, current) {
+		before, hadBefore := previous[name]
+		after, hasAfter := current[name]
+
+		switch {
+		case !hadBefore:
+			// Callers can't be passing a value yet, so only a required variable breaks them
+			changes = append(changes, Change{Kind: ChangeVariableAdded, Name: name, Breaking: after.Required, After: after})
+		case !hasAfter:
+			changes = append(changes, Change{Kind: ChangeVariableRemoved, Name: name, Breaking: true, Before: before})
+		default:
+			if normalizeType(before.Type) != normalizeType(after.Type) {
+				changes = append(changes, Change{Kind: ChangeVariableTypeChanged, Name: name, Breaking: true, Before: before.Type, After: after.Type})
+			}
+			if !before.Required && after.Required {
+				changes = append(changes, Change{Kind: ChangeVariableDefaultRemoved, Name: name, Breaking: true, Before: before.Default})
+			} else if !after.Required && !reflect.DeepEqual(before.Default, after.Default) {
+				changes = append(changes, Change{Kind: ChangeVariableDefaultChanged, Name: name, Before: before.Default, After: after.Default})
+			}
+		}
+	}
+
+	return changes
+}
+
+func diffOutputs(previous, current map[string]Output) []Change {
+	var changes []Change
+
+	for _, name := range sortedUnion(previous, current) {
+		_, hadBefore := previous[name]
+		_, hasAfter := current[name]
+
+		switch {
+		case !hadBefore:
+			changes = append(changes, Change{Kind: ChangeOutputAdded, Name: name})
+		case !hasAfter:
+			changes = append(changes, Change{Kind: ChangeOutputRemoved, Name: name, Breaking: true})
+		}
+	}
+
+	return changes
+}
+
+func diffProviders(previous, current []Provider) []Change {
+	// Provider configurations are matched by their source address, aliases share the same constraint
+	before := providerConstraints(previous)
+	after := providerConstraints(current)
+
+	var changes []Change
+	for _, name := range sortedUnion(before, after) {
+		beforeConstraint, hadBefore := before[name]
+		afterConstraint, hasAfter := after[name]
+
+		switch {
+		case !hadBefore:
+			changes = append(changes, Change{Kind: ChangeProviderAdded, Name: name, After: afterConstraint})
+		case !hasAfter:
+			changes = append(changes, Change{Kind: ChangeProviderRemoved, Name: name, Before: beforeConstraint})
+		case beforeConstraint != afterConstraint:
+			kind := ChangeProviderRelaxed
+			if constraintTightened(beforeConstraint, afterConstraint) {
+				kind = ChangeProviderTightened
+			}
+			changes = append(changes, Change{
+				Kind:     kind,
+				Name:     name,
+				Breaking: kind == ChangeProviderTightened,
+				Before:   beforeConstraint,
+				After:    afterConstraint,
+			})
+		}
+	}
+
+	return changes
+}
+
+func providerConstraints(providers []Provider) map[string]string {
+	result := make(map[string]string, len(providers))
+	for _, p := range providers {
+		name := p.FullName
+		if name == "" {
+			name = p.Name
+		}
+		// Only constraints declared by the module itself matter, not ones from nested module calls
+		if p.ModuleAddress != "" {
+			continue
+		}
+		if existing, ok := result[name]; ok && existing != "" {
+			continue
+		}
+		result[name] = strings.TrimSpace(p.VersionConstraint)
+	}
+	return result
+}
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+){0,2}`)
+
+// constraintTightened reports whether any provider version accepted by the previous constraint is rejected by
+// the current one. Candidate versions are taken around every version mentioned in either constraint.
+func constraintTightened(previous, current string) bool {
+	if current == "" {
+		return false
+	}
+	currentConstraints, err := goversion.NewConstraint(current)
+	if err != nil {
+		// Can't reason about it, be conservative
+		return true
+	}
+
+	var previousConstraints goversion.Constraints
+	if previous != "" {
+		previousConstraints, err = goversion.NewConstraint(previous)
+		if err != nil {
+			return true
+		}
+	}
+
+	for _, candidate := range candidateVersions(previous, current) {
+		if previousConstraints.Check(candidate) && !currentConstraints.Check(candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func candidateVersions(constraints ...string) []*goversion.Version {
+	candidates := []*goversion.Version{goversion.Must(goversion.NewVersion("0.0.0")), goversion.Must(goversion.NewVersion("9999.0.0"))}
+
+	for _, constraint := range constraints {
+		for _, match := range versionPattern.FindAllString(constraint, -1) {
+			v, err := goversion.NewVersion(match)
+			if err != nil {
+				continue
+			}
+			segments := v.Segments()
+			for i := range 3 {
+				for _, delta := range []int{-1, 0, 1} {
+					bumped := slices.Clone(segments)
+					bumped[i] += delta
+					if bumped[i] < 0 {
+						continue
+					}
+					// Reset the less significant segments when bumping
+					if delta != 0 {
+						for j := i + 1; j < len(bumped); j++ {
+							bumped[j] = 0
+						}
+					}
+					candidate, err := goversion.NewVersion(fmt.Sprintf("%d.%d.%d", bumped[0], bumped[1], bumped[2]))
+					if err == nil {
+						candidates = append(candidates, candidate)
+					}
+				}
+			}
+		}
+	}
+
+	return candidates
+}
+
+// normalizeType removes formatting differences from a type expression
+func normalizeType(t string) string {
+	return strings.Join(strings.Fields(t), "")
+}
+
+func sortedUnion[V any](a, b map[string]V) []string {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	result := slices.Collect(maps.Keys(keys))
+	sort.Strings(result)
+	return result
+}
+
+// PreviousVersion returns the highest version lower than version, or "" if there is none. Versions that are not
+// valid semver are ignored.
+func PreviousVersion(versions []string, version string) string {
+	current, err := goversion.NewVersion(version)
+	if err != nil {
+		return ""
+	}
+
+	var best *goversion.Version
+	var bestRaw string
+	for _, raw := range versions {
+		v, err := goversion.NewVersion(raw)
+		if err != nil || !v.LessThan(current) {
+			continue
+		}
+		if best == nil || v.GreaterThan(best) {
+			best = v
+			bestRaw = raw
+		}
+	}
+	return bestRaw
+}
+
+// NextVersion returns the lowest version greater than version, or "" if there is none
+func NextVersion(versions []string, version string) string {
+	current, err := goversion.NewVersion(version)
+	if err != nil {
+		return ""
+	}
+
+	var best *goversion.Version
+	var bestRaw string
+	for _, raw := range versions {
+		v, err := goversion.NewVersion(raw)
+		if err != nil || !v.GreaterThan(current) {
+			continue
+		}
+		if best == nil || v.LessThan(best) {
+			best = v
+			bestRaw = raw
+		}
+	}
+	return bestRaw
+}
@@ -0,0 +1,141 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/module/storage"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// PublishVersionChanges generates changes.json for every given version and for the version that follows it, since
+// inserting a version in the middle of the history changes what its successor is compared against. Versions that are
+// no longer in the database only get their successor republished, which is then compared against the version before
+// the removed one.
+func PublishVersionChanges(ctx context.Context, db storage.Queryable, store bucket.Store, namespace, name, target string, versions []string) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "module.publish_version_changes")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("module.namespace", namespace),
+		attribute.String("module.name", name),
+		attribute.String("module.target", target),
+		attribute.Int("module.versions", len(versions)),
+	)
+
+	if len(versions) == 0 {
+		return nil
+	}
+
+	stored, err := storage.GetCompletedModuleVersionData(ctx, db, namespace, name, target)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err := publishChanges(ctx, store, namespace, name, target, stored, versionsToPublish(stored, versions)); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// PublishAllVersionChanges generates changes.json for every completed version of a module, backfilling versions that
+// were indexed before changes were published
+func PublishAllVersionChanges(ctx context.Context, db storage.Queryable, store bucket.Store, namespace, name, target string) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "module.publish_all_version_changes")
+	defer span.End()
+
+	stored, err := storage.GetCompletedModuleVersionData(ctx, db, namespace, name, target)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	versions := make([]string, 0, len(stored))
+	for version := range stored {
+		versions = append(versions, version)
+	}
+	span.SetAttributes(attribute.Int("module.versions", len(versions)))
+
+	if err := publishChanges(ctx, store, namespace, name, target, stored, versions); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// versionsToPublish returns the stored versions among versions and the stored version that follows each of them
+func versionsToPublish(stored map[string][]byte, versions []string) []string {
+	storedVersions := make([]string, 0, len(stored))
+	for version := range stored {
+		storedVersions = append(storedVersions, version)
+	}
+
+	toPublish := make(map[string]struct{})
+	for _, version := range versions {
+		if _, ok := stored[version]; ok {
+			toPublish[version] = struct{}{}
+		}
+		if next := NextVersion(storedVersions, version); next != "" {
+			toPublish[next] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(toPublish))
+	for version := range toPublish {
+		result = append(result, version)
+	}
+	return result
+}
+
+// publishChanges uploads the changes.json of every given version, comparing it against the stored version before it
+func publishChanges(ctx context.Context, store bucket.Store, namespace, name, target string, stored map[string][]byte, versions []string) error {
+	storedVersions := make([]string, 0, len(stored))
+	for version := range stored {
+		storedVersions = append(storedVersions, version)
+	}
+
+	for _, version := range versions {
+		current, err := decodeModuleData(stored[version])
+		if err != nil {
+			return fmt.Errorf("failed to decode module data for %s: %w", version, err)
+		}
+
+		var previous *ModuleData
+		previousVersion := PreviousVersion(storedVersions, version)
+		if previousVersion != "" {
+			previous, err = decodeModuleData(stored[previousVersion])
+			if err != nil {
+				return fmt.Errorf("failed to decode module data for %s: %w", previousVersion, err)
+			}
+		}
+
+		changes := DiffModuleData(previousVersion, previous, version, current)
+		if err := storage.StoreModuleChangesInS3(ctx, store, namespace, name, target, version, changes); err != nil {
+			return err
+		}
+
+		slog.DebugContext(ctx, "Published module version changes",
+			"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
+			"version", version,
+			"previous_version", previousVersion,
+			"changes", len(changes.Changes),
+			"breaking", changes.Breaking)
+	}
+
+	return nil
+}
+
+func decodeModuleData(data []byte) (*ModuleData, error) {
+	var moduleData ModuleData
+	if err := json.Unmarshal(data, &moduleData); err != nil {
+		return nil, err
+	}
+	return &moduleData, nil
+}
@@ -0,0 +1,208 @@
+package module
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffModuleData(t *testing.T) {
+	base := func() *ModuleData {
+		return &ModuleData{
+			ModuleComponentData: ModuleComponentData{
+				BaseComponentData: BaseComponentData{
+					Variables: map[string]Variable{
+						"name":   {Type: "string", Required: true},
+						"region": {Type: "string", Default: "us-east-1"},
+					},
+					Outputs: map[string]Output{
+						"id": {},
+					},
+				},
+				Providers: []Provider{
+					{Name: "aws", FullName: "hashicorp/aws", VersionConstraint: ">= 4.0"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		modify       func(m *ModuleData)
+		wantKinds    []ChangeKind
+		wantBreaking bool
+	}{
+		{
+			name:      "unchanged",
+			modify:    func(m *ModuleData) {},
+			wantKinds: nil,
+		},
+		{
+			name: "new optional variable",
+			modify: func(m *ModuleData) {
+				m.Variables["tags"] = Variable{Type: "map(string)", Default: map[string]any{}}
+			},
+			wantKinds: []ChangeKind{ChangeVariableAdded},
+		},
+		{
+			name: "new required variable",
+			modify: func(m *ModuleData) {
+				m.Variables["vpc_id"] = Variable{Type: "string", Required: true}
+			},
+			wantKinds:    []ChangeKind{ChangeVariableAdded},
+			wantBreaking: true,
+		},
+		{
+			name: "removed variable",
+			modify: func(m *ModuleData) {
+				delete(m.Variables, "region")
+			},
+			wantKinds:    []ChangeKind{ChangeVariableRemoved},
+			wantBreaking: true,
+		},
+		{
+			name: "changed variable type",
+			modify: func(m *ModuleData) {
+				m.Variables["name"] = Variable{Type: "list(string)", Required: true}
+			},
+			wantKinds:    []ChangeKind{ChangeVariableTypeChanged},
+			wantBreaking: true,
+		},
+		{
+			name: "type formatting only",
+			modify: func(m *ModuleData) {
+				m.Variables["region"] = Variable{Type: " string ", Default: "us-east-1"}
+			},
+			wantKinds: nil,
+		},
+		{
+			name: "removed default",
+			modify: func(m *ModuleData) {
+				m.Variables["region"] = Variable{Type: "string", Required: true}
+			},
+			wantKinds:    []ChangeKind{ChangeVariableDefaultRemoved},
+			wantBreaking: true,
+		},
+		{
+			name: "changed default",
+			modify: func(m *ModuleData) {
+				m.Variables["region"] = Variable{Type: "string", Default: "eu-west-1"}
+			},
+			wantKinds: []ChangeKind{ChangeVariableDefaultChanged},
+		},
+		{
+			name: "removed output",
+			modify: func(m *ModuleData) {
+				delete(m.Outputs, "id")
+			},
+			wantKinds:    []ChangeKind{ChangeOutputRemoved},
+			wantBreaking: true,
+		},
+		{
+			name: "added output",
+			modify: func(m *ModuleData) {
+				m.Outputs["arn"] = Output{}
+			},
+			wantKinds: []ChangeKind{ChangeOutputAdded},
+		},
+		{
+			name: "tightened provider constraint",
+			modify: func(m *ModuleData) {
+				m.Providers[0].VersionConstraint = ">= 5.0"
+			},
+			wantKinds:    []ChangeKind{ChangeProviderTightened},
+			wantBreaking: true,
+		},
+		{
+			name: "upper bound added",
+			modify: func(m *ModuleData) {
+				m.Providers[0].VersionConstraint = ">= 4.0, < 6.0"
+			},
+			wantKinds:    []ChangeKind{ChangeProviderTightened},
+			wantBreaking: true,
+		},
+		{
+			name: "relaxed provider constraint",
+			modify: func(m *ModuleData) {
+				m.Providers[0].VersionConstraint = ">= 3.0"
+			},
+			wantKinds: []ChangeKind{ChangeProviderRelaxed},
+		},
+		{
+			name: "added provider",
+			modify: func(m *ModuleData) {
+				m.Providers = append(m.Providers, Provider{Name: "random", FullName: "hashicorp/random"})
+			},
+			wantKinds: []ChangeKind{ChangeProviderAdded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := base()
+			tt.modify(current)
+
+			got := DiffModuleData("1.0.0", base(), "1.1.0", current)
+
+			if len(got.Changes) != len(tt.wantKinds) {
+				t.Fatalf("got %d changes %+v, want kinds %v", len(got.Changes), got.Changes, tt.wantKinds)
+			}
+			for i, kind := range tt.wantKinds {
+				if got.Changes[i].Kind != kind {
+					t.Errorf("change %d: got kind %s, want %s", i, got.Changes[i].Kind, kind)
+				}
+			}
+			if got.Breaking != tt.wantBreaking {
+				t.Errorf("got breaking %v, want %v", got.Breaking, tt.wantBreaking)
+			}
+		})
+	}
+}
+
+func TestPreviousVersion(t *testing.T) {
+	versions := []string{"v1.0.0", "v1.2.0", "v1.10.0", "v2.0.0-beta1", "not-a-version"}
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "v1.0.0", want: ""},
+		{version: "v1.3.0", want: "v1.2.0"},
+		{version: "v1.11.0", want: "v1.10.0"},
+		{version: "v2.0.0", want: "v2.0.0-beta1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := PreviousVersion(versions, tt.version); got != tt.want {
+				t.Errorf("PreviousVersion(%s) = %q, want %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionsToPublish(t *testing.T) {
+	stored := map[string][]byte{"v1.0.0": nil, "v1.1.0": nil, "v1.2.0": nil, "v2.0.0": nil}
+
+	tests := []struct {
+		name     string
+		versions []string
+		want     []string
+	}{
+		{name: "latest version", versions: []string{"v2.0.0"}, want: []string{"v2.0.0"}},
+		{name: "version in the middle", versions: []string{"v1.1.0"}, want: []string{"v1.1.0", "v1.2.0"}},
+		{name: "adjacent versions", versions: []string{"v1.0.0", "v1.1.0"}, want: []string{"v1.0.0", "v1.1.0", "v1.2.0"}},
+		// A removed version is no longer stored, its successor is compared against the version before it
+		{name: "removed version", versions: []string{"v1.3.0"}, want: []string{"v2.0.0"}},
+		{name: "removed latest version", versions: []string{"v3.0.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := versionsToPublish(stored, tt.versions)
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("versionsToPublish(%v) = %v, want %v", tt.versions, got, tt.want)
+			}
+		})
+	}
+}
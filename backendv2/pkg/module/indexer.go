@@ -7,7 +7,6 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
-	"strings"
 	"sync"
 	"time"
 
@@ -16,14 +15,17 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/opentofu/registry-ui/pkg/blocklist"
 	"github.com/opentofu/registry-ui/pkg/git"
 	"github.com/opentofu/registry-ui/pkg/index"
 	"github.com/opentofu/registry-ui/pkg/license"
 	"github.com/opentofu/registry-ui/pkg/module/storage"
 	"github.com/opentofu/registry-ui/pkg/registry"
 	"github.com/opentofu/registry-ui/pkg/repository"
+	"github.com/opentofu/registry-ui/pkg/scrapeerr"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
 	"github.com/opentofu/registry-ui/pkg/tofu"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 // IndexVersion indexes a specific version of a module
@@ -51,14 +53,29 @@ func (r *Reader) IndexVersion(ctx context.Context, namespace, name, target, vers
 		return nil, fmt.Errorf("version %s not found for module in the registry: %s/%s/%s", version, namespace, name, target)
 	}
 
+	// Blocked modules are recorded as skipped without checking out or scraping anything
+	blocked, blockedReason, err := blocklist.IsModuleBlocked(ctx, r.db, namespace, name, target)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to check blocklist: %w", err)
+	}
+	if blocked {
+		span.SetAttributes(
+			attribute.String("module.skip_reason", blocklist.SkipReason),
+			attribute.Bool("module.version_skipped", true),
+		)
+		return r.storeBlockedVersion(ctx, namespace, name, target, version, blockedReason)
+	}
+
 	// Checkout the version for processing
 	var workDir string
 	var cleanup func()
-	workDir, cleanup, err := r.CheckoutVersionForScraping(ctx, namespace, name, target, version)
+	workDir, cleanup, err = r.CheckoutVersionForScraping(ctx, namespace, name, target, version)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to checkout version: %w", err)
+		return nil, scrapeerr.Checkout(fmt.Errorf("failed to checkout version: %w", err))
 	}
 	defer cleanup()
 
@@ -68,7 +85,7 @@ func (r *Reader) IndexVersion(ctx context.Context, namespace, name, target, vers
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to detect licenses: %w", err)
+		return nil, scrapeerr.Wrap(scrapeerr.LicenseDetection, fmt.Errorf("failed to detect licenses: %w", err))
 	}
 
 	// Determine if version should be skipped due to license issues
@@ -129,19 +146,19 @@ func (r *Reader) IndexVersion(ctx context.Context, namespace, name, target, vers
 		moduleData = &collectedData.ModuleData
 
 		// Store registryModule data in S3 and capture checksum
-		indexChecksum, err = storage.StoreModuleInS3(ctx, r.uploader, r.config.Bucket.BucketName, namespace, name, target, version, moduleData)
+		indexChecksum, err = storage.StoreModuleInS3(ctx, r.store, namespace, name, target, version, moduleData)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
-			return nil, fmt.Errorf("failed to store registryModule in S3: %w", err)
+			return nil, scrapeerr.Wrap(scrapeerr.Upload, fmt.Errorf("failed to store registryModule in S3: %w", err))
 		}
 
 		// Store registryModule README in S3 and capture checksum
-		readmeChecksum, err = storage.StoreModuleREADME(ctx, r.uploader, r.config.Bucket.BucketName, namespace, name, target, version, workDir)
+		readmeChecksum, err = storage.StoreModuleREADME(ctx, r.store, namespace, name, target, version, workDir)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
-			return nil, fmt.Errorf("failed to store registryModule README in S3: %w", err)
+			return nil, scrapeerr.Wrap(scrapeerr.Upload, fmt.Errorf("failed to store registryModule README in S3: %w", err))
 		}
 	} else {
 		// Create minimal empty module data for skipped versions
@@ -155,7 +172,7 @@ func (r *Reader) IndexVersion(ctx context.Context, namespace, name, target, vers
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to start transaction: %w", err))
 	}
 	defer tx.Rollback(ctx)
 
@@ -168,11 +185,11 @@ func (r *Reader) IndexVersion(ctx context.Context, namespace, name, target, vers
 	}
 
 	// Store registryModule version in database (always store, even if skipped)
-	err = storage.StoreModuleVersion(ctx, tx, namespace, name, target, version, moduleData, tagCreatedAt, scrapeStatus, skipReason, "", indexChecksum, readmeChecksum)
+	err = storage.StoreModuleVersion(ctx, tx, namespace, name, target, version, moduleData, tagCreatedAt, scrapeStatus, skipReason, "", "", indexChecksum, readmeChecksum)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to store registryModule version: %w", err)
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store registryModule version: %w", err))
 	}
 
 	// Only store submodules and examples if not skipped
@@ -200,7 +217,7 @@ func (r *Reader) IndexVersion(ctx context.Context, namespace, name, target, vers
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
-			return nil, fmt.Errorf("failed to store module licenses: %w", err)
+			return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store module licenses: %w", err))
 		}
 	}
 
@@ -209,8 +226,9 @@ func (r *Reader) IndexVersion(ctx context.Context, namespace, name, target, vers
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to commit transaction: %w", err))
 	}
+	telemetry.RecordVersionIndexed(ctx, "module", scrapeStatus, skipReason)
 
 	// Count licenses from registryModule data
 	licensesCount := len(moduleData.Licenses)
@@ -264,12 +282,31 @@ func (r *Reader) IndexAllVersions(ctx context.Context, registryModule *registry.
 		return nil, fmt.Errorf("failed to get existing versions: %w", err)
 	}
 
+	// Versions that disappeared from the registry are no longer published
+	removedVersions, err := r.handleRemovedVersions(ctx, namespace, name, target, existingVersions, allVersions)
+	if err != nil {
+		span.RecordError(err)
+		slog.WarnContext(ctx, "Failed to handle versions removed from the registry",
+			"module", fmt.Sprintf("%s/%s/%s", namespace, name, target), "error", err)
+	}
+
 	// Convert to map for fast lookup
 	existingSet := make(map[string]bool)
 	for _, version := range existingVersions {
 		existingSet[version] = true
 	}
 
+	// Failed versions are scraped again once their retry backoff has passed
+	retryVersions, err := r.retryableVersions(ctx, namespace, name, target)
+	if err != nil {
+		span.RecordError(err)
+		slog.WarnContext(ctx, "Failed to get failed versions to retry",
+			"module", fmt.Sprintf("%s/%s/%s", namespace, name, target), "error", err)
+	}
+	for _, version := range retryVersions {
+		delete(existingSet, version)
+	}
+
 	// Filter to only versions that need processing
 	var versionsToProcess []string
 	var skippedVersions []string
@@ -316,11 +353,14 @@ func (r *Reader) IndexAllVersions(ctx context.Context, registryModule *registry.
 	if len(versionsToProcess) == 0 {
 		slog.DebugContext(ctx, "No versions to process, all versions already exist",
 			"module", fmt.Sprintf("%s/%s/%s", namespace, name, target))
+		if len(removedVersions) > 0 {
+			r.regenerateModuleVersionIndex(ctx, namespace, name, target)
+		}
 		return responses, nil
 	}
 
 	// Pre-clone and fetch tags before parallel processing to avoid race conditions
-	repoURL := fmt.Sprintf("https://github.com/%s/terraform-%s-%s", namespace, target, name)
+	repoURL := r.vcsHost.CloneURL(vcs.ModuleRepo(namespace, name, target))
 	localPath := fmt.Sprintf("%s/modules/%s/%s/%s", r.config.WorkDir, namespace, target, name)
 
 	slog.DebugContext(ctx, "Preparing module repository for parallel processing",
@@ -354,8 +394,8 @@ func (r *Reader) IndexAllVersions(ctx context.Context, registryModule *registry.
 
 	// Store repository and module ONCE before parallel version processing
 	// This eliminates row lock contention between concurrent versions
-	repoOrganisation := namespace
-	repoName := fmt.Sprintf("terraform-%s-%s", target, name)
+	moduleRepo := vcs.ModuleRepo(namespace, name, target)
+	repoOrganisation, repoName := moduleRepo.Owner, moduleRepo.Name
 
 	if err := storage.StoreRepository(ctx, r.db, repoOrganisation, repoName); err != nil {
 		span.RecordError(err)
@@ -398,14 +438,16 @@ func (r *Reader) IndexAllVersions(ctx context.Context, registryModule *registry.
 			// Pass the module data to avoid redundant file reads
 			response, err := r.IndexVersion(versionCtx, namespace, name, target, version, registryModule)
 			if err != nil {
+				errorClass := scrapeerr.ClassOf(err)
 				versionSpan.RecordError(err)
 				versionSpan.SetStatus(codes.Error, err.Error())
-				slog.WarnContext(versionCtx, "Failed to index version, storing as failed to prevent retry",
+				versionSpan.SetAttributes(attribute.String("module.error_class", string(errorClass)))
+				slog.WarnContext(versionCtx, "Failed to index version, storing as failed",
 					"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
-					"version", version, "error", err)
+					"version", version, "error", err, "error_class", errorClass)
 
 				// Store failed version in database to prevent re-scraping
-				storeErr := r.storeFailedVersion(versionCtx, namespace, name, target, version, err.Error(), registryModule)
+				storeErr := r.storeFailedVersion(versionCtx, namespace, name, target, version, err, registryModule)
 				if storeErr != nil {
 					slog.ErrorContext(versionCtx, "Failed to store failed version record",
 						"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
@@ -424,6 +466,7 @@ func (r *Reader) IndexAllVersions(ctx context.Context, registryModule *registry.
 					ProcessedAt:  time.Now(),
 					Success:      false,
 					ErrorMessage: err.Error(),
+					ErrorClass:   string(errorClass),
 				}
 			}
 
@@ -450,16 +493,16 @@ func (r *Reader) IndexAllVersions(ctx context.Context, registryModule *registry.
 			"failed_versions", failedVersions)
 	}
 
-	// Sync repository metadata from GitHub (stats, fork info, etc.)
-	if r.githubClient != nil {
+	// Sync repository metadata from the VCS host (stats, fork info, etc.)
+	if r.metadataSource != nil {
 		// Extract repository information from module source
-		repoOrganisation, repoName := parseGitHubURL(registryModule.Source)
-		if repoOrganisation != "" && repoName != "" {
-			slog.DebugContext(ctx, "Syncing repository metadata from GitHub",
+		if sourceRepo, ok := r.vcsHost.ParseURL(registryModule.Source); ok {
+			repoOrganisation, repoName := sourceRepo.Owner, sourceRepo.Name
+			slog.DebugContext(ctx, "Syncing repository metadata",
 				"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
 				"repository", fmt.Sprintf("%s/%s", repoOrganisation, repoName))
 
-			err := repository.SyncRepositoryMetadata(ctx, r.db, r.githubClient, repoOrganisation, repoName)
+			err := repository.SyncRepositoryMetadata(ctx, r.db, r.metadataSource, repoOrganisation, repoName)
 			if err != nil {
 				slog.ErrorContext(ctx, "Failed to sync repository metadata",
 					"error", err,
@@ -470,32 +513,50 @@ func (r *Reader) IndexAllVersions(ctx context.Context, registryModule *registry.
 		}
 	}
 
+	// Publish changes.json relative to the previous version for everything indexed in this run
+	var indexedVersions []string
+	for _, response := range responses {
+		if response.Success {
+			indexedVersions = append(indexedVersions, response.Version)
+		}
+	}
+	if err := PublishVersionChanges(ctx, r.db, r.store, namespace, name, target, indexedVersions); err != nil {
+		slog.WarnContext(ctx, "Failed to publish module version changes",
+			"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
+			"error", err)
+	}
+
+	r.regenerateModuleVersionIndex(ctx, namespace, name, target)
+
+	return responses, nil
+}
+
+// regenerateModuleVersionIndex generates and uploads the index.json of a module from the database
+func (r *Reader) regenerateModuleVersionIndex(ctx context.Context, namespace, name, target string) {
 	slog.DebugContext(ctx, "Generating module version index",
 		"module", fmt.Sprintf("%s/%s/%s", namespace, name, target))
 
-	moduleIndex, err := index.GenerateModuleVersionIndex(ctx, r.db, namespace, name, target)
+	moduleIndex, err := index.GenerateModuleVersionIndex(ctx, r.db, r.vcsHost, namespace, name, target)
 	if err != nil {
 		slog.WarnContext(ctx, "Failed to generate module version index",
 			"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
 			"error", err)
-	} else {
-		// Upload module version index to S3
-		err = index.UploadModuleVersionIndex(ctx, r.uploader, r.config.Bucket.BucketName, moduleIndex)
-		if err != nil {
-			slog.WarnContext(ctx, "Failed to upload module version index",
-				"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
-				"error", err)
-		} else {
-			slog.DebugContext(ctx, "Successfully uploaded module version index",
-				"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
-				"versions", len(moduleIndex.Versions))
+		return
+	}
 
-			// Note: Global module index is NOT updated here to avoid race conditions.
-			// Use the `rebuild-global-indexes` command to rebuild it from the database.
-		}
+	err = index.UploadModuleVersionIndex(ctx, r.store, moduleIndex)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to upload module version index",
+			"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
+			"error", err)
+		return
 	}
 
-	return responses, nil
+	// Note: Global module index is NOT updated here to avoid race conditions.
+	// Use the `rebuild-global-indexes` command to rebuild it from the database.
+	slog.DebugContext(ctx, "Successfully uploaded module version index",
+		"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
+		"versions", len(moduleIndex.Versions))
 }
 
 // CollectedModuleData holds all collected module data for storage
@@ -510,7 +571,7 @@ func (r *Reader) buildCompleteModuleData(ctx context.Context, namespace, name, t
 	ctx, span := telemetry.Tracer().Start(ctx, "module.build_complete_module_data")
 	defer span.End()
 
-	parser := NewModuleParser(workDir, namespace, name, target, version, publishedAt)
+	parser := NewModuleParser(r.vcsHost, workDir, namespace, name, target, version, publishedAt)
 
 	// Collect root module, submodules, and examples in parallel
 	var rootModuleData *tofu.Config
@@ -523,7 +584,7 @@ func (r *Reader) buildCompleteModuleData(ctx context.Context, namespace, name, t
 
 	g.Go(func() error {
 		rootModuleData, rootSchemaError, rootErr = tofu.Show(gctx, workDir)
-		return rootErr
+		return scrapeerr.Show(rootErr)
 	})
 
 	g.Go(func() error {
@@ -608,7 +669,7 @@ func (r *Reader) collectSubmodulesDataParallel(ctx context.Context, namespace, n
 			}
 
 			// Create parser and transform the raw tofu config
-			parser := NewModuleParser(workDir, namespace, name, target, version, nil)
+			parser := NewModuleParser(r.vcsHost, workDir, namespace, name, target, version, nil)
 			submoduleData, err := parser.BuildSubmoduleData(gctx, submoduleName, tofuConfig, schemaError)
 			if err != nil {
 				slog.WarnContext(gctx, "Failed to transform submodule data",
@@ -690,7 +751,7 @@ func (r *Reader) collectExamplesDataParallel(ctx context.Context, namespace, nam
 			}
 
 			// Create parser and transform the raw tofu config
-			parser := NewModuleParser(workDir, namespace, name, target, version, nil)
+			parser := NewModuleParser(r.vcsHost, workDir, namespace, name, target, version, nil)
 			exampleData, err := parser.BuildExampleData(gctx, exampleName, tofuConfig, schemaError)
 			if err != nil {
 				slog.WarnContext(gctx, "Failed to transform example data",
@@ -747,11 +808,11 @@ func (r *Reader) storeSubmodulesWithTx(ctx context.Context, tx pgx.Tx, namespace
 			submodulePath := filepath.Join("modules", submoduleName)
 
 			// Store submodule data in S3 and capture checksums
-			indexChecksum, readmeChecksum, err := storage.StoreModuleSubmoduleInS3(gctx, r.uploader, r.config.Bucket.BucketName, namespace, name, target, version, submoduleName, submoduleData, workDir)
+			indexChecksum, readmeChecksum, err := storage.StoreModuleSubmoduleInS3(gctx, r.store, namespace, name, target, version, submoduleName, submoduleData, workDir)
 			if err != nil {
 				slog.ErrorContext(gctx, "Failed to store submodule in S3",
 					"submodule", submoduleName, "error", err)
-				return fmt.Errorf("failed to store submodule %s in S3: %w", submoduleName, err)
+				return scrapeerr.Wrap(scrapeerr.Upload, fmt.Errorf("failed to store submodule %s in S3: %w", submoduleName, err))
 			}
 
 			txMu.Lock()
@@ -760,7 +821,7 @@ func (r *Reader) storeSubmodulesWithTx(ctx context.Context, tx pgx.Tx, namespace
 			if err != nil {
 				slog.ErrorContext(gctx, "Failed to store submodule in database",
 					"submodule", submoduleName, "error", err)
-				return fmt.Errorf("failed to store submodule %s: %w", submoduleName, err)
+				return scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store submodule %s: %w", submoduleName, err))
 			}
 
 			slog.DebugContext(gctx, "Successfully stored submodule",
@@ -809,11 +870,11 @@ func (r *Reader) storeExamplesWithTx(ctx context.Context, tx pgx.Tx, namespace,
 			examplePath := filepath.Join("examples", exampleName)
 
 			// Store example data in S3 and capture checksums
-			indexChecksum, readmeChecksum, err := storage.StoreModuleExampleInS3(gctx, r.uploader, r.config.Bucket.BucketName, namespace, name, target, version, exampleName, exampleData, workDir)
+			indexChecksum, readmeChecksum, err := storage.StoreModuleExampleInS3(gctx, r.store, namespace, name, target, version, exampleName, exampleData, workDir)
 			if err != nil {
 				slog.ErrorContext(gctx, "Failed to store example in S3",
 					"example", exampleName, "error", err)
-				return fmt.Errorf("failed to store example %s in S3: %w", exampleName, err)
+				return scrapeerr.Wrap(scrapeerr.Upload, fmt.Errorf("failed to store example %s in S3: %w", exampleName, err))
 			}
 
 			txMu.Lock()
@@ -822,7 +883,7 @@ func (r *Reader) storeExamplesWithTx(ctx context.Context, tx pgx.Tx, namespace,
 			if err != nil {
 				slog.ErrorContext(gctx, "Failed to store example in database",
 					"example", exampleName, "error", err)
-				return fmt.Errorf("failed to store example %s: %w", exampleName, err)
+				return scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store example %s: %w", exampleName, err))
 			}
 
 			slog.DebugContext(gctx, "Successfully stored example",
@@ -844,46 +905,75 @@ func (r *Reader) storeExamplesWithTx(ctx context.Context, tx pgx.Tx, namespace,
 	return nil
 }
 
-// parseGitHubURL extracts organization and repository name from a GitHub URL
-// Expected format: https://github.com/{org}/terraform-{target}-module-{name}
-func parseGitHubURL(gitHubURL string) (organisation, repoName string) {
-	// Remove https://github.com/ prefix
-	if after, ok := strings.CutPrefix(gitHubURL, "https://github.com/"); ok {
-		prefix := after
-		parts := strings.Split(prefix, "/")
-		if len(parts) >= 2 {
-			return parts[0], parts[1]
-		}
+// storeBlockedVersion records a version of a blocked module as skipped so it is neither scraped nor retried
+func (r *Reader) storeBlockedVersion(ctx context.Context, namespace, name, target, version, reason string) (*IndexResponse, error) {
+	slog.WarnContext(ctx, "Module is blocked, will store with skipped status",
+		"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
+		"version", version,
+		"reason", reason)
+
+	tagCreatedAt, err := r.GetTagCreationDate(ctx, namespace, name, target, version)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get tag creation date",
+			"registryModule", fmt.Sprintf("%s/%s/%s", namespace, name, target),
+			"version", version,
+			"error", err)
+		tagCreatedAt = nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to start transaction: %w", err))
+	}
+	defer tx.Rollback(ctx)
+
+	err = storage.StoreModuleVersion(ctx, tx, namespace, name, target, version, &ModuleData{}, tagCreatedAt, "skipped", blocklist.SkipReason, reason, "", "", "")
+	if err != nil {
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store registryModule version: %w", err))
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to commit transaction: %w", err))
 	}
+	telemetry.RecordVersionIndexed(ctx, "module", "skipped", blocklist.SkipReason)
 
-	// Fallback: return empty strings if URL format is unexpected
-	return "", ""
+	return &IndexResponse{
+		Namespace:   namespace,
+		Name:        name,
+		Target:      target,
+		Version:     version,
+		ProcessedAt: time.Now(),
+		Success:     true,
+	}, nil
 }
 
-// storeFailedVersion stores a minimal version record with status='failed' to prevent re-scraping
+// storeFailedVersion stores a minimal version record with status='failed' and the class of indexErr, failed versions
+// are only scraped again by the retry policy
 // Note: Repository and module records are stored BEFORE parallel processing in IndexAllVersions
 // registryModule parameter must be provided by the caller to avoid redundant file reads
-func (r *Reader) storeFailedVersion(ctx context.Context, namespace, name, target, version, errorMessage string, registryModule *registry.Module) error {
+func (r *Reader) storeFailedVersion(ctx context.Context, namespace, name, target, version string, indexErr error, registryModule *registry.Module) error {
 	// Start database transaction
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
+		return scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to start transaction: %w", err))
 	}
 	defer tx.Rollback(ctx)
 
 	// Store module version with status='failed' and error message (no checksums for failed versions)
-	err = storage.StoreModuleVersion(ctx, tx, namespace, name, target, version, &ModuleData{}, nil, "failed", "processing_error", errorMessage, "", "")
+	err = storage.StoreModuleVersion(ctx, tx, namespace, name, target, version, &ModuleData{}, nil, "failed", "processing_error", indexErr.Error(), string(scrapeerr.ClassOf(indexErr)), "", "")
 	if err != nil {
-		return fmt.Errorf("failed to store module version: %w", err)
+		return scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store module version: %w", err))
 	}
 
 	// Commit transaction
 	err = tx.Commit(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to commit transaction: %w", err))
 	}
+	telemetry.RecordVersionIndexed(ctx, "module", "failed", string(scrapeerr.ClassOf(indexErr)))
 
-	slog.InfoContext(ctx, "Stored failed version record to prevent re-scraping",
+	slog.InfoContext(ctx, "Stored failed version record, it is retried according to the retry policy",
 		"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
 		"version", version)
 
@@ -9,11 +9,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/opentofu/registry-ui/pkg/bucket"
 	"github.com/opentofu/registry-ui/pkg/config"
 	"github.com/opentofu/registry-ui/pkg/git"
 	"github.com/opentofu/registry-ui/pkg/license"
@@ -22,33 +21,38 @@ import (
 	"github.com/opentofu/registry-ui/pkg/repository"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
 	"github.com/opentofu/registry-ui/pkg/tofu"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 // Reader is the main entry point for all module operations
 type Reader struct {
 	config       *config.BackendConfig
 	db           *pgxpool.Pool
-	s3Client     *s3.Client
-	uploader     *manager.Uploader
+	store        bucket.Store
 	githubClient *repository.Client
 	tofuPath     string
+
+	vcsHost        vcs.Host
+	metadataSource repository.MetadataSource
 }
 
 // NewModuleReader creates a new Reader with all dependencies initialized
 func NewModuleReader(ctx context.Context, cfg *config.BackendConfig, db *pgxpool.Pool) (*Reader, error) {
-	// Initialize S3 client
-	s3Client, err := cfg.Bucket.GetClient(ctx)
+	// Initialize the storage for generated files
+	store, err := bucket.New(ctx, &cfg.Bucket)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize S3 client: %w", err)
+		return nil, fmt.Errorf("failed to initialize bucket storage: %w", err)
 	}
 
-	// Initialize S3 uploader
-	uploader := manager.NewUploader(s3Client)
+	vcsHost, err := vcs.New(cfg.VCS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize VCS host: %w", err)
+	}
 
-	// Initialize GitHub client if configured
+	// Initialize GitHub client if configured, it is only used for license detection on GitHub hosted repositories
 	var githubClient *repository.Client
-	if cfg.GitHub.Token != "" {
-		githubClient = repository.NewClient(ctx, &cfg.GitHub)
+	if cfg.GitHub.Configured() && vcsHost.Type() == config.VCSTypeGitHub {
+		githubClient = repository.NewClient(ctx, &cfg.GitHub, cfg.VCS.GitHubAPIURL(), db)
 	}
 
 	// Ensure the tofu binary exists
@@ -66,11 +70,13 @@ func NewModuleReader(ctx context.Context, cfg *config.BackendConfig, db *pgxpool
 	return &Reader{
 		config:       cfg,
 		db:           db,
-		s3Client:     s3Client,
-		uploader:     uploader,
+		store:        store,
 		githubClient: githubClient,
 
 		tofuPath: tofuPath,
+
+		vcsHost:        vcsHost,
+		metadataSource: repository.NewMetadataSource(ctx, cfg, db),
 	}, nil
 }
 
@@ -160,7 +166,7 @@ func (r *Reader) ScrapeAllVersions(ctx context.Context, module *registry.Module)
 
 // GetTagCreationDate returns the creation date of a git tag for a module
 func (r *Reader) GetTagCreationDate(ctx context.Context, namespace, name, target, version string) (*time.Time, error) {
-	repoURL := fmt.Sprintf("https://github.com/%s/terraform-%s-%s", namespace, target, name)
+	repoURL := r.vcsHost.CloneURL(vcs.ModuleRepo(namespace, name, target))
 	localPath := fmt.Sprintf("%s/modules/%s/%s/%s", r.config.WorkDir, namespace, target, name)
 
 	repo, err := git.GetRepo(repoURL, localPath)
@@ -173,7 +179,7 @@ func (r *Reader) GetTagCreationDate(ctx context.Context, namespace, name, target
 
 // CheckoutVersionForScraping creates a worktree for a module tag and returns the directory path and cleanup function
 func (r *Reader) CheckoutVersionForScraping(ctx context.Context, namespace, name, target, tag string) (string, func(), error) {
-	repoURL := fmt.Sprintf("https://github.com/%s/terraform-%s-%s", namespace, target, name)
+	repoURL := r.vcsHost.CloneURL(vcs.ModuleRepo(namespace, name, target))
 	localPath := fmt.Sprintf("%s/modules/%s/%s/%s", r.config.WorkDir, namespace, target, name)
 
 	return git.CheckoutVersionForScraping(ctx, repoURL, localPath, tag)
@@ -194,17 +200,14 @@ func (r *Reader) DetectLicensesInDirectory(ctx context.Context, namespace, name,
 	slog.DebugContext(ctx, "Starting license detection in module directory", "directory", directory)
 
 	// Create license detector
-	detector, err := license.New(r.config.License, r.githubClient)
+	detector, err := license.New(r.config.License, r.githubClient, r.vcsHost)
 	if err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to create license detector: %w", err)
 	}
 
-	// Generate repo URL for context
-	repoURL := fmt.Sprintf("https://github.com/%s/terraform-%s-%s", namespace, target, name)
-
 	// Detect licenses in the directory
-	licenses, err := detector.Detect(ctx, directory, repoURL)
+	licenses, err := detector.Detect(ctx, directory, vcs.ModuleRepo(namespace, name, target))
 	if err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to detect licenses in directory %s: %w", directory, err)
@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"time"
 
 	"github.com/opentofu/registry-ui/pkg/license"
 	"github.com/opentofu/registry-ui/pkg/tofu"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 // Parser handles parsing and transformation of module data
 type Parser struct {
+	host        vcs.Host
 	workDir     string
 	namespace   string
 	name        string
@@ -23,8 +26,9 @@ type Parser struct {
 }
 
 // NewModuleParser creates a new module parser
-func NewModuleParser(workDir, namespace, name, target, version string, publishedAt *time.Time) *Parser {
+func NewModuleParser(host vcs.Host, workDir, namespace, name, target, version string, publishedAt *time.Time) *Parser {
 	return &Parser{
+		host:        host,
 		workDir:     workDir,
 		namespace:   namespace,
 		name:        name,
@@ -204,34 +208,32 @@ func (p *Parser) transformTofuShowOutput(tofuData *tofu.Config, schemaError stri
 	}, nil
 }
 
+func (p *Parser) repo() vcs.Repo {
+	return vcs.ModuleRepo(p.namespace, p.name, p.target)
+}
+
 func (p *Parser) buildEditLink() string {
-	return fmt.Sprintf("https://github.com/%s/terraform-%s-%s/blob/%s/README.md",
-		p.namespace, p.target, p.name, p.version)
+	return p.host.BlobURL(p.repo(), p.version, "README.md")
 }
 
 func (p *Parser) buildRepoLink() string {
-	return fmt.Sprintf("https://github.com/%s/terraform-%s-%s/tree/%s",
-		p.namespace, p.target, p.name, p.version)
+	return p.host.TreeURL(p.repo(), p.version, "")
 }
 
 func (p *Parser) buildVCSRepository() string {
-	return fmt.Sprintf("https://github.com/%s/terraform-%s-%s",
-		p.namespace, p.target, p.name)
+	return p.host.RepoURL(p.repo())
 }
 
 func (p *Parser) buildSubmoduleEditLink(submoduleName string) string {
-	return fmt.Sprintf("https://github.com/%s/terraform-%s-%s/blob/%s/modules/%s/README.md",
-		p.namespace, p.target, p.name, p.version, submoduleName)
+	return p.host.BlobURL(p.repo(), p.version, path.Join("modules", submoduleName, "README.md"))
 }
 
 func (p *Parser) buildExampleEditLink(exampleName string) string {
-	return fmt.Sprintf("https://github.com/%s/terraform-%s-%s/blob/%s/examples/%s/README.md",
-		p.namespace, p.target, p.name, p.version, exampleName)
+	return p.host.BlobURL(p.repo(), p.version, path.Join("examples", exampleName, "README.md"))
 }
 
 func (p *Parser) buildLicenseLink(fileName string) string {
-	return fmt.Sprintf("https://github.com/%s/terraform-%s-%s/blob/%s/%s",
-		p.namespace, p.target, p.name, p.version, fileName)
+	return p.host.BlobURL(p.repo(), p.version, fileName)
 }
 
 func (p *Parser) hasIncompatibleLicense(licenses []license.License) bool {
@@ -0,0 +1,67 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/module/storage"
+	"github.com/opentofu/registry-ui/pkg/registry"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// handleRemovedVersions marks or deletes (depending on removedVersions.action) the known versions of a module that
+// are no longer listed in the registry and returns them. The caller is responsible for regenerating the version index.
+func (r *Reader) handleRemovedVersions(ctx context.Context, namespace, name, target string, known, listed []string) ([]string, error) {
+	removed := registry.RemovedVersions(known, listed)
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	// A module without any versions is more likely a broken registry checkout than a takedown of every release
+	if len(listed) == 0 {
+		slog.WarnContext(ctx, "Registry lists no versions for module, not removing known versions",
+			"module", fmt.Sprintf("%s/%s/%s", namespace, name, target), "known_versions", len(known))
+		return nil, nil
+	}
+
+	ctx, span := telemetry.Tracer().Start(ctx, "module.handle_removed_versions")
+	defer span.End()
+
+	action := r.config.RemovedVersions.Action
+	span.SetAttributes(
+		attribute.String("module.namespace", namespace),
+		attribute.String("module.name", name),
+		attribute.String("module.target", target),
+		attribute.StringSlice("module.removed_versions", removed),
+		attribute.String("action", action),
+	)
+
+	if action == config.RemovedVersionsDelete {
+		if err := storage.DeleteModuleVersions(ctx, r.db, namespace, name, target, removed); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		for _, version := range removed {
+			prefix := fmt.Sprintf("modules/%s/%s/%s/%s/", namespace, name, target, version)
+			if _, err := bucket.DeletePrefix(ctx, r.store, prefix); err != nil {
+				// The version is no longer in the index, left over files can be cleaned up with the reconcile command
+				slog.WarnContext(ctx, "Failed to delete files of removed module version",
+					"module", fmt.Sprintf("%s/%s/%s", namespace, name, target), "version", version, "error", err)
+			}
+		}
+	} else {
+		if err := storage.MarkModuleVersionsRemoved(ctx, r.db, namespace, name, target, removed); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	slog.InfoContext(ctx, "Handled module versions removed from the registry",
+		"module", fmt.Sprintf("%s/%s/%s", namespace, name, target), "versions", removed, "action", action)
+	return removed, nil
+}
@@ -0,0 +1,53 @@
+package module
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/config"
+)
+
+func TestHandleRemovedVersionsSkips(t *testing.T) {
+	tests := []struct {
+		name   string
+		known  []string
+		listed []string
+	}{
+		{"registry lists no versions", []string{"v5.0.0", "v5.1.0"}, nil},
+		{"nothing removed", []string{"v5.0.0"}, []string{"v5.0.0", "v5.1.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := bucket.NewDir(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, version := range tt.known {
+				key := "modules/terraform-aws-modules/vpc/aws/" + version + "/index.json"
+				if err := store.Put(context.Background(), bucket.Object{Key: key, Body: []byte("{}")}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Without a database any removal would fail, so the versions must be left alone before touching it
+			r := &Reader{
+				config: &config.BackendConfig{RemovedVersions: config.RemovedVersionsConfig{Action: config.RemovedVersionsDelete}},
+				store:  store,
+			}
+			removed, err := r.handleRemovedVersions(context.Background(), "terraform-aws-modules", "vpc", "aws", tt.known, tt.listed)
+			if err != nil || len(removed) != 0 {
+				t.Errorf("handleRemovedVersions() = %v, %v, want no removed versions", removed, err)
+			}
+
+			objects, err := store.List(context.Background(), "modules/terraform-aws-modules/vpc/aws/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(objects) != len(tt.known) {
+				t.Errorf("%d files left in the bucket, want %d", len(objects), len(tt.known))
+			}
+		})
+	}
+}
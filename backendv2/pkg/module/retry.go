@@ -0,0 +1,37 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/opentofu/registry-ui/pkg/module/storage"
+	"github.com/opentofu/registry-ui/pkg/scrapeerr"
+)
+
+// retryableVersions returns the failed versions of a module whose error class is retryable and whose retry backoff
+// has passed, see config.RetryConfig
+func (r *Reader) retryableVersions(ctx context.Context, namespace, name, target string) ([]string, error) {
+	failed, err := storage.GetFailedModuleVersions(ctx, r.db, namespace, name, target)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var versions []string
+	for _, f := range failed {
+		// Failures that only a change in the repository fixes are left for retry-version
+		if !scrapeerr.Parse(f.ErrorClass).Retryable() || !r.config.Retry.Eligible(f.Attempts, f.LastAttemptAt, now) {
+			continue
+		}
+		slog.InfoContext(ctx, "Retrying failed module version",
+			"module", fmt.Sprintf("%s/%s/%s", namespace, name, target),
+			"version", f.Version,
+			"attempts", f.Attempts,
+			"error_class", f.ErrorClass,
+			"last_attempt_at", f.LastAttemptAt)
+		versions = append(versions, f.Version)
+	}
+	return versions, nil
+}
@@ -56,8 +56,9 @@ func StoreRepository(ctx context.Context, db Queryable, organisation, name strin
 	return nil
 }
 
-// StoreModuleVersion stores module version information in the database
-func StoreModuleVersion(ctx context.Context, tx pgx.Tx, namespace, name, target, version string, tofuJSON any, tagCreatedAt *time.Time, scrapeStatus, skipReason, errorMessage, indexChecksum, readmeChecksum string) error {
+// StoreModuleVersion stores module version information in the database. The attempt count grows with every failure
+// and is reset by any other status.
+func StoreModuleVersion(ctx context.Context, tx pgx.Tx, namespace, name, target, version string, tofuJSON any, tagCreatedAt *time.Time, scrapeStatus, skipReason, errorMessage, errorClass, indexChecksum, readmeChecksum string) error {
 	// Convert the moduleData to JSON
 	jsonData, err := json.Marshal(tofuJSON)
 	if err != nil {
@@ -65,8 +66,8 @@ func StoreModuleVersion(ctx context.Context, tx pgx.Tx, namespace, name, target,
 	}
 
 	query := `
-		INSERT INTO module_versions (module_namespace, module_name, module_target, version, tofu_json, processed_at, tag_created_at, scrape_status, skip_reason, error_message, last_attempt_at, index_md5_checksum, readme_md5_checksum)
-		VALUES ($1, $2, $3, $4, $5, NOW(), $6, $7, $8, $9, NOW(), $10, $11)
+		INSERT INTO module_versions (module_namespace, module_name, module_target, version, tofu_json, processed_at, tag_created_at, scrape_status, skip_reason, error_message, error_class, last_attempt_at, attempt_count, index_md5_checksum, readme_md5_checksum)
+		VALUES ($1, $2, $3, $4, $5, NOW(), $6, $7, $8, $9, NULLIF($10, ''), NOW(), CASE WHEN $7 = 'failed' THEN 1 ELSE 0 END, $11, $12)
 		ON CONFLICT (module_namespace, module_name, module_target, version)
 		DO UPDATE SET
 			tofu_json = EXCLUDED.tofu_json,
@@ -75,11 +76,13 @@ func StoreModuleVersion(ctx context.Context, tx pgx.Tx, namespace, name, target,
 			scrape_status = EXCLUDED.scrape_status,
 			skip_reason = EXCLUDED.skip_reason,
 			error_message = EXCLUDED.error_message,
+			error_class = EXCLUDED.error_class,
 			last_attempt_at = NOW(),
+			attempt_count = CASE WHEN EXCLUDED.scrape_status = 'failed' THEN module_versions.attempt_count + 1 ELSE 0 END,
 			index_md5_checksum = EXCLUDED.index_md5_checksum,
 			readme_md5_checksum = EXCLUDED.readme_md5_checksum`
 
-	_, err = tx.Exec(ctx, query, namespace, name, target, version, jsonData, tagCreatedAt, scrapeStatus, skipReason, errorMessage, indexChecksum, readmeChecksum)
+	_, err = tx.Exec(ctx, query, namespace, name, target, version, jsonData, tagCreatedAt, scrapeStatus, skipReason, errorMessage, errorClass, indexChecksum, readmeChecksum)
 	if err != nil {
 		return fmt.Errorf("failed to store module version: %w", err)
 	}
@@ -207,7 +210,8 @@ func StoreModuleVersionLicenses(ctx context.Context, tx pgx.Tx, namespace, name,
 }
 
 // GetExistingModuleVersions returns all versions that already exist in the database for a given module
-// Includes 'failed' status to avoid retrying failed versions on every run
+// Includes 'failed' status to avoid retrying failed versions on every run, see GetFailedModuleVersions for the
+// failed versions that are due for a retry
 func GetExistingModuleVersions(ctx context.Context, db Queryable, namespace, name, target string) ([]string, error) {
 	query := `
 		SELECT version
@@ -239,6 +243,47 @@ func GetExistingModuleVersions(ctx context.Context, db Queryable, namespace, nam
 	return versions, nil
 }
 
+// FailedVersion is a module version whose last scraping attempt failed
+type FailedVersion struct {
+	Namespace     string
+	Name          string
+	Target        string
+	Version       string
+	ErrorMessage  string
+	ErrorClass    string
+	Attempts      int
+	LastAttemptAt time.Time
+}
+
+// GetFailedModuleVersions returns the failed versions of a module, or of all modules when namespace is empty
+func GetFailedModuleVersions(ctx context.Context, db Queryable, namespace, name, target string) ([]FailedVersion, error) {
+	rows, err := db.Query(ctx, `
+		SELECT module_namespace, module_name, module_target, version, COALESCE(error_message, ''), COALESCE(error_class, ''), attempt_count,
+		       COALESCE(last_attempt_at, updated_at, NOW())
+		FROM module_versions
+		WHERE scrape_status = 'failed'
+		  AND ($1 = '' OR (module_namespace = $1 AND module_name = $2 AND module_target = $3))
+		ORDER BY module_namespace, module_name, module_target, version`, namespace, name, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failed module versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []FailedVersion
+	for rows.Next() {
+		var v FailedVersion
+		if err := rows.Scan(&v.Namespace, &v.Name, &v.Target, &v.Version, &v.ErrorMessage, &v.ErrorClass, &v.Attempts, &v.LastAttemptAt); err != nil {
+			return nil, fmt.Errorf("failed to scan failed module version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over failed module versions: %w", err)
+	}
+
+	return versions, nil
+}
+
 // UpdateModuleVersionStatus updates the scraping status of a module version
 func UpdateModuleVersionStatus(ctx context.Context, db Queryable, namespace, name, target, version, status, skipReason, errorMessage string) error {
 	query := `
@@ -246,6 +291,7 @@ func UpdateModuleVersionStatus(ctx context.Context, db Queryable, namespace, nam
 		SET scrape_status = $5,
 		    skip_reason = $6,
 		    error_message = $7,
+		    error_class = CASE WHEN $5 = 'failed' THEN error_class END,
 		    last_attempt_at = NOW(),
 		    updated_at = NOW()
 		WHERE module_namespace = $1
@@ -264,3 +310,67 @@ func UpdateModuleVersionStatus(ctx context.Context, db Queryable, namespace, nam
 
 	return nil
 }
+
+// MarkModuleVersionsRemoved sets the 'removed' status on versions that are no longer listed in the registry
+func MarkModuleVersionsRemoved(ctx context.Context, db Queryable, namespace, name, target string, versions []string) error {
+	_, err := db.Exec(ctx, `
+		UPDATE module_versions
+		SET scrape_status = 'removed',
+		    updated_at = NOW()
+		WHERE module_namespace = $1
+		  AND module_name = $2
+		  AND module_target = $3
+		  AND version = ANY($4)`, namespace, name, target, versions)
+	if err != nil {
+		return fmt.Errorf("failed to mark module versions as removed: %w", err)
+	}
+	return nil
+}
+
+// DeleteModuleVersions deletes versions and their related records (submodules, examples, licenses)
+func DeleteModuleVersions(ctx context.Context, db Queryable, namespace, name, target string, versions []string) error {
+	_, err := db.Exec(ctx, `
+		DELETE FROM module_versions
+		WHERE module_namespace = $1
+		  AND module_name = $2
+		  AND module_target = $3
+		  AND version = ANY($4)`, namespace, name, target, versions)
+	if err != nil {
+		return fmt.Errorf("failed to delete module versions: %w", err)
+	}
+	return nil
+}
+
+// GetCompletedModuleVersionData returns the stored tofu JSON of every completed version of a module, keyed by version
+func GetCompletedModuleVersionData(ctx context.Context, db Queryable, namespace, name, target string) (map[string][]byte, error) {
+	query := `
+		SELECT version, tofu_json
+		FROM module_versions
+		WHERE module_namespace = $1
+		  AND module_name = $2
+		  AND module_target = $3
+		  AND scrape_status = 'completed'
+		  AND tofu_json IS NOT NULL`
+
+	rows, err := db.Query(ctx, query, namespace, name, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query module version data: %w", err)
+	}
+	defer rows.Close()
+
+	data := make(map[string][]byte)
+	for rows.Next() {
+		var version string
+		var tofuJSON []byte
+		if err := rows.Scan(&version, &tofuJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan module version data: %w", err)
+		}
+		data[version] = tofuJSON
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over module version data: %w", err)
+	}
+
+	return data, nil
+}
@@ -1,7 +1,6 @@
 package storage
 
 import (
-	"bytes"
 	"context"
 	"crypto/md5"
 	"encoding/hex"
@@ -11,16 +10,14 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/opentofu/registry-ui/pkg/bucket"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
 )
 
 // StoreModuleInS3 stores module data in S3 and returns the MD5 checksum
-func StoreModuleInS3(ctx context.Context, uploader *manager.Uploader, bucketName, namespace, name, target, version string, moduleData any) (string, error) {
+func StoreModuleInS3(ctx context.Context, store bucket.Store, namespace, name, target, version string, moduleData any) (string, error) {
 	// Convert module data to JSON
 	jsonData, err := json.MarshalIndent(moduleData, "", "  ")
 	if err != nil {
@@ -28,7 +25,7 @@ func StoreModuleInS3(ctx context.Context, uploader *manager.Uploader, bucketName
 	}
 
 	key := fmt.Sprintf("modules/%s/%s/%s/%s/index.json", namespace, name, target, version)
-	md5Hash, err := uploadToS3(ctx, uploader, bucketName, key, jsonData, "application/json")
+	md5Hash, err := upload(ctx, store, key, jsonData, "application/json")
 	if err != nil {
 		return "", fmt.Errorf("failed to upload module index.json: %w", err)
 	}
@@ -42,8 +39,23 @@ func StoreModuleInS3(ctx context.Context, uploader *manager.Uploader, bucketName
 	return md5Hash, nil
 }
 
+// StoreModuleChangesInS3 stores the changes of a module version relative to its previous version as changes.json
+func StoreModuleChangesInS3(ctx context.Context, store bucket.Store, namespace, name, target, version string, changes any) error {
+	jsonData, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal module changes: %w", err)
+	}
+
+	key := fmt.Sprintf("modules/%s/%s/%s/%s/changes.json", namespace, name, target, version)
+	if _, err := upload(ctx, store, key, jsonData, "application/json"); err != nil {
+		return fmt.Errorf("failed to upload module changes.json: %w", err)
+	}
+
+	return nil
+}
+
 // StoreModuleSubmoduleInS3 stores submodule data and README in S3, returns (indexChecksum, readmeChecksum, error)
-func StoreModuleSubmoduleInS3(ctx context.Context, uploader *manager.Uploader, bucketName, namespace, name, target, version, submoduleName string, tofuJSON any, workDir string) (string, string, error) {
+func StoreModuleSubmoduleInS3(ctx context.Context, store bucket.Store, namespace, name, target, version, submoduleName string, tofuJSON any, workDir string) (string, string, error) {
 	// Upload submodule index.json
 	jsonData, err := json.MarshalIndent(tofuJSON, "", "  ")
 	if err != nil {
@@ -51,7 +63,7 @@ func StoreModuleSubmoduleInS3(ctx context.Context, uploader *manager.Uploader, b
 	}
 
 	indexKey := fmt.Sprintf("modules/%s/%s/%s/%s/submodules/%s/index.json", namespace, name, target, version, submoduleName)
-	indexChecksum, err := uploadToS3(ctx, uploader, bucketName, indexKey, jsonData, "application/json")
+	indexChecksum, err := upload(ctx, store, indexKey, jsonData, "application/json")
 	if err != nil {
 		return "", "", fmt.Errorf("failed to upload submodule index.json: %w", err)
 	}
@@ -61,7 +73,7 @@ func StoreModuleSubmoduleInS3(ctx context.Context, uploader *manager.Uploader, b
 	readmePath := filepath.Join(workDir, "modules", submoduleName, "README.md")
 	if readmeContent, err := os.ReadFile(readmePath); err == nil {
 		readmeKey := fmt.Sprintf("modules/%s/%s/%s/%s/submodules/%s/README.md", namespace, name, target, version, submoduleName)
-		readmeChecksum, err = uploadToS3(ctx, uploader, bucketName, readmeKey, readmeContent, "text/markdown")
+		readmeChecksum, err = upload(ctx, store, readmeKey, readmeContent, "text/markdown")
 		if err != nil {
 			slog.WarnContext(ctx, "Failed to upload submodule README", "error", err, "submodule", submoduleName)
 		} else {
@@ -80,7 +92,7 @@ func StoreModuleSubmoduleInS3(ctx context.Context, uploader *manager.Uploader, b
 }
 
 // StoreModuleExampleInS3 stores example data and README in S3, returns (indexChecksum, readmeChecksum, error)
-func StoreModuleExampleInS3(ctx context.Context, uploader *manager.Uploader, bucketName, namespace, name, target, version, exampleName string, tofuJSON any, workDir string) (string, string, error) {
+func StoreModuleExampleInS3(ctx context.Context, store bucket.Store, namespace, name, target, version, exampleName string, tofuJSON any, workDir string) (string, string, error) {
 	// Upload example index.json
 	jsonData, err := json.MarshalIndent(tofuJSON, "", "  ")
 	if err != nil {
@@ -88,7 +100,7 @@ func StoreModuleExampleInS3(ctx context.Context, uploader *manager.Uploader, buc
 	}
 
 	indexKey := fmt.Sprintf("modules/%s/%s/%s/%s/examples/%s/index.json", namespace, name, target, version, exampleName)
-	indexChecksum, err := uploadToS3(ctx, uploader, bucketName, indexKey, jsonData, "application/json")
+	indexChecksum, err := upload(ctx, store, indexKey, jsonData, "application/json")
 	if err != nil {
 		return "", "", fmt.Errorf("failed to upload example index.json: %w", err)
 	}
@@ -98,7 +110,7 @@ func StoreModuleExampleInS3(ctx context.Context, uploader *manager.Uploader, buc
 	readmePath := filepath.Join(workDir, "examples", exampleName, "README.md")
 	if readmeContent, err := os.ReadFile(readmePath); err == nil {
 		readmeKey := fmt.Sprintf("modules/%s/%s/%s/%s/examples/%s/README.md", namespace, name, target, version, exampleName)
-		readmeChecksum, err = uploadToS3(ctx, uploader, bucketName, readmeKey, readmeContent, "text/markdown")
+		readmeChecksum, err = upload(ctx, store, readmeKey, readmeContent, "text/markdown")
 		if err != nil {
 			slog.WarnContext(ctx, "Failed to upload example README", "error", err, "example", exampleName)
 		} else {
@@ -117,7 +129,7 @@ func StoreModuleExampleInS3(ctx context.Context, uploader *manager.Uploader, buc
 }
 
 // StoreModuleREADME stores the main module README in S3 and returns the MD5 checksum
-func StoreModuleREADME(ctx context.Context, uploader *manager.Uploader, bucketName, namespace, name, target, version, workDir string) (string, error) {
+func StoreModuleREADME(ctx context.Context, store bucket.Store, namespace, name, target, version, workDir string) (string, error) {
 	readmePath := filepath.Join(workDir, "README.md")
 	readmeContent, err := os.ReadFile(readmePath)
 	if err != nil {
@@ -130,7 +142,7 @@ func StoreModuleREADME(ctx context.Context, uploader *manager.Uploader, bucketNa
 	}
 
 	readmeKey := fmt.Sprintf("modules/%s/%s/%s/%s/README.md", namespace, name, target, version)
-	md5Hash, err := uploadToS3(ctx, uploader, bucketName, readmeKey, readmeContent, "text/markdown")
+	md5Hash, err := upload(ctx, store, readmeKey, readmeContent, "text/markdown")
 	if err != nil {
 		return "", fmt.Errorf("failed to upload module README: %w", err)
 	}
@@ -144,35 +156,26 @@ func StoreModuleREADME(ctx context.Context, uploader *manager.Uploader, bucketNa
 	return md5Hash, nil
 }
 
-// uploadToS3 uploads data to S3 with the specified content type and returns the MD5 checksum
-func uploadToS3(ctx context.Context, uploader *manager.Uploader, bucketName, key string, data []byte, contentType string) (string, error) {
-	// TODO: find a centralized way to upload files with decent OTEL in there, for now we'll just keep this method in this package
-	ctx, span := telemetry.Tracer().Start(ctx, "module_storage.upload_to_s3")
+// upload stores data in the bucket with the specified content type and returns the MD5 checksum
+func upload(ctx context.Context, store bucket.Store, key string, data []byte, contentType string) (string, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "module_storage.upload")
 	defer span.End()
 
 	hash := md5.Sum(data)
 	checksum := hex.EncodeToString(hash[:])
 
-	toUpload := &s3.PutObjectInput{
-		Bucket:      aws.String(bucketName),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(data),
-		ContentType: aws.String(contentType),
-	}
-
-	span.SetAttributes(attribute.String("bucket", bucketName),
-		attribute.String("key", key),
+	span.SetAttributes(attribute.String("key", key),
 		attribute.String("content-type", contentType),
 		attribute.Int64("size", int64(len(data))),
 		attribute.String("md5_checksum", checksum),
 	)
 
-	_, err := uploader.Upload(ctx, toUpload)
+	err := store.Put(ctx, bucket.Object{Key: key, Body: data, ContentType: contentType})
 	if err != nil {
 		span.RecordError(err)
 		return "", err
 	}
 
-	slog.DebugContext(ctx, "Uploaded to S3", "key", key, "checksum", checksum)
+	slog.DebugContext(ctx, "Uploaded to bucket", "key", key, "checksum", checksum)
 	return checksum, nil
 }
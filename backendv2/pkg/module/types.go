@@ -13,6 +13,7 @@ type IndexResponse struct {
 	LicensesCount  int       `json:"licenses_count"`
 	Success        bool      `json:"success"`
 	ErrorMessage   string    `json:"error_message,omitempty"`
+	ErrorClass     string    `json:"error_class,omitempty"`
 }
 
 // ModuleVersion represents a specific version of a module with metadata
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/provider/storage"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// DocRef identifies a document of a provider version
+type DocRef struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Language string `json:"language"`
+}
+
+// VersionChanges is the content of changes.json: the documents added, removed or changed in a provider version
+// relative to the previous version
+type VersionChanges struct {
+	Version         string   `json:"version"`
+	PreviousVersion string   `json:"previous_version,omitempty"`
+	Added           []DocRef `json:"added"`
+	Removed         []DocRef `json:"removed"`
+	Changed         []DocRef `json:"changed"`
+}
+
+// DiffDocuments compares the document checksums of two provider versions, as returned by
+// storage.GetAllDocumentChecksums. A nil previous map means this is the first version, so every document is added.
+func DiffDocuments(previousVersion string, previous map[string]string, version string, current map[string]string) *VersionChanges {
+	changes := &VersionChanges{
+		Version:         version,
+		PreviousVersion: previousVersion,
+		Added:           []DocRef{},
+		Removed:         []DocRef{},
+		Changed:         []DocRef{},
+	}
+
+	for key, checksum := range current {
+		previousChecksum, existed := previous[key]
+		switch {
+		case !existed:
+			changes.Added = append(changes.Added, parseDocKey(key))
+		case previousChecksum != checksum:
+			changes.Changed = append(changes.Changed, parseDocKey(key))
+		}
+	}
+	for key := range previous {
+		if _, exists := current[key]; !exists {
+			changes.Removed = append(changes.Removed, parseDocKey(key))
+		}
+	}
+
+	for _, refs := range [][]DocRef{changes.Added, changes.Removed, changes.Changed} {
+		slices.SortFunc(refs, compareDocRefs)
+	}
+
+	return changes
+}
+
+// parseDocKey splits a "docType:docName:language" checksum key
+func parseDocKey(key string) DocRef {
+	docType, rest, _ := strings.Cut(key, ":")
+	idx := strings.LastIndex(rest, ":")
+	if idx < 0 {
+		return DocRef{Type: docType, Name: rest}
+	}
+	return DocRef{Type: docType, Name: rest[:idx], Language: rest[idx+1:]}
+}
+
+func compareDocRefs(a, b DocRef) int {
+	if c := strings.Compare(a.Type, b.Type); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Name, b.Name); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Language, b.Language)
+}
+
+// PublishVersionChanges generates and uploads changes.json for every given version and for the version that follows
+// each of them, since indexing a version in the middle of the history changes what its successor is compared against.
+// Versions that are no longer in the database only get their successor republished.
+func PublishVersionChanges(ctx context.Context, db storage.Queryable, store bucket.Store, namespace, name string, versions []string) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "provider.publish_version_changes")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("provider.namespace", namespace),
+		attribute.String("provider.name", name),
+		attribute.Int("provider.versions", len(versions)),
+	)
+
+	toPublish := make(map[string]struct{})
+	for _, version := range versions {
+		_, next, err := storage.GetAdjacentProviderVersions(ctx, db, namespace, name, version)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+		toPublish[version] = struct{}{}
+		if next != "" {
+			toPublish[next] = struct{}{}
+		}
+	}
+
+	if err := publishChanges(ctx, db, store, namespace, name, toPublish); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// PublishAllVersionChanges generates and uploads changes.json for every version of a provider, backfilling versions
+// that were indexed before changes were published
+func PublishAllVersionChanges(ctx context.Context, db storage.Queryable, store bucket.Store, namespace, name string) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "provider.publish_all_version_changes")
+	defer span.End()
+
+	versions, err := storage.GetExistingProviderVersions(ctx, db, namespace, name)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	span.SetAttributes(attribute.Int("provider.versions", len(versions)))
+
+	toPublish := make(map[string]struct{}, len(versions))
+	for _, version := range versions {
+		toPublish[version] = struct{}{}
+	}
+
+	if err := publishChanges(ctx, db, store, namespace, name, toPublish); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// publishChanges uploads the changes.json of every given version that has documents, comparing it against the
+// completed version before it
+func publishChanges(ctx context.Context, db storage.Queryable, store bucket.Store, namespace, name string, versions map[string]struct{}) error {
+	for version := range versions {
+		current, err := storage.GetAllDocumentChecksums(ctx, db, namespace, name, version)
+		if err != nil {
+			return err
+		}
+		// Skipped, blocked and removed versions have no documents, a changelog would only list everything as removed
+		if len(current) == 0 {
+			continue
+		}
+
+		previousVersion, _, err := storage.GetAdjacentProviderVersions(ctx, db, namespace, name, version)
+		if err != nil {
+			return err
+		}
+
+		var previous map[string]string
+		if previousVersion != "" {
+			previous, err = storage.GetAllDocumentChecksums(ctx, db, namespace, name, previousVersion)
+			if err != nil {
+				return err
+			}
+		}
+
+		changes := DiffDocuments(previousVersion, previous, version, current)
+		if err := uploadVersionChanges(ctx, store, namespace, name, changes); err != nil {
+			return err
+		}
+
+		slog.DebugContext(ctx, "Published provider version changes",
+			"provider", fmt.Sprintf("%s/%s", namespace, name),
+			"version", version,
+			"previous_version", previousVersion,
+			"added", len(changes.Added),
+			"removed", len(changes.Removed),
+			"changed", len(changes.Changed))
+	}
+
+	return nil
+}
+
+// uploadVersionChanges uploads changes.json next to the index.json of a provider version
+func uploadVersionChanges(ctx context.Context, store bucket.Store, namespace, name string, changes *VersionChanges) error {
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal provider changes: %w", err)
+	}
+
+	key := fmt.Sprintf("providers/%s/%s/%s/changes.json", namespace, name, changes.Version)
+	err = store.Put(ctx, bucket.Object{Key: key, Body: data, ContentType: "application/json"})
+	if err != nil {
+		return fmt.Errorf("failed to upload provider changes.json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+)
+
+func TestDiffDocuments(t *testing.T) {
+	tests := []struct {
+		name        string
+		previous    map[string]string
+		current     map[string]string
+		wantAdded   []DocRef
+		wantRemoved []DocRef
+		wantChanged []DocRef
+	}{
+		{
+			name:      "first version",
+			previous:  nil,
+			current:   map[string]string{"resources:instance:default": "a"},
+			wantAdded: []DocRef{{Type: "resources", Name: "instance", Language: "default"}},
+		},
+		{
+			name:     "unchanged",
+			previous: map[string]string{"resources:instance:default": "a"},
+			current:  map[string]string{"resources:instance:default": "a"},
+		},
+		{
+			name: "added, removed and changed",
+			previous: map[string]string{
+				"resources:instance:default":  "a",
+				"datasources:ami:default":     "b",
+				"resources:instance:python":   "c",
+				"functions:arn_parse:default": "d",
+			},
+			current: map[string]string{
+				"resources:instance:default":  "a",
+				"datasources:ami:default":     "changed",
+				"resources:instance:python":   "c",
+				"resources:vpc:default":       "e",
+				"ephemeral:password:default":  "f",
+				"functions:arn_build:default": "g",
+			},
+			wantAdded: []DocRef{
+				{Type: "ephemeral", Name: "password", Language: "default"},
+				{Type: "functions", Name: "arn_build", Language: "default"},
+				{Type: "resources", Name: "vpc", Language: "default"},
+			},
+			wantRemoved: []DocRef{{Type: "functions", Name: "arn_parse", Language: "default"}},
+			wantChanged: []DocRef{{Type: "datasources", Name: "ami", Language: "default"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffDocuments("1.0.0", tt.previous, "1.1.0", tt.current)
+
+			for _, check := range []struct {
+				label string
+				got   []DocRef
+				want  []DocRef
+			}{
+				{"added", got.Added, tt.wantAdded},
+				{"removed", got.Removed, tt.wantRemoved},
+				{"changed", got.Changed, tt.wantChanged},
+			} {
+				if len(check.got) == 0 && len(check.want) == 0 {
+					continue
+				}
+				if !reflect.DeepEqual(check.got, check.want) {
+					t.Errorf("%s: got %+v, want %+v", check.label, check.got, check.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadVersionChanges(t *testing.T) {
+	ctx := context.Background()
+	store, err := bucket.NewDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := DiffDocuments("1.0.0", map[string]string{}, "1.1.0", map[string]string{"resources:instance:hcl": "a"})
+	if err := uploadVersionChanges(ctx, store, "hashicorp", "aws", changes); err != nil {
+		t.Fatalf("uploadVersionChanges() error = %v", err)
+	}
+
+	// changes.json sits next to the index.json of the version
+	obj, err := store.Get(ctx, "providers/hashicorp/aws/1.1.0/changes.json")
+	if err != nil {
+		t.Fatalf("changes.json not uploaded: %v", err)
+	}
+	var got VersionChanges
+	if err := json.Unmarshal(obj.Body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, changes) {
+		t.Errorf("uploaded changes = %+v, want %+v", got, changes)
+	}
+}
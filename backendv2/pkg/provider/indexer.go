@@ -14,6 +14,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/opentofu/registry-ui/pkg/blocklist"
 	"github.com/opentofu/registry-ui/pkg/git"
 	"github.com/opentofu/registry-ui/pkg/index"
 	"github.com/opentofu/registry-ui/pkg/license"
@@ -21,7 +22,10 @@ import (
 	"github.com/opentofu/registry-ui/pkg/provider/storage"
 	"github.com/opentofu/registry-ui/pkg/registry"
 	"github.com/opentofu/registry-ui/pkg/repository"
+	"github.com/opentofu/registry-ui/pkg/scrapeerr"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/tofu"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 // IndexVersion indexes a specific version of a provider
@@ -56,6 +60,20 @@ func (p *ProviderReader) IndexVersion(ctx context.Context, provider *registry.Pr
 		return nil, fmt.Errorf("version %s not found for provider %s/%s", version, namespace, name)
 	}
 
+	// Blocked providers are recorded as skipped without checking out or scraping anything
+	blocked, blockedReason, err := blocklist.IsProviderBlocked(ctx, p.db, namespace, name)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to check blocklist: %w", err)
+	}
+	if blocked {
+		span.SetAttributes(
+			attribute.String("provider.skip_reason", blocklist.SkipReason),
+			attribute.Bool("provider.docs_skipped", true),
+		)
+		return p.storeBlockedVersion(ctx, namespace, name, version, blockedReason)
+	}
+
 	// Checkout the version for scraping
 	var workDir string
 	var cleanup func()
@@ -63,7 +81,7 @@ func (p *ProviderReader) IndexVersion(ctx context.Context, provider *registry.Pr
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to checkout version: %w", err)
+		return nil, scrapeerr.Checkout(fmt.Errorf("failed to checkout version: %w", err))
 	}
 	defer cleanup()
 
@@ -73,7 +91,7 @@ func (p *ProviderReader) IndexVersion(ctx context.Context, provider *registry.Pr
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to detect licenses: %w", err)
+		return nil, scrapeerr.Wrap(scrapeerr.LicenseDetection, fmt.Errorf("failed to detect licenses: %w", err))
 	}
 
 	// Determine if licenses are acceptable for documentation scraping
@@ -106,11 +124,12 @@ func (p *ProviderReader) IndexVersion(ctx context.Context, provider *registry.Pr
 	}
 
 	// Create documentation scraper
-	docScraper := scraper.New(p.config, p.uploader, p.db)
+	docScraper := scraper.New(p.config, p.store, p.db, p.vcsHost)
 
 	// Initialize doc count and docs
 	var docCount int
 	var docs map[string]*scraper.DocItem
+	var schema *tofu.ProviderSchema
 
 	// Only scrape documentation if license is acceptable
 	if licenseAccepted {
@@ -122,6 +141,29 @@ func (p *ProviderReader) IndexVersion(ctx context.Context, provider *registry.Pr
 			return nil, fmt.Errorf("failed to scrape documentation: %w", err)
 		}
 		docCount = len(docs)
+
+		// The schema complements the docs, so a failure is logged rather than failing the version
+		if p.config.ProviderSchema.Enabled {
+			schema, err = p.ExtractSchema(ctx, provider, version)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to extract provider schema",
+					"provider", fmt.Sprintf("%s/%s", namespace, name),
+					"version", version, "error", err)
+				schema = nil
+			}
+		}
+
+		// Providers without a docs directory would end up with an empty page, so fall back to pages
+		// generated from the schema
+		if docCount == 0 && schema != nil {
+			docs = scraper.GenerateDocsFromSchema(name, schema)
+			docCount = len(docs)
+			span.SetAttributes(attribute.Bool("provider.docs_generated", true))
+			slog.InfoContext(ctx, "No documentation found, generated documentation from the provider schema",
+				"provider", fmt.Sprintf("%s/%s", namespace, name),
+				"version", version,
+				"docs", docCount)
+		}
 	} else {
 		docCount = 0
 		slog.InfoContext(ctx, "Skipping documentation scraping due to incompatible license",
@@ -136,7 +178,7 @@ func (p *ProviderReader) IndexVersion(ctx context.Context, provider *registry.Pr
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to start transaction: %w", err))
 	}
 	defer tx.Rollback(ctx)
 
@@ -165,11 +207,11 @@ func (p *ProviderReader) IndexVersion(ctx context.Context, provider *registry.Pr
 	}
 
 	// Store provider version in database
-	err = storage.StoreProviderVersion(ctx, tx, namespace, name, version, docCount, len(licenses), tagCreatedAt, licenseAccepted, scrapeStatus, skipReason, "")
+	err = storage.StoreProviderVersion(ctx, tx, namespace, name, version, docCount, len(licenses), tagCreatedAt, licenseAccepted, scrapeStatus, skipReason, "", "")
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to store provider version: %w", err)
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store provider version: %w", err))
 	}
 
 	// Store license information (always, for complete audit trail)
@@ -178,7 +220,7 @@ func (p *ProviderReader) IndexVersion(ctx context.Context, provider *registry.Pr
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
-			return nil, fmt.Errorf("failed to store provider licenses: %w", err)
+			return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store provider licenses: %w", err))
 		}
 		slog.DebugContext(ctx, "Stored license information in database",
 			"provider", fmt.Sprintf("%s/%s", namespace, name),
@@ -196,11 +238,31 @@ func (p *ProviderReader) IndexVersion(ctx context.Context, provider *registry.Pr
 		}
 	}
 
+	if schema != nil {
+		err = storage.StoreProviderSchema(ctx, tx, namespace, name, version, schema)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store provider schema: %w", err))
+		}
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to commit transaction: %w", err))
+	}
+	telemetry.RecordVersionIndexed(ctx, "provider", scrapeStatus, skipReason)
+
+	// Like the extraction, the schema upload only complements the docs, so a failure does not fail the version
+	if schema != nil {
+		if err := p.uploadSchema(ctx, namespace, name, version, schema); err != nil {
+			span.RecordError(err)
+			slog.WarnContext(ctx, "Failed to upload provider schema",
+				"provider", fmt.Sprintf("%s/%s", namespace, name),
+				"version", version, "error", err)
+		}
 	}
 
 	response = &IndexResponse{
@@ -258,12 +320,31 @@ func (p *ProviderReader) IndexAllVersions(ctx context.Context, namespace, name s
 		return nil, fmt.Errorf("failed to get existing versions: %w", err)
 	}
 
+	// Versions that disappeared from the registry are no longer published
+	removedVersions, err := p.handleRemovedVersions(ctx, namespace, name, existingVersions, allVersions)
+	if err != nil {
+		span.RecordError(err)
+		slog.WarnContext(ctx, "Failed to handle versions removed from the registry",
+			"provider", fmt.Sprintf("%s/%s", namespace, name), "error", err)
+	}
+
 	// Convert to map for fast lookup
 	existingSet := make(map[string]bool)
 	for _, version := range existingVersions {
 		existingSet[version] = true
 	}
 
+	// Failed versions are scraped again once their retry backoff has passed
+	retryVersions, err := p.retryableVersions(ctx, namespace, name)
+	if err != nil {
+		span.RecordError(err)
+		slog.WarnContext(ctx, "Failed to get failed versions to retry",
+			"provider", fmt.Sprintf("%s/%s", namespace, name), "error", err)
+	}
+	for _, version := range retryVersions {
+		delete(existingSet, version)
+	}
+
 	// Filter to only versions that need processing
 	var versionsToProcess []string
 	var skippedVersions []string
@@ -309,11 +390,14 @@ func (p *ProviderReader) IndexAllVersions(ctx context.Context, namespace, name s
 	if len(versionsToProcess) == 0 {
 		slog.DebugContext(ctx, "No versions to process, all versions already exist",
 			"provider", fmt.Sprintf("%s/%s", namespace, name))
+		if len(removedVersions) > 0 {
+			p.RegenerateProviderVersionIndex(ctx, namespace, name)
+		}
 		return responses, nil
 	}
 
 	// Pre-clone and fetch tags before parallel processing to avoid race conditions
-	repoURL := fmt.Sprintf("https://github.com/%s/terraform-provider-%s", namespace, name)
+	repoURL := p.vcsHost.CloneURL(vcs.ProviderRepo(namespace, name))
 	localPath := filepath.Join(p.config.WorkDir, "providers", namespace, name)
 
 	slog.DebugContext(ctx, "Preparing provider repository for parallel processing",
@@ -390,15 +474,17 @@ func (p *ProviderReader) IndexAllVersions(ctx context.Context, namespace, name s
 			// Pass the provider data to avoid redundant file reads
 			response, err := p.IndexVersion(versionCtx, provider, version)
 			if err != nil {
+				errorClass := scrapeerr.ClassOf(err)
 				versionSpan.RecordError(err)
 				versionSpan.SetStatus(codes.Error, err.Error())
-				slog.WarnContext(versionCtx, "Failed to index version, storing as failed to prevent retry",
+				versionSpan.SetAttributes(attribute.String("provider.error_class", string(errorClass)))
+				slog.WarnContext(versionCtx, "Failed to index version, storing as failed",
 					"provider", fmt.Sprintf("%s/%s", namespace, name),
-					"version", version, "error", err)
+					"version", version, "error", err, "error_class", errorClass)
 
 				// Store failed version in database to prevent re-scraping
 				// Pass the provider data to avoid redundant file reads
-				storeErr := p.storeFailedVersion(versionCtx, namespace, name, version, err.Error(), provider)
+				storeErr := p.storeFailedVersion(versionCtx, namespace, name, version, err, provider)
 				if storeErr != nil {
 					slog.ErrorContext(versionCtx, "Failed to store failed version record",
 						"provider", fmt.Sprintf("%s/%s", namespace, name),
@@ -418,6 +504,7 @@ func (p *ProviderReader) IndexAllVersions(ctx context.Context, namespace, name s
 					ProcessedAt:  time.Now().UTC(),
 					Success:      false,
 					ErrorMessage: err.Error(),
+					ErrorClass:   string(errorClass),
 				}
 			}
 
@@ -443,16 +530,17 @@ func (p *ProviderReader) IndexAllVersions(ctx context.Context, namespace, name s
 			"failed_versions", failedVersions)
 	}
 
-	// Sync repository metadata from GitHub (stats, fork info, etc.)
-	if p.githubClient != nil {
-		repoOrg := namespace
-		repoName := fmt.Sprintf("terraform-provider-%s", name)
+	// Sync repository metadata from the VCS host (stats, fork info, etc.)
+	if p.metadataSource != nil {
+		providerRepo := vcs.ProviderRepo(namespace, name)
+		repoOrg := providerRepo.Owner
+		repoName := providerRepo.Name
 
-		slog.DebugContext(ctx, "Syncing repository metadata from GitHub",
+		slog.DebugContext(ctx, "Syncing repository metadata",
 			"provider", fmt.Sprintf("%s/%s", namespace, name),
 			"repository", fmt.Sprintf("%s/%s", repoOrg, repoName))
 
-		err := repository.SyncRepositoryMetadata(ctx, p.db, p.githubClient, repoOrg, repoName)
+		err := repository.SyncRepositoryMetadata(ctx, p.db, p.metadataSource, repoOrg, repoName)
 		if err != nil {
 			// Log error but don't fail the sync process
 			slog.WarnContext(ctx, "Failed to sync repository metadata",
@@ -466,20 +554,35 @@ func (p *ProviderReader) IndexAllVersions(ctx context.Context, namespace, name s
 		}
 	}
 
+	// Publish changes.json relative to the previous version for everything indexed in this run
+	var indexedVersions []string
+	for _, response := range responses {
+		if response.Success {
+			indexedVersions = append(indexedVersions, response.Version)
+		}
+	}
+	if len(indexedVersions) > 0 {
+		if err := PublishVersionChanges(ctx, p.db, p.store, namespace, name, indexedVersions); err != nil {
+			slog.WarnContext(ctx, "Failed to publish provider version changes",
+				"provider", fmt.Sprintf("%s/%s", namespace, name),
+				"error", err)
+		}
+	}
+
 	// Generate and upload provider version index after processing versions
 	p.RegenerateProviderVersionIndex(ctx, namespace, name)
 
 	return responses, nil
 }
 
-// RegenerateProviderVersionIndex generates and uploads the per-provider version index to S3.
+// RegenerateProviderVersionIndex generates and uploads the per-provider version index and resources.json to S3.
 // Note: The global provider index is NOT updated here to avoid race conditions.
 // Use the `rebuild-global-indexes` command to rebuild it from the database.
 func (p *ProviderReader) RegenerateProviderVersionIndex(ctx context.Context, namespace, name string) {
 	slog.InfoContext(ctx, "Generating provider version index",
 		"provider", fmt.Sprintf("%s/%s", namespace, name))
 
-	providerIndex, err := index.GenerateProviderVersionIndex(ctx, p.db, namespace, name)
+	providerIndex, err := index.GenerateProviderVersionIndex(ctx, p.db, p.vcsHost, namespace, name)
 	if err != nil {
 		slog.WarnContext(ctx, "Failed to generate provider version index",
 			"provider", fmt.Sprintf("%s/%s", namespace, name),
@@ -487,7 +590,7 @@ func (p *ProviderReader) RegenerateProviderVersionIndex(ctx context.Context, nam
 		return
 	}
 
-	err = index.UploadProviderVersionIndex(ctx, p.uploader, p.config.Bucket.BucketName, providerIndex)
+	err = index.UploadProviderVersionIndex(ctx, p.store, providerIndex)
 	if err != nil {
 		slog.WarnContext(ctx, "Failed to upload provider version index",
 			"provider", fmt.Sprintf("%s/%s", namespace, name),
@@ -498,32 +601,80 @@ func (p *ProviderReader) RegenerateProviderVersionIndex(ctx context.Context, nam
 	slog.InfoContext(ctx, "Successfully uploaded provider version index",
 		"provider", fmt.Sprintf("%s/%s", namespace, name),
 		"versions", len(providerIndex.Versions))
+
+	if err := p.PublishResourceTimeline(ctx, namespace, name); err != nil {
+		slog.WarnContext(ctx, "Failed to upload provider resource timeline",
+			"provider", fmt.Sprintf("%s/%s", namespace, name),
+			"error", err)
+	}
+}
+
+// storeBlockedVersion records a version of a blocked provider as skipped so it is neither scraped nor retried
+func (p *ProviderReader) storeBlockedVersion(ctx context.Context, namespace, name, version, reason string) (*IndexResponse, error) {
+	slog.WarnContext(ctx, "Provider is blocked, will store version but skip documentation",
+		"provider", fmt.Sprintf("%s/%s", namespace, name),
+		"version", version,
+		"reason", reason)
+
+	tagCreatedAt, err := p.GetTagCreationDate(ctx, namespace, name, version)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get tag creation date",
+			"provider", fmt.Sprintf("%s/%s", namespace, name),
+			"version", version, "error", err)
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to start transaction: %w", err))
+	}
+	defer tx.Rollback(ctx)
+
+	err = storage.StoreProviderVersion(ctx, tx, namespace, name, version, 0, 0, tagCreatedAt, false, "skipped", blocklist.SkipReason, reason, "")
+	if err != nil {
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store provider version: %w", err))
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to commit transaction: %w", err))
+	}
+	telemetry.RecordVersionIndexed(ctx, "provider", "skipped", blocklist.SkipReason)
+
+	return &IndexResponse{
+		Namespace:   namespace,
+		Name:        name,
+		Version:     version,
+		ProcessedAt: time.Now().UTC(),
+		Success:     true,
+	}, nil
 }
 
-// storeFailedVersion stores a minimal version record with status='failed' to prevent re-scraping
+// storeFailedVersion stores a minimal version record with status='failed' and the class of indexErr, failed versions
+// are only scraped again by the retry policy
 // Note: Repository and provider records are stored BEFORE parallel processing in IndexAllVersions
 // provider parameter must be provided by the caller to avoid redundant file reads
-func (p *ProviderReader) storeFailedVersion(ctx context.Context, namespace, name, version, errorMessage string, provider *registry.Provider) error {
+func (p *ProviderReader) storeFailedVersion(ctx context.Context, namespace, name, version string, indexErr error, provider *registry.Provider) error {
 	// Start database transaction
 	tx, err := p.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
+		return scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to start transaction: %w", err))
 	}
 	defer tx.Rollback(ctx)
 
 	// Store provider version with status='failed' and error message
-	err = storage.StoreProviderVersion(ctx, tx, namespace, name, version, 0, 0, nil, false, "failed", "processing_error", errorMessage)
+	err = storage.StoreProviderVersion(ctx, tx, namespace, name, version, 0, 0, nil, false, "failed", "processing_error", indexErr.Error(), string(scrapeerr.ClassOf(indexErr)))
 	if err != nil {
-		return fmt.Errorf("failed to store provider version: %w", err)
+		return scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store provider version: %w", err))
 	}
 
 	// Commit transaction
 	err = tx.Commit(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to commit transaction: %w", err))
 	}
+	telemetry.RecordVersionIndexed(ctx, "provider", "failed", string(scrapeerr.ClassOf(indexErr)))
 
-	slog.InfoContext(ctx, "Stored failed version record to prevent re-scraping",
+	slog.InfoContext(ctx, "Stored failed version record, it is retried according to the retry policy",
 		"provider", fmt.Sprintf("%s/%s", namespace, name),
 		"version", version)
 
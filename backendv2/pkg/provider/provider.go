@@ -7,11 +7,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/opentofu/registry-ui/pkg/bucket"
 	"github.com/opentofu/registry-ui/pkg/config"
 	"github.com/opentofu/registry-ui/pkg/git"
 	"github.com/opentofu/registry-ui/pkg/license"
@@ -19,40 +18,47 @@ import (
 	"github.com/opentofu/registry-ui/pkg/registry"
 	"github.com/opentofu/registry-ui/pkg/repository"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 // ProviderReader is the main entry point for all provider operations
 type ProviderReader struct {
 	config       *config.BackendConfig
 	db           *pgxpool.Pool
-	s3Client     *s3.Client
-	uploader     *manager.Uploader
+	store        bucket.Store
 	githubClient *repository.Client
+
+	vcsHost        vcs.Host
+	metadataSource repository.MetadataSource
 }
 
 // NewProviderReader creates a new ProviderReader with all dependencies initialized
 func NewProviderReader(ctx context.Context, cfg *config.BackendConfig, db *pgxpool.Pool) (*ProviderReader, error) {
-	// Initialize S3 client
-	s3Client, err := cfg.Bucket.GetClient(ctx)
+	// Initialize the storage for generated files
+	store, err := bucket.New(ctx, &cfg.Bucket)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize S3 client: %w", err)
+		return nil, fmt.Errorf("failed to initialize bucket storage: %w", err)
 	}
 
-	// Initialize S3 uploader
-	uploader := manager.NewUploader(s3Client)
+	vcsHost, err := vcs.New(cfg.VCS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize VCS host: %w", err)
+	}
 
-	// Initialize GitHub client if configured
+	// Initialize GitHub client if configured, it is only used for license detection on GitHub hosted repositories
 	var githubClient *repository.Client
-	if cfg.GitHub.Token != "" {
-		githubClient = repository.NewClient(ctx, &cfg.GitHub)
+	if cfg.GitHub.Configured() && vcsHost.Type() == config.VCSTypeGitHub {
+		githubClient = repository.NewClient(ctx, &cfg.GitHub, cfg.VCS.GitHubAPIURL(), db)
 	}
 
 	return &ProviderReader{
 		config:       cfg,
 		db:           db,
-		s3Client:     s3Client,
-		uploader:     uploader,
+		store:        store,
 		githubClient: githubClient,
+
+		vcsHost:        vcsHost,
+		metadataSource: repository.NewMetadataSource(ctx, cfg, db),
 	}, nil
 }
 
@@ -116,7 +122,7 @@ func (p *ProviderReader) ScrapeAllVersions(ctx context.Context, provider *regist
 
 // GetTagCreationDate returns the creation date of a git tag for a provider
 func (p *ProviderReader) GetTagCreationDate(ctx context.Context, namespace, name, version string) (*time.Time, error) {
-	repoURL := fmt.Sprintf("https://github.com/%s/terraform-provider-%s", namespace, name)
+	repoURL := p.vcsHost.CloneURL(vcs.ProviderRepo(namespace, name))
 	localPath := filepath.Join(p.config.WorkDir, "providers", namespace, name)
 
 	repo, err := git.GetRepo(repoURL, localPath)
@@ -136,7 +142,7 @@ func (p *ProviderReader) GetTagCreationDate(ctx context.Context, namespace, name
 // CheckoutVersionForScraping creates a worktree for a tag and returns the directory path and cleanup function
 // Provider tags typically have a 'v' prefix (e.g., v1.0.0), so we ensure the tag starts with 'v'
 func (p *ProviderReader) CheckoutVersionForScraping(ctx context.Context, namespace, name, tag string) (string, func(), error) {
-	repoURL := fmt.Sprintf("https://github.com/%s/terraform-provider-%s", namespace, name)
+	repoURL := p.vcsHost.CloneURL(vcs.ProviderRepo(namespace, name))
 	localPath := filepath.Join(p.config.WorkDir, "providers", namespace, name)
 
 	// Ensure tag has 'v' prefix for provider repositories
@@ -160,17 +166,14 @@ func (p *ProviderReader) DetectLicensesInDirectory(ctx context.Context, namespac
 	)
 
 	// Create license detector
-	detector, err := license.New(p.config.License, p.githubClient)
+	detector, err := license.New(p.config.License, p.githubClient, p.vcsHost)
 	if err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to create license detector: %w", err)
 	}
 
-	// Generate repo URL for context
-	repoURL := fmt.Sprintf("https://github.com/%s/terraform-provider-%s", namespace, name)
-
 	// Detect licenses in the directory
-	licenses, err := detector.Detect(ctx, directory, repoURL)
+	licenses, err := detector.Detect(ctx, directory, vcs.ProviderRepo(namespace, name))
 	if err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to detect licenses in directory %s: %w", directory, err)
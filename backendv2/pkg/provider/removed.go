@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/provider/storage"
+	"github.com/opentofu/registry-ui/pkg/registry"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// handleRemovedVersions marks or deletes (depending on removedVersions.action) the known versions of a provider that
+// are no longer listed in the registry and returns them. The caller is responsible for regenerating the version index.
+func (p *ProviderReader) handleRemovedVersions(ctx context.Context, namespace, name string, known, listed []string) ([]string, error) {
+	removed := registry.RemovedVersions(known, listed)
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	// A provider without any versions is more likely a broken registry checkout than a takedown of every release
+	if len(listed) == 0 {
+		slog.WarnContext(ctx, "Registry lists no versions for provider, not removing known versions",
+			"provider", fmt.Sprintf("%s/%s", namespace, name), "known_versions", len(known))
+		return nil, nil
+	}
+
+	ctx, span := telemetry.Tracer().Start(ctx, "provider.handle_removed_versions")
+	defer span.End()
+
+	action := p.config.RemovedVersions.Action
+	span.SetAttributes(
+		attribute.String("provider.namespace", namespace),
+		attribute.String("provider.name", name),
+		attribute.StringSlice("provider.removed_versions", removed),
+		attribute.String("action", action),
+	)
+
+	if action == config.RemovedVersionsDelete {
+		if err := storage.DeleteProviderVersions(ctx, p.db, namespace, name, removed); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		for _, version := range removed {
+			prefix := fmt.Sprintf("providers/%s/%s/%s/", namespace, name, version)
+			if _, err := bucket.DeletePrefix(ctx, p.store, prefix); err != nil {
+				// The version is no longer in the index, left over files can be cleaned up with the reconcile command
+				slog.WarnContext(ctx, "Failed to delete files of removed provider version",
+					"provider", fmt.Sprintf("%s/%s", namespace, name), "version", version, "error", err)
+			}
+		}
+	} else {
+		if err := storage.MarkProviderVersionsRemoved(ctx, p.db, namespace, name, removed); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	slog.InfoContext(ctx, "Handled provider versions removed from the registry",
+		"provider", fmt.Sprintf("%s/%s", namespace, name), "versions", removed, "action", action)
+	return removed, nil
+}
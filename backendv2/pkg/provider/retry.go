@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/opentofu/registry-ui/pkg/provider/storage"
+	"github.com/opentofu/registry-ui/pkg/scrapeerr"
+)
+
+// retryableVersions returns the failed versions of a provider whose error class is retryable and whose retry backoff
+// has passed, see config.RetryConfig
+func (p *ProviderReader) retryableVersions(ctx context.Context, namespace, name string) ([]string, error) {
+	failed, err := storage.GetFailedProviderVersions(ctx, p.db, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var versions []string
+	for _, f := range failed {
+		// Failures that only a change in the repository fixes are left for retry-version
+		if !scrapeerr.Parse(f.ErrorClass).Retryable() || !p.config.Retry.Eligible(f.Attempts, f.LastAttemptAt, now) {
+			continue
+		}
+		slog.InfoContext(ctx, "Retrying failed provider version",
+			"provider", fmt.Sprintf("%s/%s", namespace, name),
+			"version", f.Version,
+			"attempts", f.Attempts,
+			"error_class", f.ErrorClass,
+			"last_attempt_at", f.LastAttemptAt)
+		versions = append(versions, f.Version)
+	}
+	return versions, nil
+}
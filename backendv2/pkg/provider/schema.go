@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/registry"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/tofu"
+)
+
+const (
+	// The tofu binary can only load providers built for the platform it runs on, which is linux/amd64 in CI
+	schemaTargetOS   = "linux"
+	schemaTargetArch = "amd64"
+
+	registryHostname = "registry.opentofu.org"
+)
+
+// ExtractSchema downloads the linux/amd64 release artifact of a provider version, verifies its checksum against
+// the registry and dumps the provider schema with tofu providers schema -json
+func (p *ProviderReader) ExtractSchema(ctx context.Context, provider *registry.Provider, version string) (*tofu.ProviderSchema, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "provider.extract_schema")
+	defer span.End()
+
+	namespace := provider.Namespace
+	name := provider.Name
+
+	span.SetAttributes(
+		attribute.String("provider.namespace", namespace),
+		attribute.String("provider.name", name),
+		attribute.String("provider.version", version),
+	)
+
+	target, ok := provider.Target(version, schemaTargetOS, schemaTargetArch)
+	if !ok {
+		err := fmt.Errorf("no %s_%s artifact for %s/%s %s", schemaTargetOS, schemaTargetArch, namespace, name, version)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	downloadURL, err := p.artifactURL(target.DownloadURL)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	tempDir, err := os.MkdirTemp(p.config.WorkDir, "provider-schema-")
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// tofu init -plugin-dir expects the packed layout: HOSTNAME/NAMESPACE/TYPE/terraform-provider-TYPE_VERSION_OS_ARCH.zip
+	plainVersion := strings.TrimPrefix(version, "v")
+	pluginDir := filepath.Join(tempDir, "plugins")
+	packageDir := filepath.Join(pluginDir, registryHostname, namespace, name)
+	if err := os.MkdirAll(packageDir, 0o755); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+	packagePath := filepath.Join(packageDir, fmt.Sprintf("terraform-provider-%s_%s_%s_%s.zip", name, plainVersion, schemaTargetOS, schemaTargetArch))
+
+	if err := downloadVerified(ctx, downloadURL, target.SHA256, packagePath); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	configDir := filepath.Join(tempDir, "config")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to create configuration directory: %w", err)
+	}
+
+	source := fmt.Sprintf("%s/%s/%s", registryHostname, namespace, name)
+	schema, stderr, err := tofu.ProvidersSchema(ctx, configDir, pluginDir, source, plainVersion)
+	if err != nil {
+		span.RecordError(err)
+		slog.DebugContext(ctx, "tofu providers schema failed",
+			"provider", fmt.Sprintf("%s/%s", namespace, name),
+			"version", version,
+			"stderr", stderr)
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("provider.schema.resources", len(schema.ResourceSchemas)),
+		attribute.Int("provider.schema.data_sources", len(schema.DataSourceSchemas)),
+		attribute.Int("provider.schema.ephemeral_resources", len(schema.EphemeralResourceSchemas)),
+		attribute.Int("provider.schema.functions", len(schema.Functions)),
+	)
+
+	return schema, nil
+}
+
+// artifactURL applies the configured download URL override to an artifact URL from the registry
+func (p *ProviderReader) artifactURL(downloadURL string) (string, error) {
+	if p.config.ProviderSchema.DownloadURL == "" {
+		return downloadURL, nil
+	}
+
+	override, err := url.Parse(p.config.ProviderSchema.DownloadURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid download URL override: %w", err)
+	}
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid download URL %s: %w", downloadURL, err)
+	}
+
+	u.Scheme = override.Scheme
+	u.Host = override.Host
+	return u.String(), nil
+}
+
+// downloadVerified downloads url to destination and checks that its SHA256 matches the expected hex digest
+func downloadVerified(ctx context.Context, url, expectedSHA256, destination string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request for %s: %w", url, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, url)
+	}
+
+	f, err := os.Create(destination)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", destination, err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), resp.Body); err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(actual, expectedSHA256) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", url, expectedSHA256, actual)
+	}
+
+	return nil
+}
+
+// uploadSchema uploads the schema of a provider version as schema.json next to its documentation
+func (p *ProviderReader) uploadSchema(ctx context.Context, namespace, name, version string, schema *tofu.ProviderSchema) error {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("failed to marshal provider schema: %w", err)
+	}
+
+	key := fmt.Sprintf("providers/%s/%s/%s/schema.json", namespace, name, version)
+	err = p.store.Put(ctx, bucket.Object{Key: key, Body: data, ContentType: "application/json"})
+	if err != nil {
+		return fmt.Errorf("failed to upload provider schema: %w", err)
+	}
+
+	return nil
+}
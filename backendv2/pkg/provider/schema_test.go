@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+)
+
+func TestArtifactURL(t *testing.T) {
+	const upstream = "https://github.com/example/terraform-provider-test/releases/download/v1.0.0/terraform-provider-test_1.0.0_linux_amd64.zip"
+
+	tests := []struct {
+		name     string
+		override string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "no override",
+			want: upstream,
+		},
+		{
+			name:     "local file server",
+			override: "http://127.0.0.1:8080",
+			want:     "http://127.0.0.1:8080/example/terraform-provider-test/releases/download/v1.0.0/terraform-provider-test_1.0.0_linux_amd64.zip",
+		},
+		{
+			name:     "override path is ignored",
+			override: "http://mirror.example.com/ignored",
+			want:     "http://mirror.example.com/example/terraform-provider-test/releases/download/v1.0.0/terraform-provider-test_1.0.0_linux_amd64.zip",
+		},
+		{
+			name:     "invalid override",
+			override: "http://[::1",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ProviderReader{config: &config.BackendConfig{ProviderSchema: config.ProviderSchemaConfig{DownloadURL: tt.override}}}
+			got, err := p.artifactURL(upstream)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("artifactURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("artifactURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadVerified(t *testing.T) {
+	artifact := []byte("provider zip contents")
+	sum := sha256.Sum256(artifact)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/terraform-provider-test_1.0.0_linux_amd64.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, "artifact.zip", time.Time{}, bytes.NewReader(artifact))
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name     string
+		path     string
+		checksum string
+		wantErr  string
+	}{
+		{
+			name:     "matching checksum",
+			path:     "/terraform-provider-test_1.0.0_linux_amd64.zip",
+			checksum: checksum,
+		},
+		{
+			name:     "uppercase checksum",
+			path:     "/terraform-provider-test_1.0.0_linux_amd64.zip",
+			checksum: strings.ToUpper(checksum),
+		},
+		{
+			name:     "checksum mismatch",
+			path:     "/terraform-provider-test_1.0.0_linux_amd64.zip",
+			checksum: strings.Repeat("0", 64),
+			wantErr:  "checksum mismatch",
+		},
+		{
+			name:     "missing artifact",
+			path:     "/missing.zip",
+			checksum: checksum,
+			wantErr:  "unexpected status code 404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destination := filepath.Join(t.TempDir(), "artifact.zip")
+			err := downloadVerified(context.Background(), server.URL+tt.path, tt.checksum, destination)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("downloadVerified() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("downloadVerified() error = %v", err)
+			}
+			got, err := os.ReadFile(destination)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, artifact) {
+				t.Errorf("downloaded %q, want %q", got, artifact)
+			}
+		})
+	}
+}
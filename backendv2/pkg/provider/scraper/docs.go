@@ -1,7 +1,6 @@
 package scraper
 
 import (
-	"bytes"
 	"context"
 	"crypto/md5"
 	"encoding/hex"
@@ -16,18 +15,18 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/opentofu/registry-ui/pkg/bucket"
 	"github.com/opentofu/registry-ui/pkg/config"
 	"github.com/opentofu/registry-ui/pkg/license"
 	"github.com/opentofu/registry-ui/pkg/provider/storage"
+	"github.com/opentofu/registry-ui/pkg/scrapeerr"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 // maxFileSize limits the amount of data read from the docs to 10MB to prevent memory-based DoS.
@@ -68,29 +67,37 @@ type DocItem struct {
 	Subcategory string `json:"subcategory,omitempty" yaml:"subcategory"`
 	Description string `json:"description,omitempty" yaml:"description"`
 	EditLink    string `json:"edit_link,omitempty" yaml:"edit_link"`
+	// FirstVersion and LastVersion are the oldest and newest completed provider versions containing this document when
+	// this version was indexed. resources.json holds the current range.
+	FirstVersion string `json:"first_version,omitempty" yaml:"-"`
+	LastVersion  string `json:"last_version,omitempty" yaml:"-"`
+	// Generated is set on pages built from the provider schema for providers without documentation
+	Generated   bool `json:"generated,omitempty" yaml:"-"`
 	contents    []byte
 	md5Checksum string // MD5 checksum of the document contents
 	isError     bool
 }
 
 type Scraper struct {
-	config   *config.BackendConfig
-	uploader *manager.Uploader
-	pool     *pgxpool.Pool
+	config *config.BackendConfig
+	store  bucket.Store
+	pool   *pgxpool.Pool
+	host   vcs.Host
 }
 
-func New(cfg *config.BackendConfig, uploader *manager.Uploader, pool *pgxpool.Pool) *Scraper {
+func New(cfg *config.BackendConfig, store bucket.Store, pool *pgxpool.Pool, host vcs.Host) *Scraper {
 	return &Scraper{
-		config:   cfg,
-		uploader: uploader,
-		pool:     pool,
+		config: cfg,
+		store:  store,
+		pool:   pool,
+		host:   host,
 	}
 }
 
 // StoreDocs uploads already-scraped documentation to S3 and stores metadata in the database.
 func (s *Scraper) StoreDocs(ctx context.Context, namespace, name, version string, docs map[string]*DocItem, licenses license.List, tx pgx.Tx) error {
 	if err := s.saveToBucket(ctx, namespace, name, version, docs); err != nil {
-		return err
+		return scrapeerr.Wrap(scrapeerr.Upload, err)
 	}
 	// Convert docs to storage format
 	storageDocs := make(map[string]*storage.DocItem)
@@ -102,14 +109,21 @@ func (s *Scraper) StoreDocs(ctx context.Context, namespace, name, version string
 			Description: doc.Description,
 			EditLink:    doc.EditLink,
 			MD5Checksum: doc.md5Checksum,
+			Generated:   doc.Generated,
 		}
 	}
 
 	if err := storage.StoreProviderDocuments(ctx, tx, namespace, name, version, storageDocs); err != nil {
-		return fmt.Errorf("failed to store documents in database: %w", err)
+		return scrapeerr.Wrap(scrapeerr.DB, fmt.Errorf("failed to store documents in database: %w", err))
+	}
+
+	// Includes this version, which is stored as completed with its documents in this transaction
+	availability, err := storage.GetDocumentAvailability(ctx, tx, namespace, name)
+	if err != nil {
+		return scrapeerr.Wrap(scrapeerr.DB, err)
 	}
 
-	return s.GenerateAndStoreIndex(ctx, namespace, name, version, docs, licenses)
+	return scrapeerr.Wrap(scrapeerr.Upload, s.GenerateAndStoreIndex(ctx, namespace, name, version, docs, licenses, availability))
 }
 
 func (s *Scraper) ScrapeDocumentation(ctx context.Context, namespace, name, version, directory string) (map[string]*DocItem, error) {
@@ -134,7 +148,7 @@ func (s *Scraper) ScrapeDocumentation(ctx context.Context, namespace, name, vers
 	}
 
 	docs := make(map[string]*DocItem)
-	repoURL := fmt.Sprintf("https://github.com/%s/terraform-provider-%s", namespace, name)
+	repo := vcs.ProviderRepo(namespace, name)
 
 	/*
 		Right now in the existing registry implementations, provider documentation can come in a couple different formats:
@@ -175,7 +189,7 @@ func (s *Scraper) ScrapeDocumentation(ctx context.Context, namespace, name, vers
 	} {
 		// Note: we prefer website/docs over docs and not include docs because some providers
 		// (such as AWS) use the docs directory for internal documentation.
-		foundDocs, err := s.scrapeDir(ctx, dir, readDirFS, docs, repoURL, version)
+		foundDocs, err := s.scrapeDir(ctx, dir, readDirFS, docs, repo, version)
 		if err != nil {
 			span.RecordError(err)
 			return nil, fmt.Errorf("failed to scrape directory %s: %w", dir, err)
@@ -193,7 +207,7 @@ func (s *Scraper) ScrapeDocumentation(ctx context.Context, namespace, name, vers
 }
 
 // scrapeDocTypes scrapes documentation for all configured doc types in the given base directory
-func (s *Scraper) scrapeDocTypes(ctx context.Context, fsys fs.ReadDirFS, baseDir string, docs map[string]*DocItem, repoURL, version, pathPrefix string) error {
+func (s *Scraper) scrapeDocTypes(ctx context.Context, fsys fs.ReadDirFS, baseDir string, docs map[string]*DocItem, repo vcs.Repo, version, pathPrefix string) error {
 	for _, docType := range storage.DocTypes {
 		for _, sourceDir := range docType.SourceDirs {
 			fullSourceDir := path.Join(baseDir, sourceDir)
@@ -203,7 +217,7 @@ func (s *Scraper) scrapeDocTypes(ctx context.Context, fsys fs.ReadDirFS, baseDir
 			}
 
 			// this is inefficient but it works for now
-			if err := s.scrapeType(ctx, fsys, fullSourceDir, targetPrefix, docs, repoURL, version); err != nil {
+			if err := s.scrapeType(ctx, fsys, fullSourceDir, targetPrefix, docs, repo, version); err != nil {
 				// Log debug message but don't fail - some directories may not exist
 				slog.DebugContext(ctx, "Failed to scrape directory", "directory", fullSourceDir, "error", err)
 			}
@@ -238,7 +252,7 @@ func (s *Scraper) saveToBucket(ctx context.Context, namespace, name, version str
 	return nil
 }
 
-func (s *Scraper) GenerateAndStoreIndex(ctx context.Context, namespace, name, version string, docs map[string]*DocItem, licenses license.List) error {
+func (s *Scraper) GenerateAndStoreIndex(ctx context.Context, namespace, name, version string, docs map[string]*DocItem, licenses license.List, availability map[string]*storage.DocAvailability) error {
 	ctx, span := telemetry.Tracer().Start(ctx, "provider_docs.generate_index")
 	defer span.End()
 
@@ -249,7 +263,7 @@ func (s *Scraper) GenerateAndStoreIndex(ctx context.Context, namespace, name, ve
 		attribute.Int("docs.count", len(docs)),
 	)
 
-	providerVersion := s.buildProviderVersionJSON(namespace, name, version, docs, licenses)
+	providerVersion := s.buildProviderVersionJSON(namespace, name, version, docs, licenses, availability)
 
 	jsonData, err := json.MarshalIndent(providerVersion, "", "  ")
 	if err != nil {
@@ -258,12 +272,7 @@ func (s *Scraper) GenerateAndStoreIndex(ctx context.Context, namespace, name, ve
 	}
 
 	key := fmt.Sprintf("providers/%s/%s/%s/index.json", namespace, name, version)
-	_, err = s.uploader.Upload(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(s.config.Bucket.BucketName),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(jsonData),
-		ContentType: aws.String("application/json"),
-	})
+	err = s.store.Put(ctx, bucket.Object{Key: key, Body: jsonData, ContentType: "application/json"})
 	if err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("failed to upload index.json to S3: %w", err)
@@ -282,7 +291,7 @@ func normalizeName(name string) string {
 	return normalizeRe.ReplaceAllString(name, "")
 }
 
-func (s *Scraper) scrapeDir(ctx context.Context, dir string, fsys fs.ReadDirFS, docs map[string]*DocItem, repoURL, version string) (bool, error) {
+func (s *Scraper) scrapeDir(ctx context.Context, dir string, fsys fs.ReadDirFS, docs map[string]*DocItem, repo vcs.Repo, version string) (bool, error) {
 	_, err := fsys.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -291,20 +300,20 @@ func (s *Scraper) scrapeDir(ctx context.Context, dir string, fsys fs.ReadDirFS,
 		return false, err
 	}
 
-	if err := s.scrapeDocumentation(ctx, fsys, dir, docs, repoURL, version); err != nil {
+	if err := s.scrapeDocumentation(ctx, fsys, dir, docs, repo, version); err != nil {
 		return true, fmt.Errorf("failed to scrape documentation directory: %w", err)
 	}
 
 	// Handle CDKTF documentation separately
 	cdktfDir := path.Join(dir, "cdktf")
-	if err := s.scrapeCDKTFDocumentation(ctx, fsys, cdktfDir, docs, repoURL, version); err != nil {
+	if err := s.scrapeCDKTFDocumentation(ctx, fsys, cdktfDir, docs, repo, version); err != nil {
 		return true, fmt.Errorf("failed to scrape CDKTF documentation: %w", err)
 	}
 
 	return true, nil
 }
 
-func (s *Scraper) scrapeCDKTFDocumentation(ctx context.Context, fsys fs.ReadDirFS, cdktfDir string, docs map[string]*DocItem, repoURL, version string) error {
+func (s *Scraper) scrapeCDKTFDocumentation(ctx context.Context, fsys fs.ReadDirFS, cdktfDir string, docs map[string]*DocItem, repo vcs.Repo, version string) error {
 	cdktfItems, err := fsys.ReadDir(cdktfDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -326,13 +335,13 @@ func (s *Scraper) scrapeCDKTFDocumentation(ctx context.Context, fsys fs.ReadDirF
 
 		langDir := path.Join(cdktfDir, lang)
 		indexPrefix := fmt.Sprintf("cdktf/%s", lang)
-		if err := s.extractRootDoc(ctx, fsys, langDir, docs, repoURL, version, indexPrefix); err != nil {
+		if err := s.extractRootDoc(ctx, fsys, langDir, docs, repo, version, indexPrefix); err != nil {
 			slog.WarnContext(ctx, "Failed to extract CDKTF index", "language", lang, "error", err)
 		}
 
 		// Scrape CDKTF documentation using unified approach
 		pathPrefix := fmt.Sprintf("cdktf/%s", lang)
-		if err := s.scrapeDocTypes(ctx, fsys, langDir, docs, repoURL, version, pathPrefix); err != nil {
+		if err := s.scrapeDocTypes(ctx, fsys, langDir, docs, repo, version, pathPrefix); err != nil {
 			slog.DebugContext(ctx, "Failed to scrape CDKTF documentation", "language", lang, "error", err)
 		}
 	}
@@ -340,21 +349,21 @@ func (s *Scraper) scrapeCDKTFDocumentation(ctx context.Context, fsys fs.ReadDirF
 	return nil
 }
 
-func (s *Scraper) scrapeDocumentation(ctx context.Context, fsys fs.ReadDirFS, dir string, docs map[string]*DocItem, repoURL, version string) error {
+func (s *Scraper) scrapeDocumentation(ctx context.Context, fsys fs.ReadDirFS, dir string, docs map[string]*DocItem, repo vcs.Repo, version string) error {
 	// Extract root document (index file)
-	if err := s.extractRootDoc(ctx, fsys, dir, docs, repoURL, version); err != nil {
+	if err := s.extractRootDoc(ctx, fsys, dir, docs, repo, version); err != nil {
 		return err
 	}
 
 	// Scrape different types of documentation using unified approach
-	if err := s.scrapeDocTypes(ctx, fsys, dir, docs, repoURL, version, ""); err != nil {
+	if err := s.scrapeDocTypes(ctx, fsys, dir, docs, repo, version, ""); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *Scraper) extractRootDoc(ctx context.Context, fsys fs.ReadDirFS, dir string, docs map[string]*DocItem, repoURL, version string, pathPrefixes ...string) error {
+func (s *Scraper) extractRootDoc(ctx context.Context, fsys fs.ReadDirFS, dir string, docs map[string]*DocItem, repo vcs.Repo, version string, pathPrefixes ...string) error {
 	pathPrefix := ""
 	if len(pathPrefixes) > 0 {
 		pathPrefix = pathPrefixes[0]
@@ -372,7 +381,7 @@ func (s *Scraper) extractRootDoc(ctx context.Context, fsys fs.ReadDirFS, dir str
 			continue
 		}
 		if strings.HasPrefix(item.Name(), "index.") {
-			doc, err := s.readDocFile(ctx, dir, item, fsys, repoURL, version)
+			doc, err := s.readDocFile(ctx, dir, item, fsys, repo, version)
 			if err != nil {
 				return fmt.Errorf("failed to parse %s: %w", item.Name(), err)
 			}
@@ -398,7 +407,7 @@ var suffixes = []string{
 	".markdown",
 }
 
-func (s *Scraper) scrapeType(ctx context.Context, fsys fs.ReadDirFS, dir, pathPrefix string, docs map[string]*DocItem, repoURL, version string) error {
+func (s *Scraper) scrapeType(ctx context.Context, fsys fs.ReadDirFS, dir, pathPrefix string, docs map[string]*DocItem, repo vcs.Repo, version string) error {
 	items, err := fsys.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -412,7 +421,7 @@ func (s *Scraper) scrapeType(ctx context.Context, fsys fs.ReadDirFS, dir, pathPr
 			continue
 		}
 
-		doc, err := s.readDocFile(ctx, dir, item, fsys, repoURL, version)
+		doc, err := s.readDocFile(ctx, dir, item, fsys, repo, version)
 		if err != nil {
 			return err
 		}
@@ -432,7 +441,7 @@ func (s *Scraper) scrapeType(ctx context.Context, fsys fs.ReadDirFS, dir, pathPr
 	return nil
 }
 
-func (s *Scraper) readDocFile(ctx context.Context, dir string, item fs.DirEntry, fsys fs.ReadDirFS, repoURL, version string) (*DocItem, error) {
+func (s *Scraper) readDocFile(ctx context.Context, dir string, item fs.DirEntry, fsys fs.ReadDirFS, repo vcs.Repo, version string) (*DocItem, error) {
 	fn := path.Join(dir, item.Name())
 	name := normalizeName(path.Base(fn))
 
@@ -464,8 +473,8 @@ func (s *Scraper) readDocFile(ctx context.Context, dir string, item fs.DirEntry,
 
 	var contents []byte
 	if stat.Size() > maxFileSize {
-		contents = fmt.Appendf(nil, "# File Too Large\n\nThis file is too large to display. View it directly in the repository: %s/blob/%s/%s",
-			repoURL, version, fn)
+		contents = fmt.Appendf(nil, "# File Too Large\n\nThis file is too large to display. View it directly in the repository: %s",
+			s.host.BlobURL(repo, version, fn))
 	} else {
 		contents, err = fs.ReadFile(fsys, fn)
 		if err != nil {
@@ -490,7 +499,7 @@ func (s *Scraper) readDocFile(ctx context.Context, dir string, item fs.DirEntry,
 	}
 
 	// Generate edit link
-	doc.EditLink = fmt.Sprintf("%s/blob/v%s/%s", repoURL, version, fn)
+	doc.EditLink = s.host.BlobURL(repo, "v"+version, fn)
 
 	return doc, nil
 }
@@ -561,11 +570,10 @@ func (s *Scraper) uploadDocToS3(ctx context.Context, namespace, name, version, f
 		}
 	}
 
-	_, err := s.uploader.Upload(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(s.config.Bucket.BucketName),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(doc.contents),
-		ContentType: aws.String("text/markdown"),
+	err := s.store.Put(ctx, bucket.Object{
+		Key:         key,
+		Body:        doc.contents,
+		ContentType: "text/markdown",
 		Metadata: map[string]string{
 			"title":       doc.Title,
 			"subcategory": doc.Subcategory,
@@ -582,9 +590,9 @@ func (s *Scraper) uploadDocToS3(ctx context.Context, namespace, name, version, f
 	return nil
 }
 
-func (s *Scraper) buildProviderVersionJSON(namespace, name, version string, docs map[string]*DocItem, licenses license.List) *ProviderVersion {
-	providerDocs := s.buildProviderDocs(docs)
-	cdktfDocs := s.buildCDKTFDocs(docs)
+func (s *Scraper) buildProviderVersionJSON(namespace, name, version string, docs map[string]*DocItem, licenses license.List, availability map[string]*storage.DocAvailability) *ProviderVersion {
+	providerDocs := s.buildProviderDocs(docs, availability)
+	cdktfDocs := s.buildCDKTFDocs(docs, availability)
 
 	return &ProviderVersion{
 		ID:                  version,
@@ -596,11 +604,11 @@ func (s *Scraper) buildProviderVersionJSON(namespace, name, version string, docs
 		CDKTFDocs:           cdktfDocs,
 		License:             licenses,
 		IncompatibleLicense: !licenses.IsRedistributable(),
-		Link:                fmt.Sprintf("https://github.com/%s/terraform-provider-%s", namespace, name),
+		Link:                s.host.RepoURL(vcs.ProviderRepo(namespace, name)),
 	}
 }
 
-func (s *Scraper) buildProviderDocs(docs map[string]*DocItem) ProviderDocs {
+func (s *Scraper) buildProviderDocs(docs map[string]*DocItem, availability map[string]*storage.DocAvailability) ProviderDocs {
 	result := ProviderDocs{}
 	resultVal := reflect.ValueOf(&result).Elem()
 
@@ -616,9 +624,11 @@ func (s *Scraper) buildProviderDocs(docs map[string]*DocItem) ProviderDocs {
 			Title:       doc.Title,
 			Subcategory: doc.Subcategory,
 			Description: doc.Description,
+			Generated:   doc.Generated,
 		}
 
 		category := storage.GetDocCategory(filePath)
+		setAvailability(&docItem, category, availability)
 		if category == "index" {
 			result.Index = &docItem
 			continue
@@ -630,6 +640,14 @@ func (s *Scraper) buildProviderDocs(docs map[string]*DocItem) ProviderDocs {
 	return result
 }
 
+// setAvailability fills in the version range in which a document appears
+func setAvailability(docItem *DocItem, category string, availability map[string]*storage.DocAvailability) {
+	if a, ok := availability[category+":"+docItem.Name]; ok {
+		docItem.FirstVersion = a.FirstVersion
+		docItem.LastVersion = a.LastVersion
+	}
+}
+
 // appendDocToField uses reflection to append a DocItem to the appropriate field in ProviderDocs
 // based on the category. The field name is looked up from the storage.DocTypes map.
 func appendDocToField(resultVal reflect.Value, category string, docItem DocItem) {
@@ -644,7 +662,7 @@ func appendDocToField(resultVal reflect.Value, category string, docItem DocItem)
 	}
 }
 
-func (s *Scraper) buildCDKTFDocs(docs map[string]*DocItem) map[string]ProviderDocs {
+func (s *Scraper) buildCDKTFDocs(docs map[string]*DocItem, availability map[string]*storage.DocAvailability) map[string]ProviderDocs {
 	result := map[string]ProviderDocs{}
 
 	for filePath, doc := range docs {
@@ -669,11 +687,13 @@ func (s *Scraper) buildCDKTFDocs(docs map[string]*DocItem) map[string]ProviderDo
 			Title:       doc.Title,
 			Subcategory: doc.Subcategory,
 			Description: doc.Description,
+			Generated:   doc.Generated,
 		}
 
 		// Categorize based on remaining path (everything after cdktf/<language>/)
 		_, remainingPath, _ := strings.Cut(filePath, "cdktf/"+language+"/")
 		category := storage.GetDocCategory(remainingPath)
+		setAvailability(&docItem, category, availability)
 		if category == "index" {
 			langDocs.Index = &docItem
 		} else {
@@ -2,6 +2,8 @@ package scraper
 
 import (
 	"testing"
+
+	"github.com/opentofu/registry-ui/pkg/provider/storage"
 )
 
 func TestNormalizeName(t *testing.T) {
@@ -157,3 +159,53 @@ Manages projects.
 		})
 	}
 }
+
+func TestBuildProviderDocsAvailability(t *testing.T) {
+	docs := map[string]*DocItem{
+		"index.md":                           {Name: "index"},
+		"resources/instance.md":              {Name: "instance"},
+		"resources/vpc.md":                   {Name: "vpc"},
+		"cdktf/python/resources/instance.md": {Name: "instance"},
+	}
+	availability := map[string]*storage.DocAvailability{
+		"index:index":        {Type: "index", Name: "index", FirstVersion: "1.0.0", LastVersion: "2.0.0"},
+		"resources:instance": {Type: "resources", Name: "instance", FirstVersion: "1.2.0", LastVersion: "2.0.0"},
+	}
+
+	s := &Scraper{}
+	result := s.buildProviderDocs(docs, availability)
+	cdktf := s.buildCDKTFDocs(docs, availability)
+
+	tests := []struct {
+		name      string
+		item      *DocItem
+		wantFirst string
+		wantLast  string
+	}{
+		{"index", result.Index, "1.0.0", "2.0.0"},
+		{"resource", findDoc(result.Resources, "instance"), "1.2.0", "2.0.0"},
+		{"cdktf resource", findDoc(cdktf["python"].Resources, "instance"), "1.2.0", "2.0.0"},
+		// Documents missing from the availability are left without a range
+		{"unknown document", findDoc(result.Resources, "vpc"), "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.item == nil {
+				t.Fatal("document not found")
+			}
+			if tt.item.FirstVersion != tt.wantFirst || tt.item.LastVersion != tt.wantLast {
+				t.Errorf("range = %q..%q, want %q..%q", tt.item.FirstVersion, tt.item.LastVersion, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
+
+func findDoc(items []DocItem, name string) *DocItem {
+	for i := range items {
+		if items[i].Name == name {
+			return &items[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package scraper
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/opentofu/registry-ui/pkg/tofu"
+)
+
+const generatedNotice = "-> This page was generated from the provider schema because the provider does not publish documentation."
+
+// GenerateDocsFromSchema builds minimal markdown pages from a provider schema for providers that do not publish any
+// documentation. It produces an index page for the provider configuration and one page per resource, data source,
+// ephemeral resource and function, keyed the same way as scraped docs. All returned docs are marked as generated.
+func GenerateDocsFromSchema(name string, schema *tofu.ProviderSchema) map[string]*DocItem {
+	docs := make(map[string]*DocItem)
+	if schema == nil {
+		return docs
+	}
+
+	if schema.Provider != nil {
+		title := fmt.Sprintf("%s Provider", name)
+		docs["index"] = newGeneratedDoc("index", title, blockDescription(schema.Provider.Block),
+			renderSchemaPage(fmt.Sprintf("%s Provider", name), schema.Provider.Block))
+	}
+
+	for _, kind := range []struct {
+		targetPath string
+		label      string
+		schemas    map[string]*tofu.Schema
+	}{
+		{"resources", "Resource", schema.ResourceSchemas},
+		{"datasources", "Data Source", schema.DataSourceSchemas},
+		{"ephemeral", "Ephemeral Resource", schema.EphemeralResourceSchemas},
+	} {
+		for typeName, s := range kind.schemas {
+			docName := generatedDocName(name, typeName)
+			title := fmt.Sprintf("%s %s - terraform-provider-%s", typeName, kind.label, name)
+			docs[path.Join(kind.targetPath, docName)] = newGeneratedDoc(docName, title, blockDescription(s.Block),
+				renderSchemaPage(fmt.Sprintf("%s (%s)", typeName, kind.label), s.Block))
+		}
+	}
+
+	for fnName, fn := range schema.Functions {
+		title := fmt.Sprintf("%s function - terraform-provider-%s", fnName, name)
+		description := fn.Summary
+		if description == "" {
+			description = fn.Description
+		}
+		docs[path.Join("functions", fnName)] = newGeneratedDoc(fnName, title, description, renderFunctionPage(fnName, fn))
+	}
+
+	return docs
+}
+
+func newGeneratedDoc(name, title, description, contents string) *DocItem {
+	hash := md5.Sum([]byte(contents))
+	return &DocItem{
+		Name:        name,
+		Title:       title,
+		Description: description,
+		Generated:   true,
+		contents:    []byte(contents),
+		md5Checksum: hex.EncodeToString(hash[:]),
+	}
+}
+
+// generatedDocName strips the provider name prefix from a resource type, matching the file names used in docs/
+// (e.g. aws_instance is documented in docs/resources/instance.md)
+func generatedDocName(providerName, typeName string) string {
+	if short, ok := strings.CutPrefix(typeName, providerName+"_"); ok && short != "" {
+		return short
+	}
+	return typeName
+}
+
+func blockDescription(block *tofu.SchemaBlock) string {
+	if block == nil {
+		return ""
+	}
+	return block.Description
+}
+
+func renderSchemaPage(heading string, block *tofu.SchemaBlock) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n\n", heading, generatedNotice)
+	if block == nil {
+		return b.String()
+	}
+
+	if block.Deprecated {
+		b.WriteString("~> **Deprecated** This is deprecated and may be removed in a future version.\n\n")
+	}
+	if block.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", block.Description)
+	}
+
+	b.WriteString("## Schema\n\n")
+
+	// Nested blocks and attributes are rendered in their own sections after the top level
+	type nested struct {
+		path  string
+		block *tofu.SchemaBlock
+		attrs map[string]*tofu.SchemaAttribute
+	}
+	queue := []nested{{block: block}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current.path != "" {
+			fmt.Fprintf(&b, "<a id=\"nested--%s\"></a>\n### Nested Schema for `%s`\n\n", strings.ReplaceAll(current.path, ".", "--"), current.path)
+		}
+
+		var required, optional, readOnly []string
+		attrs := current.attrs
+		if current.block != nil {
+			attrs = current.block.Attributes
+		}
+
+		for _, attrName := range slices.Sorted(maps.Keys(attrs)) {
+			attr := attrs[attrName]
+			line := fmt.Sprintf("- `%s` (%s)%s", attrName, attributeTypeLabel(attr), descriptionSuffix(attr.Description, attr.Deprecated))
+			switch {
+			case attr.Required:
+				required = append(required, line)
+			case attr.Optional:
+				optional = append(optional, line)
+			default:
+				readOnly = append(readOnly, line)
+			}
+			if attr.NestedType != nil {
+				queue = append(queue, nested{path: path.Join(current.path, attrName), attrs: attr.NestedType.Attributes})
+			}
+		}
+
+		if current.block != nil {
+			for _, blockName := range slices.Sorted(maps.Keys(current.block.BlockTypes)) {
+				blockType := current.block.BlockTypes[blockName]
+				var deprecated bool
+				var description string
+				if blockType.Block != nil {
+					deprecated = blockType.Block.Deprecated
+					description = blockType.Block.Description
+				}
+				line := fmt.Sprintf("- `%s` (%s)%s", blockName, blockTypeLabel(blockType), descriptionSuffix(description, deprecated))
+				if blockType.MinItems > 0 {
+					required = append(required, line)
+				} else {
+					optional = append(optional, line)
+				}
+				if blockType.Block != nil {
+					queue = append(queue, nested{path: path.Join(current.path, blockName), block: blockType.Block})
+				}
+			}
+		}
+
+		for _, section := range []struct {
+			title string
+			lines []string
+		}{
+			{"Required", required},
+			{"Optional", optional},
+			{"Read-Only", readOnly},
+		} {
+			if len(section.lines) == 0 {
+				continue
+			}
+			level := "###"
+			if current.path != "" {
+				level = "####"
+			}
+			fmt.Fprintf(&b, "%s %s\n\n%s\n\n", level, section.title, strings.Join(section.lines, "\n"))
+		}
+	}
+
+	return b.String()
+}
+
+func renderFunctionPage(name string, fn *tofu.FunctionSignature) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# function: %s\n\n%s\n\n", name, generatedNotice)
+
+	if fn.DeprecationMessage != "" {
+		fmt.Fprintf(&b, "~> **Deprecated** %s\n\n", fn.DeprecationMessage)
+	}
+	if fn.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", fn.Description)
+	} else if fn.Summary != "" {
+		fmt.Fprintf(&b, "%s\n\n", fn.Summary)
+	}
+
+	params := make([]string, 0, len(fn.Parameters)+1)
+	for _, param := range fn.Parameters {
+		params = append(params, fmt.Sprintf("%s %s", param.Name, typeName(param.Type)))
+	}
+	if fn.VariadicParameter != nil {
+		params = append(params, fmt.Sprintf("%s %s...", fn.VariadicParameter.Name, typeName(fn.VariadicParameter.Type)))
+	}
+	fmt.Fprintf(&b, "## Signature\n\n```text\n%s(%s) %s\n```\n\n", name, strings.Join(params, ", "), typeName(fn.ReturnType))
+
+	if len(params) > 0 {
+		b.WriteString("## Arguments\n\n")
+		for i, param := range fn.Parameters {
+			fmt.Fprintf(&b, "%d. `%s` (%s)%s\n", i+1, param.Name, parameterLabel(param, false), descriptionSuffix(param.Description, false))
+		}
+		if param := fn.VariadicParameter; param != nil {
+			fmt.Fprintf(&b, "%d. `%s` (%s)%s\n", len(fn.Parameters)+1, param.Name, parameterLabel(param, true), descriptionSuffix(param.Description, false))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func parameterLabel(param *tofu.FunctionParameter, variadic bool) string {
+	label := typeName(param.Type)
+	if variadic {
+		label = "Variadic, " + label
+	}
+	if param.IsNullable {
+		label += ", Nullable"
+	}
+	return label
+}
+
+func descriptionSuffix(description string, deprecated bool) string {
+	var suffix string
+	if deprecated {
+		suffix = " **Deprecated**"
+	}
+	if description != "" {
+		suffix += " " + strings.ReplaceAll(strings.TrimSpace(description), "\n", " ")
+	}
+	return suffix
+}
+
+func attributeTypeLabel(attr *tofu.SchemaAttribute) string {
+	var label string
+	if attr.NestedType != nil {
+		label = "Attributes"
+		if mode := nestingModeLabel(attr.NestedType.NestingMode); mode != "" {
+			label += " " + mode
+		}
+	} else {
+		label = typeName(attr.Type)
+	}
+	if attr.Sensitive {
+		label += ", Sensitive"
+	}
+	return label
+}
+
+func blockTypeLabel(blockType *tofu.SchemaBlockType) string {
+	label := "Block"
+	if mode := nestingModeLabel(blockType.NestingMode); mode != "" {
+		label += " " + mode
+	}
+	if blockType.MinItems > 0 {
+		label += fmt.Sprintf(", Min: %d", blockType.MinItems)
+	}
+	if blockType.MaxItems > 0 {
+		label += fmt.Sprintf(", Max: %d", blockType.MaxItems)
+	}
+	return label
+}
+
+func nestingModeLabel(mode string) string {
+	switch mode {
+	case "list":
+		return "List"
+	case "set":
+		return "Set"
+	case "map":
+		return "Map"
+	default:
+		// single and group nest a single object
+		return ""
+	}
+}
+
+// typeName renders a type in its cty JSON representation, e.g. "string" or ["list","string"], for humans
+func typeName(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return "Dynamic"
+	}
+
+	var primitive string
+	if err := json.Unmarshal(raw, &primitive); err == nil {
+		switch primitive {
+		case "string":
+			return "String"
+		case "number":
+			return "Number"
+		case "bool":
+			return "Boolean"
+		case "dynamic":
+			return "Dynamic"
+		default:
+			return primitive
+		}
+	}
+
+	var complex []json.RawMessage
+	if err := json.Unmarshal(raw, &complex); err != nil || len(complex) == 0 {
+		return "Dynamic"
+	}
+
+	var kind string
+	if err := json.Unmarshal(complex[0], &kind); err != nil {
+		return "Dynamic"
+	}
+
+	switch kind {
+	case "list", "set", "map":
+		label := strings.ToUpper(kind[:1]) + kind[1:]
+		if len(complex) > 1 {
+			return fmt.Sprintf("%s of %s", label, typeName(complex[1]))
+		}
+		return label
+	case "object":
+		return "Object"
+	case "tuple":
+		return "Tuple"
+	default:
+		return kind
+	}
+}
@@ -0,0 +1,142 @@
+package scraper
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/registry-ui/pkg/tofu"
+)
+
+const fixtureSchema = `{
+	"provider": {
+		"version": 0,
+		"block": {
+			"attributes": {
+				"token": {"type": "string", "description": "API token", "optional": true, "sensitive": true}
+			}
+		}
+	},
+	"resource_schemas": {
+		"example_server": {
+			"version": 0,
+			"block": {
+				"description": "Manages a server.",
+				"attributes": {
+					"id": {"type": "string", "computed": true},
+					"name": {"type": "string", "description": "Server name", "required": true},
+					"tags": {"type": ["map", "string"], "optional": true},
+					"legacy": {"type": "bool", "optional": true, "deprecated": true}
+				},
+				"block_types": {
+					"disk": {
+						"nesting_mode": "list",
+						"max_items": 1,
+						"block": {
+							"attributes": {
+								"size": {"type": "number", "required": true}
+							}
+						}
+					}
+				}
+			}
+		}
+	},
+	"data_source_schemas": {
+		"example_image": {"version": 0, "block": {"attributes": {"name": {"type": "string", "required": true}}}}
+	},
+	"ephemeral_resource_schemas": {
+		"example_secret": {"version": 0, "block": {"attributes": {"value": {"type": "string", "computed": true, "sensitive": true}}}}
+	},
+	"functions": {
+		"parse_id": {
+			"summary": "Parses an ID",
+			"return_type": ["object", {"name": "string"}],
+			"parameters": [{"name": "id", "type": "string"}]
+		}
+	}
+}`
+
+func TestGenerateDocsFromSchema(t *testing.T) {
+	var schema tofu.ProviderSchema
+	if err := json.Unmarshal([]byte(fixtureSchema), &schema); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	docs := GenerateDocsFromSchema("example", &schema)
+
+	tests := []struct {
+		name     string
+		key      string
+		docName  string
+		contains []string
+	}{
+		{
+			name:     "index",
+			key:      "index",
+			docName:  "index",
+			contains: []string{"# example Provider", "- `token` (String, Sensitive) API token"},
+		},
+		{
+			name:    "resource",
+			key:     "resources/server",
+			docName: "server",
+			contains: []string{
+				"# example_server (Resource)",
+				"Manages a server.",
+				"### Required\n\n- `name` (String) Server name",
+				"- `legacy` (Boolean) **Deprecated**",
+				"- `tags` (Map of String)",
+				"- `disk` (Block List, Max: 1)",
+				"### Read-Only\n\n- `id` (String)",
+				"### Nested Schema for `disk`",
+				"- `size` (Number)",
+			},
+		},
+		{
+			name:     "data source",
+			key:      "datasources/image",
+			docName:  "image",
+			contains: []string{"# example_image (Data Source)", "- `name` (String)"},
+		},
+		{
+			name:     "ephemeral resource",
+			key:      "ephemeral/secret",
+			docName:  "secret",
+			contains: []string{"# example_secret (Ephemeral Resource)", "- `value` (String, Sensitive)"},
+		},
+		{
+			name:     "function",
+			key:      "functions/parse_id",
+			docName:  "parse_id",
+			contains: []string{"# function: parse_id", "parse_id(id String) Object", "1. `id` (String)"},
+		},
+	}
+
+	if len(docs) != len(tests) {
+		t.Errorf("expected %d docs, got %d", len(tests), len(docs))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, ok := docs[tt.key]
+			if !ok {
+				t.Fatalf("expected doc %q to be generated", tt.key)
+			}
+			if doc.Name != tt.docName {
+				t.Errorf("expected name %q, got %q", tt.docName, doc.Name)
+			}
+			if !doc.Generated {
+				t.Errorf("expected doc to be marked as generated")
+			}
+			if doc.md5Checksum == "" {
+				t.Errorf("expected checksum to be set")
+			}
+			for _, expected := range tt.contains {
+				if !strings.Contains(string(doc.contents), expected) {
+					t.Errorf("expected contents to contain %q, got:\n%s", expected, doc.contents)
+				}
+			}
+		})
+	}
+}
@@ -25,11 +25,12 @@ type Queryable interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
-// StoreProviderVersion stores provider version information in the database
-func StoreProviderVersion(ctx context.Context, tx pgx.Tx, namespace, name, version string, docCount, licenseCount int, tagCreatedAt *time.Time, licenseAccepted bool, scrapeStatus, skipReason, errorMessage string) error {
+// StoreProviderVersion stores provider version information in the database. errorClass is the scrapeerr.Class of
+// failed versions and empty otherwise. The attempt count grows with every failure and is reset by any other status.
+func StoreProviderVersion(ctx context.Context, tx pgx.Tx, namespace, name, version string, docCount, licenseCount int, tagCreatedAt *time.Time, licenseAccepted bool, scrapeStatus, skipReason, errorMessage, errorClass string) error {
 	query := `
-		INSERT INTO provider_versions (provider_namespace, provider_name, version, tag_created_at, license_accepted, scrape_status, skip_reason, error_message, last_attempt_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		INSERT INTO provider_versions (provider_namespace, provider_name, version, tag_created_at, license_accepted, scrape_status, skip_reason, error_message, error_class, last_attempt_at, attempt_count)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NOW(), CASE WHEN $6 = 'failed' THEN 1 ELSE 0 END)
 		ON CONFLICT (provider_namespace, provider_name, version)
 		DO UPDATE SET
 			tag_created_at = EXCLUDED.tag_created_at,
@@ -37,10 +38,12 @@ func StoreProviderVersion(ctx context.Context, tx pgx.Tx, namespace, name, versi
 			scrape_status = EXCLUDED.scrape_status,
 			skip_reason = EXCLUDED.skip_reason,
 			error_message = EXCLUDED.error_message,
+			error_class = EXCLUDED.error_class,
 			last_attempt_at = NOW(),
+			attempt_count = CASE WHEN EXCLUDED.scrape_status = 'failed' THEN provider_versions.attempt_count + 1 ELSE 0 END,
 			updated_at = NOW()`
 
-	_, err := tx.Exec(ctx, query, namespace, name, version, tagCreatedAt, licenseAccepted, scrapeStatus, skipReason, errorMessage)
+	_, err := tx.Exec(ctx, query, namespace, name, version, tagCreatedAt, licenseAccepted, scrapeStatus, skipReason, errorMessage, errorClass)
 	if err != nil {
 		return fmt.Errorf("failed to store provider version: %w", err)
 	}
@@ -103,7 +106,8 @@ type ProviderVersionInfo struct {
 }
 
 // GetExistingProviderVersions returns all versions that already exist in the database for a given provider
-// Includes 'failed' status to avoid retrying failed versions on every run
+// Includes 'failed' status to avoid retrying failed versions on every run, see GetFailedProviderVersions for the
+// failed versions that are due for a retry
 func GetExistingProviderVersions(ctx context.Context, db interface {
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 }, namespace, name string,
@@ -147,6 +151,7 @@ func UpdateProviderVersionStatus(ctx context.Context, db interface {
 		SET scrape_status = $4,
 		    skip_reason = $5,
 		    error_message = $6,
+		    error_class = CASE WHEN $4 = 'failed' THEN error_class END,
 		    last_attempt_at = NOW(),
 		    updated_at = NOW()
 		WHERE provider_namespace = $1
@@ -165,6 +170,74 @@ func UpdateProviderVersionStatus(ctx context.Context, db interface {
 	return nil
 }
 
+// MarkProviderVersionsRemoved sets the 'removed' status on versions that are no longer listed in the registry
+func MarkProviderVersionsRemoved(ctx context.Context, db Queryable, namespace, name string, versions []string) error {
+	_, err := db.Exec(ctx, `
+		UPDATE provider_versions
+		SET scrape_status = 'removed',
+		    updated_at = NOW()
+		WHERE provider_namespace = $1
+		  AND provider_name = $2
+		  AND version = ANY($3)`, namespace, name, versions)
+	if err != nil {
+		return fmt.Errorf("failed to mark provider versions as removed: %w", err)
+	}
+	return nil
+}
+
+// DeleteProviderVersions deletes versions and their related records (documents, licenses, schemas)
+func DeleteProviderVersions(ctx context.Context, db Queryable, namespace, name string, versions []string) error {
+	_, err := db.Exec(ctx, `
+		DELETE FROM provider_versions
+		WHERE provider_namespace = $1
+		  AND provider_name = $2
+		  AND version = ANY($3)`, namespace, name, versions)
+	if err != nil {
+		return fmt.Errorf("failed to delete provider versions: %w", err)
+	}
+	return nil
+}
+
+// FailedVersion is a provider version whose last scraping attempt failed
+type FailedVersion struct {
+	Namespace     string
+	Name          string
+	Version       string
+	ErrorMessage  string
+	ErrorClass    string
+	Attempts      int
+	LastAttemptAt time.Time
+}
+
+// GetFailedProviderVersions returns the failed versions of a provider, or of all providers when namespace is empty
+func GetFailedProviderVersions(ctx context.Context, db Queryable, namespace, name string) ([]FailedVersion, error) {
+	rows, err := db.Query(ctx, `
+		SELECT provider_namespace, provider_name, version, COALESCE(error_message, ''), COALESCE(error_class, ''), attempt_count,
+		       COALESCE(last_attempt_at, updated_at, NOW())
+		FROM provider_versions
+		WHERE scrape_status = 'failed'
+		  AND ($1 = '' OR (provider_namespace = $1 AND provider_name = $2))
+		ORDER BY provider_namespace, provider_name, version`, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failed provider versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []FailedVersion
+	for rows.Next() {
+		var v FailedVersion
+		if err := rows.Scan(&v.Namespace, &v.Name, &v.Version, &v.ErrorMessage, &v.ErrorClass, &v.Attempts, &v.LastAttemptAt); err != nil {
+			return nil, fmt.Errorf("failed to scan failed provider version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over failed provider versions: %w", err)
+	}
+
+	return versions, nil
+}
+
 // ProviderVersionStatusInfo represents status information about a provider version
 type ProviderVersionStatusInfo struct {
 	Version         string
@@ -183,6 +256,7 @@ type DocItem struct {
 	Description string
 	EditLink    string
 	MD5Checksum string
+	Generated   bool
 }
 
 // DocTypeConfig defines the configuration for a documentation type
@@ -280,8 +354,8 @@ func StoreProviderDocuments(ctx context.Context, tx pgx.Tx, namespace, name, ver
 		batch.Queue(`
 			INSERT INTO provider_documents
 			(provider_namespace, provider_name, version, document_type, document_name,
-			 title, subcategory, description, edit_link, s3_key, language, md5_checksum)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			 title, subcategory, description, edit_link, s3_key, language, md5_checksum, generated)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 			ON CONFLICT (provider_namespace, provider_name, version, document_type, document_name, language)
 			DO UPDATE SET
 				title = EXCLUDED.title,
@@ -290,9 +364,10 @@ func StoreProviderDocuments(ctx context.Context, tx pgx.Tx, namespace, name, ver
 				edit_link = EXCLUDED.edit_link,
 				s3_key = EXCLUDED.s3_key,
 				md5_checksum = EXCLUDED.md5_checksum,
+				generated = EXCLUDED.generated,
 				updated_at = NOW()
 		`, namespace, name, version, docType, doc.Name,
-			doc.Title, doc.Subcategory, doc.Description, doc.EditLink, s3Key, language, doc.MD5Checksum)
+			doc.Title, doc.Subcategory, doc.Description, doc.EditLink, s3Key, language, doc.MD5Checksum, doc.Generated)
 	}
 
 	batchResults := tx.SendBatch(ctx, batch)
@@ -397,6 +472,58 @@ func GetAllDocumentChecksums(ctx context.Context, db interface {
 	return checksums, nil
 }
 
+// DocAvailability is the range of provider versions in which a document appears
+type DocAvailability struct {
+	Type         string `json:"type"`
+	Name         string `json:"name"`
+	FirstVersion string `json:"first_version"`
+	LastVersion  string `json:"last_version"`
+	VersionCount int    `json:"version_count"`
+}
+
+// GetDocumentAvailability returns the first and last version in which every document of a provider appears,
+// regardless of language. Only completed versions count, documents left over from failed, skipped or removed
+// versions are ignored. Returns a map with key "docType:docName".
+func GetDocumentAvailability(ctx context.Context, db Queryable, namespace, name string) (map[string]*DocAvailability, error) {
+	query := `
+		SELECT
+			d.document_type,
+			d.document_name,
+			(array_agg(d.version ORDER BY safe_to_semver(d.version) ASC))[1],
+			(array_agg(d.version ORDER BY safe_to_semver(d.version) DESC))[1],
+			COUNT(DISTINCT d.version)
+		FROM provider_documents d
+		JOIN provider_versions v
+		  ON v.provider_namespace = d.provider_namespace
+		 AND v.provider_name = d.provider_name
+		 AND v.version = d.version
+		WHERE d.provider_namespace = $1
+		  AND d.provider_name = $2
+		  AND v.scrape_status = 'completed'
+		GROUP BY d.document_type, d.document_name`
+
+	rows, err := db.Query(ctx, query, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query document availability: %w", err)
+	}
+	defer rows.Close()
+
+	availability := make(map[string]*DocAvailability)
+	for rows.Next() {
+		var a DocAvailability
+		if err := rows.Scan(&a.Type, &a.Name, &a.FirstVersion, &a.LastVersion, &a.VersionCount); err != nil {
+			return nil, fmt.Errorf("failed to scan document availability: %w", err)
+		}
+		availability[a.Type+":"+a.Name] = &a
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document availability: %w", err)
+	}
+
+	return availability, nil
+}
+
 // StoreProviderLicenses stores all detected license candidates for a provider version.
 // is_selected is set to true only for the authoritative license(s) as determined by
 // baseThreshold and overrideThreshold (see license.List.Selected).
@@ -460,3 +587,34 @@ func StoreProviderLicenses(ctx context.Context, tx pgx.Tx, namespace, name, vers
 
 	return nil
 }
+
+// GetAdjacentProviderVersions returns the completed versions directly before and after a version in semver order.
+// Either is empty if there is no such version.
+func GetAdjacentProviderVersions(ctx context.Context, db Queryable, namespace, name, version string) (string, string, error) {
+	query := `
+		SELECT
+			(SELECT version FROM provider_versions
+			 WHERE provider_namespace = $1 AND provider_name = $2 AND scrape_status = 'completed'
+			   AND safe_to_semver(version) < safe_to_semver($3)
+			 ORDER BY safe_to_semver(version) DESC
+			 LIMIT 1),
+			(SELECT version FROM provider_versions
+			 WHERE provider_namespace = $1 AND provider_name = $2 AND scrape_status = 'completed'
+			   AND safe_to_semver(version) > safe_to_semver($3)
+			 ORDER BY safe_to_semver(version) ASC
+			 LIMIT 1)`
+
+	var previous, next *string
+	if err := db.QueryRow(ctx, query, namespace, name, version).Scan(&previous, &next); err != nil {
+		return "", "", fmt.Errorf("failed to query adjacent provider versions: %w", err)
+	}
+
+	var previousVersion, nextVersion string
+	if previous != nil {
+		previousVersion = *previous
+	}
+	if next != nil {
+		nextVersion = *next
+	}
+	return previousVersion, nextVersion, nil
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/tofu"
+)
+
+// Schema types stored in provider_schemas.schema_type
+const (
+	SchemaTypeProvider          = "provider"
+	SchemaTypeResource          = "resource"
+	SchemaTypeDataSource        = "data_source"
+	SchemaTypeEphemeralResource = "ephemeral_resource"
+	SchemaTypeFunction          = "function"
+)
+
+// StoreProviderSchema stores the schema of every resource, data source, ephemeral resource and function of a
+// provider version, replacing any schema stored previously for that version
+func StoreProviderSchema(ctx context.Context, tx pgx.Tx, namespace, name, version string, schema *tofu.ProviderSchema) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "provider.storage.store_provider_schema")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("provider.namespace", namespace),
+		attribute.String("provider.name", name),
+		attribute.String("provider.version", version),
+	)
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM provider_schemas
+		WHERE provider_namespace = $1 AND provider_name = $2 AND version = $3`,
+		namespace, name, version)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to clear provider schema: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	queue := func(schemaType, schemaName string, value any) error {
+		contents, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s schema %s: %w", schemaType, schemaName, err)
+		}
+		batch.Queue(`
+			INSERT INTO provider_schemas
+			(provider_namespace, provider_name, version, schema_type, schema_name, schema)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			namespace, name, version, schemaType, schemaName, contents)
+		return nil
+	}
+
+	if schema.Provider != nil {
+		if err := queue(SchemaTypeProvider, name, schema.Provider); err != nil {
+			return err
+		}
+	}
+	for schemaName, s := range schema.ResourceSchemas {
+		if err := queue(SchemaTypeResource, schemaName, s); err != nil {
+			return err
+		}
+	}
+	for schemaName, s := range schema.DataSourceSchemas {
+		if err := queue(SchemaTypeDataSource, schemaName, s); err != nil {
+			return err
+		}
+	}
+	for schemaName, s := range schema.EphemeralResourceSchemas {
+		if err := queue(SchemaTypeEphemeralResource, schemaName, s); err != nil {
+			return err
+		}
+	}
+	for schemaName, s := range schema.Functions {
+		if err := queue(SchemaTypeFunction, schemaName, s); err != nil {
+			return err
+		}
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to insert provider schema: %w", err)
+	}
+
+	// Transaction commit is handled by the caller
+
+	span.SetAttributes(attribute.Int("schema.inserted_count", batch.Len()))
+	slog.DebugContext(ctx, "Stored provider schema in database",
+		"namespace", namespace, "name", name, "version", version, "schemas", batch.Len())
+
+	return nil
+}
+
+// GetProviderSchema loads the stored schema of a provider version. Returns nil if no schema was extracted.
+func GetProviderSchema(ctx context.Context, db Queryable, namespace, name, version string) (*tofu.ProviderSchema, error) {
+	rows, err := db.Query(ctx, `
+		SELECT schema_type, schema_name, schema
+		FROM provider_schemas
+		WHERE provider_namespace = $1 AND provider_name = $2 AND version = $3`,
+		namespace, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query provider schema: %w", err)
+	}
+	defer rows.Close()
+
+	var schema *tofu.ProviderSchema
+	for rows.Next() {
+		var schemaType, schemaName string
+		var contents []byte
+		if err := rows.Scan(&schemaType, &schemaName, &contents); err != nil {
+			return nil, fmt.Errorf("failed to scan provider schema: %w", err)
+		}
+
+		if schema == nil {
+			schema = &tofu.ProviderSchema{}
+		}
+
+		switch schemaType {
+		case SchemaTypeFunction:
+			var fn tofu.FunctionSignature
+			if err := json.Unmarshal(contents, &fn); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal function schema %s: %w", schemaName, err)
+			}
+			if schema.Functions == nil {
+				schema.Functions = map[string]*tofu.FunctionSignature{}
+			}
+			schema.Functions[schemaName] = &fn
+		default:
+			var s tofu.Schema
+			if err := json.Unmarshal(contents, &s); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal %s schema %s: %w", schemaType, schemaName, err)
+			}
+			switch schemaType {
+			case SchemaTypeProvider:
+				schema.Provider = &s
+			case SchemaTypeResource:
+				if schema.ResourceSchemas == nil {
+					schema.ResourceSchemas = map[string]*tofu.Schema{}
+				}
+				schema.ResourceSchemas[schemaName] = &s
+			case SchemaTypeDataSource:
+				if schema.DataSourceSchemas == nil {
+					schema.DataSourceSchemas = map[string]*tofu.Schema{}
+				}
+				schema.DataSourceSchemas[schemaName] = &s
+			case SchemaTypeEphemeralResource:
+				if schema.EphemeralResourceSchemas == nil {
+					schema.EphemeralResourceSchemas = map[string]*tofu.Schema{}
+				}
+				schema.EphemeralResourceSchemas[schemaName] = &s
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating provider schema: %w", err)
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/provider/storage"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// ResourceTimeline is the content of resources.json: the version range in which every document of a provider appears
+type ResourceTimeline struct {
+	Namespace string                     `json:"namespace"`
+	Name      string                     `json:"name"`
+	Documents []*storage.DocAvailability `json:"documents"`
+}
+
+// PublishResourceTimeline uploads resources.json next to the provider version index. Unlike the document entries of
+// the version indexes, which keep the availability from when their version was indexed, it is rebuilt from all
+// completed versions whenever a version is indexed.
+func (p *ProviderReader) PublishResourceTimeline(ctx context.Context, namespace, name string) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "provider.publish_resource_timeline")
+	defer span.End()
+
+	availability, err := storage.GetDocumentAvailability(ctx, p.db, namespace, name)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	timeline := buildResourceTimeline(namespace, name, availability)
+
+	data, err := json.MarshalIndent(timeline, "", "  ")
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to marshal resource timeline: %w", err)
+	}
+
+	key := fmt.Sprintf("providers/%s/%s/resources.json", namespace, name)
+	err = p.store.Put(ctx, bucket.Object{Key: key, Body: data, ContentType: "application/json"})
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to upload resources.json: %w", err)
+	}
+
+	return nil
+}
+
+// buildResourceTimeline sorts the document availability by type and name, leaving out the index page
+func buildResourceTimeline(namespace, name string, availability map[string]*storage.DocAvailability) *ResourceTimeline {
+	timeline := &ResourceTimeline{
+		Namespace: namespace,
+		Name:      name,
+		Documents: make([]*storage.DocAvailability, 0, len(availability)),
+	}
+	for _, a := range availability {
+		// The index page exists in every version and is not interesting here
+		if a.Type == "index" {
+			continue
+		}
+		timeline.Documents = append(timeline.Documents, a)
+	}
+	slices.SortFunc(timeline.Documents, func(a, b *storage.DocAvailability) int {
+		if c := strings.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return timeline
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opentofu/registry-ui/pkg/provider/storage"
+)
+
+func TestBuildResourceTimeline(t *testing.T) {
+	instance := &storage.DocAvailability{Type: "resources", Name: "instance", FirstVersion: "1.0.0", LastVersion: "2.1.0", VersionCount: 4}
+	vpc := &storage.DocAvailability{Type: "resources", Name: "vpc", FirstVersion: "2.0.0", LastVersion: "2.1.0", VersionCount: 2}
+	ami := &storage.DocAvailability{Type: "datasources", Name: "ami", FirstVersion: "1.0.0", LastVersion: "1.2.0", VersionCount: 3}
+	index := &storage.DocAvailability{Type: "index", Name: "index", FirstVersion: "1.0.0", LastVersion: "2.1.0", VersionCount: 4}
+
+	tests := []struct {
+		name         string
+		availability map[string]*storage.DocAvailability
+		want         []*storage.DocAvailability
+	}{
+		{
+			name:         "no documents",
+			availability: nil,
+			want:         []*storage.DocAvailability{},
+		},
+		{
+			name: "index page is left out",
+			availability: map[string]*storage.DocAvailability{
+				"index:index":        index,
+				"resources:instance": instance,
+			},
+			want: []*storage.DocAvailability{instance},
+		},
+		{
+			name: "sorted by type then name",
+			availability: map[string]*storage.DocAvailability{
+				"resources:vpc":      vpc,
+				"resources:instance": instance,
+				"datasources:ami":    ami,
+			},
+			want: []*storage.DocAvailability{ami, instance, vpc},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildResourceTimeline("hashicorp", "aws", tt.availability)
+			if got.Namespace != "hashicorp" || got.Name != "aws" {
+				t.Errorf("address = %s/%s, want hashicorp/aws", got.Namespace, got.Name)
+			}
+			if !reflect.DeepEqual(got.Documents, tt.want) {
+				t.Errorf("documents = %+v, want %+v", got.Documents, tt.want)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ type IndexResponse struct {
 	LicensesCount  int       `json:"licenses_count"`
 	Success        bool      `json:"success"`
 	ErrorMessage   string    `json:"error_message,omitempty"`
+	ErrorClass     string    `json:"error_class,omitempty"`
 }
 
 // ProviderVersion represents a specific version of a provider with metadata
@@ -0,0 +1,210 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+
+	database "github.com/opentofu/registry-ui/pkg/db"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// LoadProviderState adds the providers, completed provider versions and uploaded provider documents to state.
+// namespace limits the state to a single namespace when it is not empty.
+func LoadProviderState(ctx context.Context, db *pgxpool.Pool, state *State, namespace string) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "reconcile.load_provider_state")
+	defer span.End()
+	span.SetAttributes(attribute.String("namespace", namespace))
+
+	err := database.QueryEach(ctx, db, `
+		SELECT namespace, name FROM providers
+		WHERE $1 = '' OR namespace = $1`, []any{namespace}, func(row pgx.Rows) error {
+		var ns, name string
+		if err := row.Scan(&ns, &name); err != nil {
+			return err
+		}
+		state.Parents["providers/"+ns+"/"+name+"/"] = true
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load providers: %w", err)
+	}
+
+	err = database.QueryEach(ctx, db, `
+		SELECT provider_namespace, provider_name, version FROM provider_versions
+		WHERE scrape_status = 'completed' AND ($1 = '' OR provider_namespace = $1)`, []any{namespace}, func(row pgx.Rows) error {
+		v := Version{Type: EntityTypeProvider}
+		if err := row.Scan(&v.Namespace, &v.Name, &v.Version); err != nil {
+			return err
+		}
+		state.Versions[v.Prefix()] = v
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load provider versions: %w", err)
+	}
+
+	err = database.QueryEach(ctx, db, `
+		SELECT d.s3_key, COALESCE(d.md5_checksum, '')
+		FROM provider_documents d
+		JOIN provider_versions v
+			ON v.provider_namespace = d.provider_namespace
+			AND v.provider_name = d.provider_name
+			AND v.version = d.version
+		WHERE v.scrape_status = 'completed'
+			AND d.s3_key IS NOT NULL
+			AND ($1 = '' OR d.provider_namespace = $1)`, []any{namespace}, func(row pgx.Rows) error {
+		var key, checksum string
+		if err := row.Scan(&key, &checksum); err != nil {
+			return err
+		}
+		state.Objects[key] = checksum
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load provider documents: %w", err)
+	}
+
+	return nil
+}
+
+// LoadModuleState adds the modules, completed module versions and their uploaded index.json and README.md files
+// (including submodules and examples) to state. namespace limits the state to a single namespace when it is not empty.
+func LoadModuleState(ctx context.Context, db *pgxpool.Pool, state *State, namespace string) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "reconcile.load_module_state")
+	defer span.End()
+	span.SetAttributes(attribute.String("namespace", namespace))
+
+	err := database.QueryEach(ctx, db, `
+		SELECT namespace, name, target FROM modules
+		WHERE $1 = '' OR namespace = $1`, []any{namespace}, func(row pgx.Rows) error {
+		var ns, name, target string
+		if err := row.Scan(&ns, &name, &target); err != nil {
+			return err
+		}
+		state.Parents["modules/"+ns+"/"+name+"/"+target+"/"] = true
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load modules: %w", err)
+	}
+
+	err = database.QueryEach(ctx, db, `
+		SELECT module_namespace, module_name, module_target, version,
+			COALESCE(index_md5_checksum, ''), COALESCE(readme_md5_checksum, '')
+		FROM module_versions
+		WHERE scrape_status = 'completed' AND ($1 = '' OR module_namespace = $1)`, []any{namespace}, func(row pgx.Rows) error {
+		v := Version{Type: EntityTypeModule}
+		var indexChecksum, readmeChecksum string
+		if err := row.Scan(&v.Namespace, &v.Name, &v.Target, &v.Version, &indexChecksum, &readmeChecksum); err != nil {
+			return err
+		}
+		state.Versions[v.Prefix()] = v
+		addModuleFiles(state, v.Prefix(), indexChecksum, readmeChecksum)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load module versions: %w", err)
+	}
+
+	for _, table := range []struct{ name, column, dir string }{
+		{name: "module_submodules", column: "submodule_name", dir: "submodules"},
+		{name: "module_examples", column: "example_name", dir: "examples"},
+	} {
+		// The table and column names are constants, not user input
+		query := fmt.Sprintf(`
+			SELECT c.module_namespace, c.module_name, c.module_target, c.version, c.%[2]s,
+				COALESCE(c.index_md5_checksum, ''), COALESCE(c.readme_md5_checksum, '')
+			FROM %[1]s c
+			JOIN module_versions v
+				ON v.module_namespace = c.module_namespace
+				AND v.module_name = c.module_name
+				AND v.module_target = c.module_target
+				AND v.version = c.version
+			WHERE v.scrape_status = 'completed' AND ($1 = '' OR c.module_namespace = $1)`, table.name, table.column)
+
+		err = database.QueryEach(ctx, db, query, []any{namespace}, func(row pgx.Rows) error {
+			v := Version{Type: EntityTypeModule}
+			var childName, indexChecksum, readmeChecksum string
+			if err := row.Scan(&v.Namespace, &v.Name, &v.Target, &v.Version, &childName, &indexChecksum, &readmeChecksum); err != nil {
+				return err
+			}
+			addModuleFiles(state, v.Prefix()+table.dir+"/"+childName+"/", indexChecksum, readmeChecksum)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", table.name, err)
+		}
+	}
+
+	return nil
+}
+
+// addModuleFiles records the index.json and, if one was uploaded, the README.md under prefix
+func addModuleFiles(state *State, prefix, indexChecksum, readmeChecksum string) {
+	state.Objects[prefix+"index.json"] = indexChecksum
+	if readmeChecksum != "" {
+		state.Objects[prefix+"README.md"] = readmeChecksum
+	}
+}
+
+// LoadInFlightVersions marks the provider and module versions attempted after since as in flight. A sync that is still
+// running may have uploaded their objects without committing them yet. namespace limits the versions to a single
+// namespace when it is not empty.
+func LoadInFlightVersions(ctx context.Context, db *pgxpool.Pool, state *State, namespace string, since time.Time) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "reconcile.load_in_flight_versions")
+	defer span.End()
+	span.SetAttributes(attribute.String("namespace", namespace))
+
+	err := database.QueryEach(ctx, db, `
+		SELECT provider_namespace, provider_name, version FROM provider_versions
+		WHERE last_attempt_at > $2 AND ($1 = '' OR provider_namespace = $1)`, []any{namespace, since}, func(row pgx.Rows) error {
+		v := Version{Type: EntityTypeProvider}
+		if err := row.Scan(&v.Namespace, &v.Name, &v.Version); err != nil {
+			return err
+		}
+		state.InFlight[v.Prefix()] = true
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load recently attempted provider versions: %w", err)
+	}
+
+	err = database.QueryEach(ctx, db, `
+		SELECT module_namespace, module_name, module_target, version FROM module_versions
+		WHERE last_attempt_at > $2 AND ($1 = '' OR module_namespace = $1)`, []any{namespace, since}, func(row pgx.Rows) error {
+		v := Version{Type: EntityTypeModule}
+		if err := row.Scan(&v.Namespace, &v.Name, &v.Target, &v.Version); err != nil {
+			return err
+		}
+		state.InFlight[v.Prefix()] = true
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load recently attempted module versions: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("versions.in_flight", len(state.InFlight)))
+	return nil
+}
+
+// ResetVersion deletes a version from the database so that it is scraped again during the next sync, the same way
+// as the retry-version command
+func ResetVersion(ctx context.Context, db *pgxpool.Pool, v Version) error {
+	var err error
+	if v.Type == EntityTypeModule {
+		_, err = db.Exec(ctx, `DELETE FROM module_versions WHERE module_namespace = $1 AND module_name = $2 AND module_target = $3 AND version = $4`,
+			v.Namespace, v.Name, v.Target, v.Version)
+	} else {
+		_, err = db.Exec(ctx, `DELETE FROM provider_versions WHERE provider_namespace = $1 AND provider_name = $2 AND version = $3`,
+			v.Namespace, v.Name, v.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reset %s version %s: %w", v.Type, v, err)
+	}
+	return nil
+}
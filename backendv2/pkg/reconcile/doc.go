@@ -0,0 +1,3 @@
+// Package reconcile compares the objects in the bucket with the objects the database says were uploaded, finding
+// orphaned objects, missing objects and checksum mismatches left behind by partial uploads or crashed syncs.
+package reconcile
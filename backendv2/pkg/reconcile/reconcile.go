@@ -0,0 +1,258 @@
+package reconcile
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+)
+
+const (
+	EntityTypeProvider = "provider"
+	EntityTypeModule   = "module"
+)
+
+// IssueKind classifies a difference between the bucket and the database
+type IssueKind string
+
+const (
+	// IssueOrphaned is an object in the bucket that doesn't belong to any completed version in the database
+	IssueOrphaned IssueKind = "orphaned"
+	// IssueMissing is an object recorded in the database that is not in the bucket
+	IssueMissing IssueKind = "missing"
+	// IssueMismatch is an object whose checksum differs from the one recorded in the database
+	IssueMismatch IssueKind = "checksum_mismatch"
+)
+
+// Version identifies a provider or module version. Target is empty for providers.
+type Version struct {
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Target    string `json:"target,omitempty"`
+	Version   string `json:"version"`
+}
+
+func (v Version) String() string {
+	if v.Type == EntityTypeModule {
+		return v.Namespace + "/" + v.Name + "/" + v.Target + "@" + v.Version
+	}
+	return v.Namespace + "/" + v.Name + "@" + v.Version
+}
+
+// Prefix returns the bucket prefix holding the files of the version
+func (v Version) Prefix() string {
+	if v.Type == EntityTypeModule {
+		return "modules/" + v.Namespace + "/" + v.Name + "/" + v.Target + "/" + v.Version + "/"
+	}
+	return "providers/" + v.Namespace + "/" + v.Name + "/" + v.Version + "/"
+}
+
+// State is what the database expects to find in the bucket
+type State struct {
+	// Objects maps the keys of uploaded objects to their MD5 checksum, empty if the checksum was not recorded
+	Objects map[string]string
+	// Versions maps the bucket prefix of every completed version to the version
+	Versions map[string]Version
+	// Parents contains the bucket prefixes of all providers and modules (e.g. providers/hashicorp/aws/)
+	Parents map[string]bool
+	// InFlight contains the bucket prefixes of versions attempted recently, whatever their status. The indexers upload
+	// a version before committing it, so its objects may not match the database yet.
+	InFlight map[string]bool
+}
+
+func NewState() *State {
+	return &State{
+		Objects:  map[string]string{},
+		Versions: map[string]Version{},
+		Parents:  map[string]bool{},
+		InFlight: map[string]bool{},
+	}
+}
+
+// Options controls what Compare reports
+type Options struct {
+	// CompareETags enables checksum mismatches. ETags are only the MD5 of the contents for unencrypted and SSE-S3
+	// encrypted objects.
+	CompareETags bool
+	// SettledBefore skips objects modified after it, they may belong to a version whose upload is still in progress
+	// and whose database transaction is not committed yet. A zero time compares every object.
+	SettledBefore time.Time
+}
+
+// Issue is a single difference between the bucket and the database
+type Issue struct {
+	Kind             IssueKind `json:"kind"`
+	Key              string    `json:"key"`
+	ExpectedChecksum string    `json:"expected_checksum,omitempty"`
+	ActualChecksum   string    `json:"actual_checksum,omitempty"`
+	// Version is the version the object belongs to, if it belongs to a completed version
+	Version *Version `json:"version,omitempty"`
+}
+
+// Report is the result of comparing the bucket with the database
+type Report struct {
+	Prefixes        []string `json:"prefixes"`
+	ObjectsScanned  int      `json:"objects_scanned"`
+	ObjectsExpected int      `json:"objects_expected"`
+	Orphaned        []Issue  `json:"orphaned"`
+	Missing         []Issue  `json:"missing"`
+	Mismatched      []Issue  `json:"mismatched"`
+	// Skipped counts the objects that were not compared because they or their version changed recently
+	Skipped int `json:"skipped"`
+}
+
+// Clean reports whether no issues were found
+func (r *Report) Clean() bool {
+	return len(r.Orphaned) == 0 && len(r.Missing) == 0 && len(r.Mismatched) == 0
+}
+
+// BrokenVersions returns the versions with missing or mismatched objects, these need to be scraped again
+func (r *Report) BrokenVersions() []Version {
+	seen := map[string]bool{}
+	var versions []Version
+	for _, issue := range slices.Concat(r.Missing, r.Mismatched) {
+		if issue.Version == nil || seen[issue.Version.Prefix()] {
+			continue
+		}
+		seen[issue.Version.Prefix()] = true
+		versions = append(versions, *issue.Version)
+	}
+	return versions
+}
+
+// Compare compares the objects listed from the bucket under prefixes with the expected state. Objects of in-flight
+// versions and objects modified after opts.SettledBefore are skipped.
+func Compare(state *State, prefixes []string, objects []bucket.ObjectInfo, opts Options) *Report {
+	report := &Report{
+		Prefixes:       prefixes,
+		ObjectsScanned: len(objects),
+		Orphaned:       []Issue{},
+		Missing:        []Issue{},
+		Mismatched:     []Issue{},
+	}
+
+	seen := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		seen[obj.Key] = true
+
+		versionPrefix, parentPrefix := splitKey(obj.Key)
+		if state.InFlight[versionPrefix] || (!opts.SettledBefore.IsZero() && obj.LastModified.After(opts.SettledBefore)) {
+			report.Skipped++
+			continue
+		}
+		if parentPrefix == "" {
+			// Global files such as providers/index.json are not tied to a single provider or module
+			continue
+		}
+
+		if versionPrefix == "" {
+			// Files of a provider or module such as its index.json
+			if !state.Parents[parentPrefix] {
+				report.Orphaned = append(report.Orphaned, Issue{Kind: IssueOrphaned, Key: obj.Key})
+			}
+			continue
+		}
+
+		version, ok := state.Versions[versionPrefix]
+		if !ok {
+			report.Orphaned = append(report.Orphaned, Issue{Kind: IssueOrphaned, Key: obj.Key})
+			continue
+		}
+
+		expected, tracked := state.Objects[obj.Key]
+		if !tracked {
+			// Files generated from the database (changes.json, schema.json, ...) have no recorded checksum, but
+			// documents and READMEs that are no longer recorded are left over from an earlier scrape
+			if isRecordedKind(version, obj.Key) {
+				report.Orphaned = append(report.Orphaned, Issue{Kind: IssueOrphaned, Key: obj.Key, Version: &version})
+			}
+			continue
+		}
+
+		// The ETag of multipart uploads is not the MD5 of the contents
+		if opts.CompareETags && expected != "" && obj.ETag != "" && !strings.Contains(obj.ETag, "-") && !strings.EqualFold(expected, obj.ETag) {
+			report.Mismatched = append(report.Mismatched, Issue{
+				Kind:             IssueMismatch,
+				Key:              obj.Key,
+				ExpectedChecksum: expected,
+				ActualChecksum:   obj.ETag,
+				Version:          &version,
+			})
+		}
+	}
+
+	for key, checksum := range state.Objects {
+		if !hasAnyPrefix(key, prefixes) {
+			continue
+		}
+		report.ObjectsExpected++
+		if seen[key] {
+			continue
+		}
+		versionPrefix, _ := splitKey(key)
+		if state.InFlight[versionPrefix] {
+			report.Skipped++
+			continue
+		}
+		issue := Issue{Kind: IssueMissing, Key: key, ExpectedChecksum: checksum}
+		if versionPrefix != "" {
+			if version, ok := state.Versions[versionPrefix]; ok {
+				issue.Version = &version
+			}
+		}
+		report.Missing = append(report.Missing, issue)
+	}
+
+	for _, issues := range [][]Issue{report.Orphaned, report.Missing, report.Mismatched} {
+		slices.SortFunc(issues, func(a, b Issue) int {
+			return strings.Compare(a.Key, b.Key)
+		})
+	}
+
+	return report
+}
+
+// splitKey returns the version prefix and the provider or module prefix of a key. The version prefix is empty for
+// files of the provider or module itself, both are empty for global files.
+func splitKey(key string) (versionPrefix string, parentPrefix string) {
+	parts := strings.Split(key, "/")
+	switch parts[0] {
+	case "providers":
+		// providers/<namespace>/<name>/<version>/...
+		if len(parts) >= 4 {
+			parentPrefix = strings.Join(parts[:3], "/") + "/"
+		}
+		if len(parts) >= 5 {
+			versionPrefix = strings.Join(parts[:4], "/") + "/"
+		}
+	case "modules":
+		// modules/<namespace>/<name>/<target>/<version>/...
+		if len(parts) >= 5 {
+			parentPrefix = strings.Join(parts[:4], "/") + "/"
+		}
+		if len(parts) >= 6 {
+			versionPrefix = strings.Join(parts[:5], "/") + "/"
+		}
+	}
+	return versionPrefix, parentPrefix
+}
+
+// isRecordedKind reports whether the indexer records a checksum for this kind of file
+func isRecordedKind(version Version, key string) bool {
+	rel := strings.TrimPrefix(key, version.Prefix())
+	if version.Type == EntityTypeProvider {
+		return strings.HasSuffix(rel, ".md")
+	}
+	return rel == "README.md" || strings.HasPrefix(rel, "submodules/") || strings.HasPrefix(rel, "examples/")
+}
+
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package reconcile
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+)
+
+func testState() *State {
+	state := NewState()
+	aws := Version{Type: EntityTypeProvider, Namespace: "hashicorp", Name: "aws", Version: "1.0.0"}
+	vpc := Version{Type: EntityTypeModule, Namespace: "terraform-aws-modules", Name: "vpc", Target: "aws", Version: "v1.0.0"}
+
+	state.Parents["providers/hashicorp/aws/"] = true
+	state.Parents["modules/terraform-aws-modules/vpc/aws/"] = true
+	state.Versions[aws.Prefix()] = aws
+	state.Versions[vpc.Prefix()] = vpc
+
+	state.Objects["providers/hashicorp/aws/1.0.0/resources/instance.md"] = "aaa"
+	state.Objects["providers/hashicorp/aws/1.0.0/index.md"] = ""
+	state.Objects["modules/terraform-aws-modules/vpc/aws/v1.0.0/index.json"] = "bbb"
+	state.Objects["modules/terraform-aws-modules/vpc/aws/v1.0.0/submodules/nat/index.json"] = "ccc"
+	return state
+}
+
+func keys(issues []Issue) []string {
+	var result []string
+	for _, issue := range issues {
+		result = append(result, issue.Key)
+	}
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name           string
+		prefixes       []string
+		objects        []bucket.ObjectInfo
+		wantOrphaned   []string
+		wantMissing    []string
+		wantMismatched []string
+	}{
+		{
+			name:     "consistent",
+			prefixes: []string{"providers/", "modules/"},
+			objects: []bucket.ObjectInfo{
+				{Key: "providers/index.json"},
+				{Key: "providers/hashicorp/aws/index.json"},
+				{Key: "providers/hashicorp/aws/1.0.0/index.json"},
+				{Key: "providers/hashicorp/aws/1.0.0/changes.json"},
+				{Key: "providers/hashicorp/aws/1.0.0/resources/instance.md", ETag: "AAA"},
+				{Key: "providers/hashicorp/aws/1.0.0/index.md", ETag: "anything"},
+				{Key: "modules/terraform-aws-modules/vpc/aws/v1.0.0/index.json", ETag: "bbb"},
+				{Key: "modules/terraform-aws-modules/vpc/aws/v1.0.0/submodules/nat/index.json", ETag: "ccc"},
+			},
+		},
+		{
+			name:     "orphaned objects",
+			prefixes: []string{"providers/", "modules/"},
+			objects: []bucket.ObjectInfo{
+				{Key: "providers/hashicorp/aws/1.0.0/resources/instance.md", ETag: "aaa"},
+				{Key: "providers/hashicorp/aws/1.0.0/index.md"},
+				{Key: "providers/hashicorp/aws/1.0.0/resources/removed.md"},
+				{Key: "providers/hashicorp/aws/2.0.0/index.json"},
+				{Key: "providers/hashicorp/google/index.json"},
+				{Key: "modules/terraform-aws-modules/vpc/aws/v1.0.0/index.json", ETag: "bbb"},
+				{Key: "modules/terraform-aws-modules/vpc/aws/v1.0.0/submodules/nat/index.json", ETag: "ccc"},
+				{Key: "modules/terraform-aws-modules/vpc/aws/v1.0.0/README.md"},
+			},
+			wantOrphaned: []string{
+				"modules/terraform-aws-modules/vpc/aws/v1.0.0/README.md",
+				"providers/hashicorp/aws/1.0.0/resources/removed.md",
+				"providers/hashicorp/aws/2.0.0/index.json",
+				"providers/hashicorp/google/index.json",
+			},
+		},
+		{
+			name:     "missing and mismatched objects",
+			prefixes: []string{"providers/", "modules/"},
+			objects: []bucket.ObjectInfo{
+				{Key: "providers/hashicorp/aws/1.0.0/resources/instance.md", ETag: "zzz"},
+				{Key: "modules/terraform-aws-modules/vpc/aws/v1.0.0/index.json", ETag: "zzz-2"},
+			},
+			wantMissing: []string{
+				"modules/terraform-aws-modules/vpc/aws/v1.0.0/submodules/nat/index.json",
+				"providers/hashicorp/aws/1.0.0/index.md",
+			},
+			wantMismatched: []string{"providers/hashicorp/aws/1.0.0/resources/instance.md"},
+		},
+		{
+			name:        "only expected objects under the scanned prefixes are missing",
+			prefixes:    []string{"providers/"},
+			objects:     []bucket.ObjectInfo{{Key: "providers/hashicorp/aws/1.0.0/resources/instance.md", ETag: "aaa"}},
+			wantMissing: []string{"providers/hashicorp/aws/1.0.0/index.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Compare(testState(), tt.prefixes, tt.objects, Options{CompareETags: true})
+
+			if got := keys(report.Orphaned); !slices.Equal(got, tt.wantOrphaned) {
+				t.Errorf("orphaned: expected %v, got %v", tt.wantOrphaned, got)
+			}
+			if got := keys(report.Missing); !slices.Equal(got, tt.wantMissing) {
+				t.Errorf("missing: expected %v, got %v", tt.wantMissing, got)
+			}
+			if got := keys(report.Mismatched); !slices.Equal(got, tt.wantMismatched) {
+				t.Errorf("mismatched: expected %v, got %v", tt.wantMismatched, got)
+			}
+		})
+	}
+}
+
+func TestBrokenVersions(t *testing.T) {
+	report := Compare(testState(), []string{"providers/", "modules/"}, nil, Options{CompareETags: true})
+
+	var got []string
+	for _, v := range report.BrokenVersions() {
+		got = append(got, v.String())
+	}
+	slices.Sort(got)
+
+	want := []string{"hashicorp/aws@1.0.0", "terraform-aws-modules/vpc/aws@v1.0.0"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCompareSkipsUnsettledObjects(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-24 * time.Hour)
+
+	state := testState()
+	// A retry of the module version is running, its previous objects may already be deleted or replaced
+	state.InFlight["modules/terraform-aws-modules/vpc/aws/v1.0.0/"] = true
+
+	objects := []bucket.ObjectInfo{
+		{Key: "providers/hashicorp/aws/1.0.0/resources/instance.md", ETag: "aaa", LastModified: old},
+		{Key: "providers/hashicorp/aws/1.0.0/index.md", LastModified: old},
+		// Uploaded by a sync that hasn't committed the version yet
+		{Key: "providers/hashicorp/aws/2.0.0/index.json", LastModified: now.Add(-time.Minute)},
+		{Key: "providers/hashicorp/aws/2.0.0/resources/instance.md", LastModified: now.Add(-time.Minute)},
+		{Key: "modules/terraform-aws-modules/vpc/aws/v1.0.0/index.json", ETag: "zzz", LastModified: old},
+		// Left behind by an earlier crashed sync
+		{Key: "providers/hashicorp/aws/3.0.0/index.json", LastModified: old},
+	}
+
+	report := Compare(state, []string{"providers/", "modules/"}, objects, Options{
+		CompareETags:  true,
+		SettledBefore: now.Add(-time.Hour),
+	})
+
+	if got, want := keys(report.Orphaned), []string{"providers/hashicorp/aws/3.0.0/index.json"}; !slices.Equal(got, want) {
+		t.Errorf("orphaned: expected %v, got %v", want, got)
+	}
+	if len(report.Missing) != 0 || len(report.Mismatched) != 0 {
+		t.Errorf("expected no missing or mismatched objects, got %v and %v", keys(report.Missing), keys(report.Mismatched))
+	}
+	// Three recent or in-flight objects and the missing submodule index.json of the in-flight version
+	if report.Skipped != 4 {
+		t.Errorf("expected 4 skipped objects, got %d", report.Skipped)
+	}
+}
+
+func TestCompareWithoutETags(t *testing.T) {
+	objects := []bucket.ObjectInfo{
+		{Key: "providers/hashicorp/aws/1.0.0/resources/instance.md", ETag: "kms-etag"},
+		{Key: "providers/hashicorp/aws/1.0.0/index.md"},
+		{Key: "modules/terraform-aws-modules/vpc/aws/v1.0.0/index.json", ETag: "kms-etag"},
+		{Key: "modules/terraform-aws-modules/vpc/aws/v1.0.0/submodules/nat/index.json", ETag: "kms-etag"},
+	}
+
+	report := Compare(testState(), []string{"providers/", "modules/"}, objects, Options{})
+	if !report.Clean() {
+		t.Errorf("expected a clean report when ETags are not MD5 checksums, got %+v", report)
+	}
+}
@@ -0,0 +1,142 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// Changes lists the providers and modules whose registry files differ between two commits
+type Changes struct {
+	From      string
+	To        string
+	Providers []Provider
+	Modules   []Module
+	// RemovedProviders and RemovedModules hold the addresses (namespace/name and namespace/name/target) of entries
+	// whose file no longer exists in the registry
+	RemovedProviders []string
+	RemovedModules   []string
+}
+
+// Head returns the registry commit that is currently checked out
+func (r *Client) Head(ctx context.Context) (string, error) {
+	return r.repo.Head(ctx)
+}
+
+// ChangedSince returns the providers and modules whose JSON files were added, modified or deleted since the given
+// commit. It returns an error wrapping git.ErrCommitNotFound if the commit is not available in the local clone.
+func (r *Client) ChangedSince(ctx context.Context, commit string) (*Changes, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "registry.changed_since")
+	defer span.End()
+
+	head, err := r.repo.Head(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("from", commit), attribute.String("to", head))
+
+	changes := &Changes{From: commit, To: head}
+	if commit == head {
+		return changes, nil
+	}
+
+	files, err := r.repo.ChangedFiles(ctx, commit, head)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	providers := make(map[[2]string]bool)
+	modules := make(map[[3]string]bool)
+	for _, file := range files {
+		kind, namespace, name, target, ok := parseRegistryPath(file)
+		switch {
+		case !ok:
+			continue
+		case kind == "providers":
+			providers[[2]string{namespace, name}] = fileExists(filepath.Join(r.path, file))
+		case kind == "modules":
+			modules[[3]string{namespace, name, target}] = fileExists(filepath.Join(r.path, file))
+		}
+	}
+
+	for addr, exists := range providers {
+		if !exists {
+			changes.RemovedProviders = append(changes.RemovedProviders, addr[0]+"/"+addr[1])
+			continue
+		}
+		provider, err := r.GetProvider(ctx, addr[0], addr[1])
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to read changed provider %s/%s: %w", addr[0], addr[1], err)
+		}
+		changes.Providers = append(changes.Providers, *provider)
+	}
+	for addr, exists := range modules {
+		if !exists {
+			changes.RemovedModules = append(changes.RemovedModules, addr[0]+"/"+addr[1]+"/"+addr[2])
+			continue
+		}
+		module, err := r.GetModule(ctx, addr[0], addr[1], addr[2])
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to read changed module %s/%s/%s: %w", addr[0], addr[1], addr[2], err)
+		}
+		changes.Modules = append(changes.Modules, *module)
+	}
+
+	sort.Slice(changes.Providers, func(i, j int) bool {
+		a, b := changes.Providers[i], changes.Providers[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	sort.Slice(changes.Modules, func(i, j int) bool {
+		a, b := changes.Modules[i], changes.Modules[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Target < b.Target
+	})
+	sort.Strings(changes.RemovedProviders)
+	sort.Strings(changes.RemovedModules)
+
+	span.SetAttributes(
+		attribute.Int("changed_files", len(files)),
+		attribute.Int("providers", len(changes.Providers)),
+		attribute.Int("modules", len(changes.Modules)),
+		attribute.Int("removed_providers", len(changes.RemovedProviders)),
+		attribute.Int("removed_modules", len(changes.RemovedModules)),
+	)
+
+	return changes, nil
+}
+
+// parseRegistryPath maps a file of the registry repository to the provider (providers/<letter>/<namespace>/<name>.json)
+// or module (modules/<letter>/<namespace>/<name>/<target>.json) it describes
+func parseRegistryPath(path string) (kind, namespace, name, target string, ok bool) {
+	if !strings.HasSuffix(path, ".json") {
+		return "", "", "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(path, ".json"), "/")
+
+	switch {
+	case parts[0] == "providers" && len(parts) == 4:
+		return "providers", parts[2], parts[3], "", true
+	case parts[0] == "modules" && len(parts) == 5:
+		return "modules", parts[2], parts[3], parts[4], true
+	default:
+		return "", "", "", "", false
+	}
+}
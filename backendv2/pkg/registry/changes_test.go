@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/git"
+	"github.com/opentofu/registry-ui/pkg/vcs"
+)
+
+// runGit runs a git command in dir with a fixed identity and without the user's configuration
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newRegistryRepo creates a registry repository with two commits and returns its path and the hashes of both commits.
+// The second commit adds a version to a provider, adds a module and removes a provider and a module.
+func newRegistryRepo(t *testing.T) (dir, first, second string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir = t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	writeFiles(t, dir, map[string]string{
+		"README.md":                                    "registry",
+		"providers/h/hashicorp/aws.json":               `{"versions":[{"version":"5.0.0"}]}`,
+		"providers/h/hashicorp/random.json":            `{"versions":[{"version":"3.0.0"}]}`,
+		"providers/e/example/old.json":                 `{"versions":[{"version":"0.1.0"}]}`,
+		"modules/t/terraform-aws-modules/vpc/aws.json": `{"versions":[{"version":"v5.0.0"}]}`,
+		"modules/e/example/old/aws.json":               `{"versions":[{"version":"v0.1.0"}]}`,
+	})
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "first")
+	first = runGit(t, dir, "rev-parse", "HEAD")
+
+	writeFiles(t, dir, map[string]string{
+		"README.md":                                    "registry contents",
+		"providers/h/hashicorp/aws.json":               `{"versions":[{"version":"5.1.0"},{"version":"5.0.0"}]}`,
+		"modules/t/terraform-aws-modules/eks/aws.json": `{"versions":[{"version":"v20.0.0"}]}`,
+	})
+	runGit(t, dir, "rm", "-q", "providers/e/example/old.json", "modules/e/example/old/aws.json")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "second")
+	second = runGit(t, dir, "rev-parse", "HEAD")
+
+	return dir, first, second
+}
+
+func newTestClient(t *testing.T, path string) *Client {
+	t.Helper()
+	host, err := vcs.New(config.VCSConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := New(path, host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestChangedSince(t *testing.T) {
+	dir, first, second := newRegistryRepo(t)
+	client := newTestClient(t, dir)
+
+	tests := []struct {
+		name                 string
+		since                string
+		wantProviders        []string
+		wantModules          []string
+		wantRemovedProviders []string
+		wantRemovedModules   []string
+	}{
+		{
+			name:                 "since the previous commit",
+			since:                first,
+			wantProviders:        []string{"hashicorp/aws@5.1.0,5.0.0"},
+			wantModules:          []string{"terraform-aws-modules/eks/aws@v20.0.0"},
+			wantRemovedProviders: []string{"example/old"},
+			wantRemovedModules:   []string{"example/old/aws"},
+		},
+		{
+			name:  "since head",
+			since: second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := client.ChangedSince(context.Background(), tt.since)
+			if err != nil {
+				t.Fatalf("ChangedSince() error = %v", err)
+			}
+			if changes.From != tt.since || changes.To != second {
+				t.Errorf("ChangedSince() range = %s..%s, want %s..%s", changes.From, changes.To, tt.since, second)
+			}
+
+			var providers, modules []string
+			for _, p := range changes.Providers {
+				providers = append(providers, p.Namespace+"/"+p.Name+"@"+strings.Join(p.Versions, ","))
+			}
+			for _, m := range changes.Modules {
+				modules = append(modules, m.Namespace+"/"+m.Name+"/"+m.Target+"@"+strings.Join(m.Versions, ","))
+			}
+			if !reflect.DeepEqual(providers, tt.wantProviders) {
+				t.Errorf("Providers = %v, want %v", providers, tt.wantProviders)
+			}
+			if !reflect.DeepEqual(modules, tt.wantModules) {
+				t.Errorf("Modules = %v, want %v", modules, tt.wantModules)
+			}
+			if !reflect.DeepEqual(changes.RemovedProviders, tt.wantRemovedProviders) {
+				t.Errorf("RemovedProviders = %v, want %v", changes.RemovedProviders, tt.wantRemovedProviders)
+			}
+			if !reflect.DeepEqual(changes.RemovedModules, tt.wantRemovedModules) {
+				t.Errorf("RemovedModules = %v, want %v", changes.RemovedModules, tt.wantRemovedModules)
+			}
+		})
+	}
+}
+
+func TestChangedSinceShallowClone(t *testing.T) {
+	dir, first, second := newRegistryRepo(t)
+
+	// The registry is cloned with depth 1, so a commit recorded before the clone was re-created is not available
+	shallow := filepath.Join(t.TempDir(), "registry")
+	runGit(t, dir, "clone", "-q", "--depth", "1", "--branch", "main", "file://"+dir, shallow)
+	client := newTestClient(t, shallow)
+
+	if _, err := client.ChangedSince(context.Background(), first); !errors.Is(err, git.ErrCommitNotFound) {
+		t.Errorf("ChangedSince(first) error = %v, want %v", err, git.ErrCommitNotFound)
+	}
+
+	changes, err := client.ChangedSince(context.Background(), second)
+	if err != nil {
+		t.Fatalf("ChangedSince(head) error = %v", err)
+	}
+	if len(changes.Providers)+len(changes.Modules)+len(changes.RemovedProviders)+len(changes.RemovedModules) != 0 {
+		t.Errorf("ChangedSince(head) = %+v, want no changes", changes)
+	}
+}
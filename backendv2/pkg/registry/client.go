@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/opentofu/registry-ui/pkg/git"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 const registryURL = "https://github.com/opentofu/registry.git"
@@ -15,9 +16,12 @@ const registryURL = "https://github.com/opentofu/registry.git"
 type Client struct {
 	repo *git.Repo
 	path string
+	host vcs.Host
 }
 
-func New(path string) (*Client, error) {
+// New creates a client for the registry repository cloned at path. host derives the repository URLs of
+// providers and modules.
+func New(path string, host vcs.Host) (*Client, error) {
 	if path == "" {
 		return nil, fmt.Errorf("repo path cannot be empty")
 	}
@@ -32,6 +36,7 @@ func New(path string) (*Client, error) {
 	return &Client{
 		repo: repo,
 		path: repo.LocalPath,
+		host: host,
 	}, nil
 }
 
@@ -81,8 +86,8 @@ func matchPattern(pattern, value string) bool {
 		if ok {
 			return strings.HasPrefix(value, trimmed)
 		}
-		if strings.HasSuffix(pattern, "*") {
-			return strings.HasPrefix(value, strings.TrimSuffix(pattern, "*"))
+		if leading, ok := strings.CutPrefix(pattern, "*"); ok {
+			return strings.HasSuffix(value, leading)
 		}
 	}
 
@@ -132,3 +137,19 @@ func listJSONFiles(path string) ([]string, error) {
 	}
 	return files, nil
 }
+
+// RemovedVersions returns the known versions that are no longer listed in the registry
+func RemovedVersions(known, listed []string) []string {
+	listedSet := make(map[string]bool, len(listed))
+	for _, version := range listed {
+		listedSet[version] = true
+	}
+
+	var removed []string
+	for _, version := range known {
+		if !listedSet[version] {
+			removed = append(removed, version)
+		}
+	}
+	return removed
+}
@@ -10,6 +10,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 type Module struct {
@@ -100,8 +101,7 @@ func (r *Client) ListModules(ctx context.Context, filter string) ([]Module, erro
 						}
 					}
 
-					module.Source = fmt.Sprintf("https://github.com/%s/terraform-%s-%s",
-						namespace, target, moduleName)
+					module.Source = r.host.RepoURL(vcs.ModuleRepo(namespace, moduleName, target))
 
 					modules = append(modules, *module)
 				}
@@ -164,7 +164,7 @@ func (r *Client) GetModule(ctx context.Context, namespace, name, target string)
 		Namespace: namespace,
 		Name:      name,
 		Target:    target,
-		Source:    fmt.Sprintf("https://github.com/%s/terraform-%s-%s", namespace, target, name),
+		Source:    r.host.RepoURL(vcs.ModuleRepo(namespace, name, target)),
 	}
 
 	for _, v := range data.Versions {
@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/opentofu/registry-ui/pkg/telemetry"
+	"github.com/opentofu/registry-ui/pkg/vcs"
 )
 
 type Provider struct {
@@ -20,22 +21,37 @@ type Provider struct {
 	Link        string   `json:"link,omitempty"`
 	Versions    []string `json:"versions,omitempty"`
 	Warnings    []string `json:"warnings,omitempty"`
+	// Targets holds the release artifacts of each version, keyed by version
+	Targets map[string][]ProviderTarget `json:"targets,omitempty"`
+}
+
+// ProviderTarget is a release artifact of a provider version for a single platform
+type ProviderTarget struct {
+	OS          string `json:"os"`
+	Arch        string `json:"arch"`
+	Filename    string `json:"filename"`
+	DownloadURL string `json:"download_url"`
+	SHA256      string `json:"shasum"`
+}
+
+// Target returns the release artifact of a version for the given platform
+func (p *Provider) Target(version, os, arch string) (*ProviderTarget, bool) {
+	for _, target := range p.Targets[version] {
+		if target.OS == os && target.Arch == arch {
+			return &target, true
+		}
+	}
+	return nil, false
 }
 
 type providerJSON struct {
 	Warnings []string `json:"warnings"`
 	Versions []struct {
-		Version      string   `json:"version"`
-		Protocols    []string `json:"protocols"`
-		SHASumsURL   string   `json:"shasums_url"`
-		SignatureURL string   `json:"shasums_signature_url"`
-		Targets      []struct {
-			OS       string `json:"os"`
-			Arch     string `json:"arch"`
-			Filename string `json:"filename"`
-			URL      string `json:"download_url"`
-			SHA256   string `json:"shasum"`
-		} `json:"targets"`
+		Version      string           `json:"version"`
+		Protocols    []string         `json:"protocols"`
+		SHASumsURL   string           `json:"shasums_url"`
+		SignatureURL string           `json:"shasums_signature_url"`
+		Targets      []ProviderTarget `json:"targets"`
 	} `json:"versions"`
 }
 
@@ -93,10 +109,12 @@ func (r *Client) ListProviders(ctx context.Context, filter string) ([]Provider,
 				jsonPath := filepath.Join(namespacePath, name+".json")
 				var data providerJSON
 				if err := readJSONFile(ctx, jsonPath, &data); err == nil {
+					provider.Targets = make(map[string][]ProviderTarget, len(data.Versions))
 					for _, v := range data.Versions {
 						provider.Versions = append(provider.Versions, v.Version)
+						provider.Targets[v.Version] = v.Targets
 					}
-					provider.Link = fmt.Sprintf("https://github.com/%s/terraform-provider-%s", namespace, name)
+					provider.Link = r.host.RepoURL(vcs.ProviderRepo(namespace, name))
 				}
 
 				providers = append(providers, provider)
@@ -144,12 +162,14 @@ func (r *Client) GetProvider(ctx context.Context, namespace, name string) (*Prov
 	provider := &Provider{
 		Namespace: namespace,
 		Name:      name,
-		Link:      fmt.Sprintf("https://github.com/%s/terraform-provider-%s", namespace, name),
+		Link:      r.host.RepoURL(vcs.ProviderRepo(namespace, name)),
 		Warnings:  data.Warnings,
 	}
 
+	provider.Targets = make(map[string][]ProviderTarget, len(data.Versions))
 	for _, v := range data.Versions {
 		provider.Versions = append(provider.Versions, v.Version)
+		provider.Targets[v.Version] = v.Targets
 	}
 
 	return provider, nil
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -45,6 +46,13 @@ func TestMatchPattern(t *testing.T) {
 		{"*corp", "hashicorp", true},
 		{"*shi*", "hashicorp", true},
 		{"hash*", "other", false},
+		{"hash*", "hash", true},
+		{"*corp", "corp", true},
+		{"*corp", "corporate", false},
+		{"*corp", "other", false},
+		{"*shi*", "other", false},
+		// Wildcards are only supported at the start and end of a pattern
+		{"ha*corp", "hashicorp", false},
 	}
 
 	for _, tt := range tests {
@@ -175,3 +183,54 @@ func TestMatchesModuleFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestRemovedVersions(t *testing.T) {
+	tests := []struct {
+		name   string
+		known  []string
+		listed []string
+		want   []string
+	}{
+		{name: "nothing removed", known: []string{"1.0.0"}, listed: []string{"1.0.0", "1.1.0"}, want: nil},
+		{name: "removed version", known: []string{"1.0.0", "1.1.0", "1.2.0"}, listed: []string{"1.0.0", "1.2.0"}, want: []string{"1.1.0"}},
+		{name: "no known versions", known: nil, listed: []string{"1.0.0"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RemovedVersions(tt.known, tt.listed)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("RemovedVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRegistryPath(t *testing.T) {
+	tests := []struct {
+		path      string
+		kind      string
+		namespace string
+		name      string
+		target    string
+		ok        bool
+	}{
+		{"providers/h/hashicorp/aws.json", "providers", "hashicorp", "aws", "", true},
+		{"modules/t/terraform-aws-modules/vpc/aws.json", "modules", "terraform-aws-modules", "vpc", "aws", true},
+		{"providers/h/hashicorp.json", "", "", "", "", false},
+		{"modules/t/terraform-aws-modules/vpc.json", "", "", "", "", false},
+		{"README.md", "", "", "", "", false},
+		{"providers/h/hashicorp/aws.md", "", "", "", "", false},
+		{"src/internal/providers/x/y.json", "", "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			kind, namespace, name, target, ok := parseRegistryPath(tt.path)
+			if kind != tt.kind || namespace != tt.namespace || name != tt.name || target != tt.target || ok != tt.ok {
+				t.Errorf("parseRegistryPath(%q) = (%q, %q, %q, %q, %v), want (%q, %q, %q, %q, %v)", tt.path,
+					kind, namespace, name, target, ok, tt.kind, tt.namespace, tt.name, tt.target, tt.ok)
+			}
+		})
+	}
+}
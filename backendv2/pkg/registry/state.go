@@ -0,0 +1,43 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// SyncStateProviders is the registry_sync_state entry of the providers
+	SyncStateProviders = "providers"
+	// SyncStateModules is the registry_sync_state entry of the modules
+	SyncStateModules = "modules"
+)
+
+// LastSyncedCommit returns the last registry commit whose providers or modules (depending on kind) were all synced,
+// or an empty string if none was recorded yet
+func LastSyncedCommit(ctx context.Context, db *pgxpool.Pool, kind string) (string, error) {
+	var commit string
+	err := db.QueryRow(ctx, `SELECT commit_hash FROM registry_sync_state WHERE kind = $1`, kind).Scan(&commit)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get last synced %s commit: %w", kind, err)
+	}
+	return commit, nil
+}
+
+// SetLastSyncedCommit records the registry commit whose providers or modules (depending on kind) were all synced
+func SetLastSyncedCommit(ctx context.Context, db *pgxpool.Pool, kind, commit string) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO registry_sync_state (kind, commit_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (kind) DO UPDATE SET commit_hash = EXCLUDED.commit_hash`, kind, commit)
+	if err != nil {
+		return fmt.Errorf("failed to record last synced %s commit: %w", kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// BitbucketClient fetches repository metadata from the Bitbucket Cloud REST API. Bitbucket has no stars, so
+// watchers are reported as stars instead.
+type BitbucketClient struct {
+	apiURL string
+	token  string
+}
+
+// NewBitbucketClient creates a client for Bitbucket Cloud, the only Bitbucket host vcs.baseURL accepts
+func NewBitbucketClient(cfg config.VCSConfig) *BitbucketClient {
+	return &BitbucketClient{
+		apiURL: "https://api.bitbucket.org/2.0",
+		token:  cfg.Token,
+	}
+}
+
+type bitbucketRepository struct {
+	Slug        string    `json:"slug"`
+	FullName    string    `json:"full_name"`
+	Description string    `json:"description"`
+	Website     string    `json:"website"`
+	Language    string    `json:"language"`
+	CreatedOn   time.Time `json:"created_on"`
+	UpdatedOn   time.Time `json:"updated_on"`
+	MainBranch  *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Parent *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+}
+
+// bitbucketPage is the envelope of paginated collections, only the total size is needed
+type bitbucketPage struct {
+	Size int64 `json:"size"`
+}
+
+func (c *BitbucketClient) GetRepositoryMetadata(ctx context.Context, owner, name string) (*RepositoryMetadata, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "repository.bitbucket.get_metadata")
+	defer span.End()
+	span.SetAttributes(attribute.String("repository", fmt.Sprintf("%s/%s", owner, name)))
+
+	slog.DebugContext(ctx, "Fetching repository metadata from Bitbucket", "owner", owner, "name", name)
+
+	setAuth := func(req *http.Request) {
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+	}
+
+	repoURL := fmt.Sprintf("%s/repositories/%s/%s", c.apiURL, owner, name)
+	var repo bitbucketRepository
+	if err := getJSON(ctx, repoURL, setAuth, &repo); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get repository %s/%s: %w", owner, name, err)
+	}
+
+	var forks, watchers bitbucketPage
+	if err := getJSON(ctx, repoURL+"/forks?pagelen=1", setAuth, &forks); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get forks of repository %s/%s: %w", owner, name, err)
+	}
+	if err := getJSON(ctx, repoURL+"/watchers?pagelen=1", setAuth, &watchers); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get watchers of repository %s/%s: %w", owner, name, err)
+	}
+
+	actualOwner, actualName, _ := strings.Cut(repo.FullName, "/")
+	metadata := &RepositoryMetadata{
+		Owner:           owner,
+		Name:            name,
+		Stars:           watchers.Size,
+		Forks:           forks.Size,
+		Description:     repo.Description,
+		IsFork:          repo.Parent != nil,
+		ActualOwner:     actualOwner,
+		ActualName:      actualName,
+		IsRedirect:      isRedirect(actualOwner, actualName, owner, name),
+		Subscribers:     watchers.Size,
+		Homepage:        repo.Website,
+		Language:        repo.Language,
+		CreatedAtGitHub: repo.CreatedOn,
+		PushedAt:        repo.UpdatedOn,
+		UpdatedAtGitHub: repo.UpdatedOn,
+	}
+	if repo.MainBranch != nil {
+		metadata.DefaultBranch = repo.MainBranch.Name
+	}
+	if repo.Parent != nil {
+		metadata.ParentOwner, metadata.ParentName, _ = strings.Cut(repo.Parent.FullName, "/")
+	}
+
+	return metadata, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -183,3 +184,39 @@ func UpdateRepositoryMetadata(ctx context.Context, pool *pgxpool.Pool, metadata
 
 	return nil
 }
+
+// dbETagStore persists cached GitHub responses in the github_response_cache table
+type dbETagStore struct {
+	db *pgxpool.Pool
+}
+
+func (s *dbETagStore) load(ctx context.Context, url string) (*cachedResponse, error) {
+	var resp cachedResponse
+	err := s.db.QueryRow(ctx, `
+		SELECT etag, header, body
+		FROM github_response_cache
+		WHERE url = $1`, url).Scan(&resp.etag, &resp.header, &resp.body)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query cached response: %w", err)
+	}
+	return &resp, nil
+}
+
+func (s *dbETagStore) save(ctx context.Context, url string, resp *cachedResponse) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO github_response_cache (url, etag, header, body, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (url) DO UPDATE SET
+			etag = EXCLUDED.etag,
+			header = EXCLUDED.header,
+			body = EXCLUDED.body,
+			updated_at = EXCLUDED.updated_at`,
+		url, resp.etag, resp.header, resp.body)
+	if err != nil {
+		return fmt.Errorf("failed to store cached response: %w", err)
+	}
+	return nil
+}
@@ -1,2 +1,3 @@
-// Package repository provides utilities for interacting with GitHub's API and managing repository data.
+// Package repository provides utilities for interacting with the APIs of source code hosts (GitHub, GitLab,
+// Bitbucket) and managing repository data.
 package repository
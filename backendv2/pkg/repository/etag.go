@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxCachedResponses bounds the memory used by the cache, an arbitrary entry is evicted when it is full
+	maxCachedResponses = 10000
+	// maxCachedBody skips caching large responses, repository and license responses are a few kilobytes
+	maxCachedBody = 256 * 1024
+)
+
+// etagCache keeps successful GET responses with an ETag so repeated requests for unchanged repositories are sent
+// as conditional requests. GitHub doesn't count 304 Not Modified responses against the rate limit. Responses are kept
+// in memory and, when a store is set, persisted so later runs revalidate them as well.
+type etagCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+	store   etagStore
+}
+
+// etagStore persists cached responses by request URL
+type etagStore interface {
+	// load returns the stored response for url, or nil if there is none
+	load(ctx context.Context, url string) (*cachedResponse, error)
+	save(ctx context.Context, url string, resp *cachedResponse) error
+}
+
+type cachedResponse struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+func newETagCache() *etagCache {
+	return &etagCache{entries: map[string]*cachedResponse{}}
+}
+
+// setStore persists the responses cached from now on in store and looks up responses missing from memory in it
+func (c *etagCache) setStore(store etagStore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store = store
+}
+
+// lookup returns the cached response for req, or nil if there is none or the request can't be cached
+func (c *etagCache) lookup(req *http.Request) *cachedResponse {
+	if c == nil || req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return nil
+	}
+	key := req.URL.String()
+
+	c.mu.Lock()
+	cached, store := c.entries[key], c.store
+	c.mu.Unlock()
+	if cached != nil || store == nil {
+		return cached
+	}
+
+	// A failing store only costs the conditional request, the response is fetched in full
+	cached, err := store.load(req.Context(), key)
+	if err != nil {
+		slog.WarnContext(req.Context(), "Failed to load cached GitHub response", "url", key, "error", err)
+		return nil
+	}
+	if cached != nil {
+		c.remember(key, cached)
+	}
+	return cached
+}
+
+// remember keeps resp in memory, evicting an arbitrary entry when the cache is full
+func (c *etagCache) remember(key string, resp *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedResponses {
+		for key := range c.entries {
+			delete(c.entries, key)
+			break
+		}
+	}
+	c.entries[key] = resp
+}
+
+// handle replaces a 304 response to a conditional request with the cached response and stores new responses that
+// have an ETag
+func (c *etagCache) handle(req *http.Request, resp *http.Response, cached *cachedResponse) (*http.Response, error) {
+	if c == nil || req.Method != http.MethodGet {
+		return resp, nil
+	}
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		header := cached.header.Clone()
+		// Keep the current rate limit of the 304 response
+		for key, values := range resp.Header {
+			if strings.HasPrefix(http.CanonicalHeaderKey(key), "X-Ratelimit-") {
+				header[key] = values
+			}
+		}
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached.body)),
+			ContentLength: int64(len(cached.body)),
+			Request:       resp.Request,
+		}, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedBody {
+		// Too large to cache, hand the rest of the body through
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	key := req.URL.String()
+	entry := &cachedResponse{etag: etag, header: resp.Header.Clone(), body: body}
+	c.remember(key, entry)
+
+	c.mu.Lock()
+	store := c.store
+	c.mu.Unlock()
+	if store != nil {
+		if err := store.save(req.Context(), key, entry); err != nil {
+			slog.WarnContext(req.Context(), "Failed to store cached GitHub response", "url", key, "error", err)
+		}
+	}
+
+	return resp, nil
+}
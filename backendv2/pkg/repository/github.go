@@ -12,33 +12,54 @@ import (
 	"time"
 
 	"github.com/google/go-github/v84/github"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
-	"golang.org/x/oauth2"
 
 	"github.com/opentofu/registry-ui/pkg/config"
 	"github.com/opentofu/registry-ui/pkg/telemetry"
 )
 
-const githubGraphQLEndpoint = "https://api.github.com/graphql"
-
 type Client struct {
-	client *github.Client
-	config *config.GitHubConfig
+	client     *github.Client
+	config     *config.GitHubConfig
+	tokens     *tokenPool
+	graphQLURL string
 }
 
-func NewClient(ctx context.Context, cfg *config.GitHubConfig) *Client {
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: cfg.Token},
-	)
-	tc := oauth2.NewClient(ctx, ts)
+// NewClient creates a client for the GitHub API at apiURL, see config.VCSConfig.GitHubAPIURL, that spreads its
+// requests over the configured tokens and GitHub App installation. Clients with the same credentials share their
+// rate limit budget. When db is set, responses are cached in the database so later runs revalidate them with
+// conditional requests instead of fetching them again.
+func NewClient(ctx context.Context, cfg *config.GitHubConfig, apiURL string, db *pgxpool.Pool) *Client {
+	tokens := sharedTokenPool(cfg, apiURL)
+	if db != nil {
+		tokens.cache.setStore(&dbETagStore{db: db})
+	}
+
+	client := github.NewClient(&http.Client{Transport: tokens})
+	if apiURL != config.DefaultGitHubAPIURL {
+		// apiURL is derived from vcs.baseURL, which is validated to be an absolute URL
+		if enterprise, err := client.WithEnterpriseURLs(apiURL, apiURL); err == nil {
+			client = enterprise
+		}
+	}
 
 	return &Client{
-		client: github.NewClient(tc),
+		client: client,
 		config: cfg,
+		tokens: tokens,
+		// GitHub Enterprise Server serves GraphQL from /api/graphql next to the /api/v3 REST API
+		graphQLURL: strings.TrimSuffix(apiURL, "/v3") + "/graphql",
 	}
 }
 
+// RateLimitRemaining returns the requests (or GraphQL points) left for resource across all credentials. It returns
+// false until every credential has reported its rate limit, the client isn't constrained before that.
+func (c *Client) RateLimitRemaining(resource string) (int, bool) {
+	return c.tokens.remaining(resource)
+}
+
 func isRedirect(actualOwner, actualName, requestedOwner, requestedName string) bool {
 	if !strings.EqualFold(actualOwner, requestedOwner) {
 		return true
@@ -344,7 +365,7 @@ func (c *Client) doGraphQLWithRetry(ctx context.Context, span trace.Span, reqBod
 
 // doGraphQL performs a single GraphQL POST request.
 func (c *Client) doGraphQL(ctx context.Context, reqBody []byte) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, githubGraphQLEndpoint, bytes.NewReader(reqBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.graphQLURL, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to build graphql request: %w", err)
 	}
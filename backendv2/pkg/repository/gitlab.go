@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// GitLabClient fetches repository metadata from the GitLab REST API
+type GitLabClient struct {
+	apiURL string
+	token  string
+}
+
+func NewGitLabClient(cfg config.VCSConfig) *GitLabClient {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+	return &GitLabClient{
+		apiURL: strings.TrimSuffix(baseURL, "/") + "/api/v4",
+		token:  cfg.Token,
+	}
+}
+
+type gitLabProject struct {
+	Path              string    `json:"path"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	Description       string    `json:"description"`
+	StarCount         int64     `json:"star_count"`
+	ForksCount        int64     `json:"forks_count"`
+	OpenIssuesCount   int64     `json:"open_issues_count"`
+	Topics            []string  `json:"topics"`
+	Archived          bool      `json:"archived"`
+	DefaultBranch     string    `json:"default_branch"`
+	CreatedAt         time.Time `json:"created_at"`
+	LastActivityAt    time.Time `json:"last_activity_at"`
+	Namespace         struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+	ForkedFromProject *struct {
+		Path      string `json:"path"`
+		Namespace struct {
+			FullPath string `json:"full_path"`
+		} `json:"namespace"`
+	} `json:"forked_from_project"`
+}
+
+func (c *GitLabClient) GetRepositoryMetadata(ctx context.Context, owner, name string) (*RepositoryMetadata, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "repository.gitlab.get_metadata")
+	defer span.End()
+	span.SetAttributes(attribute.String("repository", fmt.Sprintf("%s/%s", owner, name)))
+
+	slog.DebugContext(ctx, "Fetching repository metadata from GitLab", "owner", owner, "name", name)
+
+	var project gitLabProject
+	projectURL := fmt.Sprintf("%s/projects/%s", c.apiURL, url.PathEscape(owner+"/"+name))
+	err := getJSON(ctx, projectURL, func(req *http.Request) {
+		if c.token != "" {
+			req.Header.Set("PRIVATE-TOKEN", c.token)
+		}
+	}, &project)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get repository %s/%s: %w", owner, name, err)
+	}
+
+	actualOwner := project.Namespace.FullPath
+	actualName := project.Path
+	metadata := &RepositoryMetadata{
+		Owner:           owner,
+		Name:            name,
+		Stars:           project.StarCount,
+		Forks:           project.ForksCount,
+		Description:     project.Description,
+		IsFork:          project.ForkedFromProject != nil,
+		ActualOwner:     actualOwner,
+		ActualName:      actualName,
+		IsRedirect:      isRedirect(actualOwner, actualName, owner, name),
+		OpenIssues:      project.OpenIssuesCount,
+		Topics:          project.Topics,
+		Archived:        project.Archived,
+		DefaultBranch:   project.DefaultBranch,
+		CreatedAtGitHub: project.CreatedAt,
+		PushedAt:        project.LastActivityAt,
+		UpdatedAtGitHub: project.LastActivityAt,
+	}
+
+	if parent := project.ForkedFromProject; parent != nil {
+		metadata.ParentOwner = parent.Namespace.FullPath
+		metadata.ParentName = parent.Path
+	}
+
+	return metadata, nil
+}
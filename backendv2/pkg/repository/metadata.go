@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+)
+
+// MetadataSource fetches repository metadata from the API of a source code host
+type MetadataSource interface {
+	GetRepositoryMetadata(ctx context.Context, owner, name string) (*RepositoryMetadata, error)
+}
+
+// NewMetadataSource returns the metadata source for the configured VCS host, or nil if metadata can't be
+// fetched: plain git hosts have no API and GitHub requires credentials. db caches GitHub responses, see NewClient.
+func NewMetadataSource(ctx context.Context, cfg *config.BackendConfig, db *pgxpool.Pool) MetadataSource {
+	switch cfg.VCS.Type {
+	case "", config.VCSTypeGitHub:
+		if !cfg.GitHub.Configured() {
+			return nil
+		}
+		return NewClient(ctx, &cfg.GitHub, cfg.VCS.GitHubAPIURL(), db)
+	case config.VCSTypeGitLab:
+		return NewGitLabClient(cfg.VCS)
+	case config.VCSTypeBitbucket:
+		return NewBitbucketClient(cfg.VCS)
+	default:
+		return nil
+	}
+}
+
+// getJSON performs an authenticated GET request and decodes the JSON response into v
+func getJSON(ctx context.Context, url string, setAuth func(*http.Request), v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request for %s: %w", url, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if setAuth != nil {
+		setAuth(req)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, url)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+)
+
+// serveJSON returns a server answering the given escaped paths with fixed JSON bodies and 404 for everything else,
+// recording the value of header in every request
+func serveJSON(t *testing.T, header string, responses map[string]string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get(header))
+		body, ok := responses[r.URL.EscapedPath()+"?"+r.URL.RawQuery]
+		if !ok {
+			body, ok = responses[r.URL.EscapedPath()]
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body)) //nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+	return server, &seen
+}
+
+func TestGitLabMetadata(t *testing.T) {
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	active := time.Date(2025, 6, 7, 8, 9, 10, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		project string
+		want    *RepositoryMetadata
+	}{
+		{
+			name: "project",
+			project: `{
+				"path": "terraform-aws-vpc",
+				"path_with_namespace": "example/terraform-aws-vpc",
+				"description": "A VPC module",
+				"star_count": 42,
+				"forks_count": 7,
+				"open_issues_count": 3,
+				"topics": ["aws", "vpc"],
+				"archived": true,
+				"default_branch": "main",
+				"created_at": "2023-01-02T03:04:05Z",
+				"last_activity_at": "2025-06-07T08:09:10Z",
+				"namespace": {"full_path": "example"}
+			}`,
+			want: &RepositoryMetadata{
+				Owner: "example", Name: "terraform-aws-vpc",
+				Stars: 42, Forks: 7, Description: "A VPC module",
+				ActualOwner: "example", ActualName: "terraform-aws-vpc",
+				OpenIssues: 3, Topics: []string{"aws", "vpc"}, Archived: true, DefaultBranch: "main",
+				CreatedAtGitHub: created, PushedAt: active, UpdatedAtGitHub: active,
+			},
+		},
+		{
+			name: "renamed fork",
+			project: `{
+				"path": "terraform-aws-network",
+				"namespace": {"full_path": "Example"},
+				"created_at": "2023-01-02T03:04:05Z",
+				"last_activity_at": "2025-06-07T08:09:10Z",
+				"forked_from_project": {"path": "terraform-aws-vpc", "namespace": {"full_path": "upstream/group"}}
+			}`,
+			want: &RepositoryMetadata{
+				Owner: "example", Name: "terraform-aws-vpc",
+				IsFork: true, ParentOwner: "upstream/group", ParentName: "terraform-aws-vpc",
+				ActualOwner: "Example", ActualName: "terraform-aws-network", IsRedirect: true,
+				CreatedAtGitHub: created, PushedAt: active, UpdatedAtGitHub: active,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, tokens := serveJSON(t, "PRIVATE-TOKEN", map[string]string{
+				"/api/v4/projects/example%2Fterraform-aws-vpc": tt.project,
+			})
+
+			client := NewGitLabClient(config.VCSConfig{Type: config.VCSTypeGitLab, BaseURL: server.URL + "/", Token: "secret"})
+			got, err := client.GetRepositoryMetadata(context.Background(), "example", "terraform-aws-vpc")
+			if err != nil {
+				t.Fatalf("GetRepositoryMetadata() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetRepositoryMetadata() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(*tokens, []string{"secret"}) {
+				t.Errorf("PRIVATE-TOKEN headers = %v, want [secret]", *tokens)
+			}
+		})
+	}
+
+	t.Run("missing project", func(t *testing.T) {
+		server, _ := serveJSON(t, "PRIVATE-TOKEN", nil)
+		client := NewGitLabClient(config.VCSConfig{Type: config.VCSTypeGitLab, BaseURL: server.URL})
+		if _, err := client.GetRepositoryMetadata(context.Background(), "example", "terraform-aws-vpc"); err == nil {
+			t.Error("GetRepositoryMetadata() error = nil, want an error")
+		}
+	})
+}
+
+func TestBitbucketMetadata(t *testing.T) {
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	updated := time.Date(2025, 6, 7, 8, 9, 10, 0, time.UTC)
+	counts := map[string]string{
+		"/2.0/repositories/example/terraform-aws-vpc/forks?pagelen=1":    `{"size": 4}`,
+		"/2.0/repositories/example/terraform-aws-vpc/watchers?pagelen=1": `{"size": 12}`,
+	}
+
+	tests := []struct {
+		name string
+		repo string
+		want *RepositoryMetadata
+	}{
+		{
+			name: "repository",
+			repo: `{
+				"slug": "terraform-aws-vpc",
+				"full_name": "example/terraform-aws-vpc",
+				"description": "A VPC module",
+				"website": "https://example.com",
+				"language": "hcl",
+				"created_on": "2023-01-02T03:04:05Z",
+				"updated_on": "2025-06-07T08:09:10Z",
+				"mainbranch": {"name": "trunk"}
+			}`,
+			want: &RepositoryMetadata{
+				Owner: "example", Name: "terraform-aws-vpc",
+				Stars: 12, Forks: 4, Description: "A VPC module",
+				ActualOwner: "example", ActualName: "terraform-aws-vpc",
+				Subscribers: 12, Homepage: "https://example.com", Language: "hcl", DefaultBranch: "trunk",
+				CreatedAtGitHub: created, PushedAt: updated, UpdatedAtGitHub: updated,
+			},
+		},
+		{
+			name: "moved fork",
+			repo: `{
+				"full_name": "other/terraform-aws-vpc",
+				"created_on": "2023-01-02T03:04:05Z",
+				"updated_on": "2025-06-07T08:09:10Z",
+				"parent": {"full_name": "upstream/terraform-aws-vpc"}
+			}`,
+			want: &RepositoryMetadata{
+				Owner: "example", Name: "terraform-aws-vpc",
+				Stars: 12, Forks: 4, Subscribers: 12,
+				IsFork: true, ParentOwner: "upstream", ParentName: "terraform-aws-vpc",
+				ActualOwner: "other", ActualName: "terraform-aws-vpc", IsRedirect: true,
+				CreatedAtGitHub: created, PushedAt: updated, UpdatedAtGitHub: updated,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := map[string]string{"/2.0/repositories/example/terraform-aws-vpc": tt.repo}
+			for path, body := range counts {
+				responses[path] = body
+			}
+			server, auth := serveJSON(t, "Authorization", responses)
+
+			client := NewBitbucketClient(config.VCSConfig{Type: config.VCSTypeBitbucket, Token: "secret"})
+			client.apiURL = server.URL + "/2.0"
+			got, err := client.GetRepositoryMetadata(context.Background(), "example", "terraform-aws-vpc")
+			if err != nil {
+				t.Fatalf("GetRepositoryMetadata() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetRepositoryMetadata() = %+v, want %+v", got, tt.want)
+			}
+			for _, header := range *auth {
+				if header != "Bearer secret" {
+					t.Errorf("Authorization header = %q, want %q", header, "Bearer secret")
+				}
+			}
+		})
+	}
+
+	t.Run("public bitbucket.org api", func(t *testing.T) {
+		client := NewBitbucketClient(config.VCSConfig{Type: config.VCSTypeBitbucket, BaseURL: "https://bitbucket.org"})
+		if client.apiURL != "https://api.bitbucket.org/2.0" {
+			t.Errorf("apiURL = %q, want %q", client.apiURL, "https://api.bitbucket.org/2.0")
+		}
+	})
+}
+
+func TestNewClientAPIURL(t *testing.T) {
+	tests := []struct {
+		apiURL      string
+		wantREST    string
+		wantGraphQL string
+	}{
+		{apiURL: config.DefaultGitHubAPIURL, wantREST: "https://api.github.com/", wantGraphQL: "https://api.github.com/graphql"},
+		{apiURL: "https://github.example.com/api/v3", wantREST: "https://github.example.com/api/v3/", wantGraphQL: "https://github.example.com/api/graphql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.apiURL, func(t *testing.T) {
+			client := NewClient(context.Background(), &config.GitHubConfig{Token: "secret"}, tt.apiURL, nil)
+			if got := client.client.BaseURL.String(); got != tt.wantREST {
+				t.Errorf("REST base URL = %q, want %q", got, tt.wantREST)
+			}
+			if client.graphQLURL != tt.wantGraphQL {
+				t.Errorf("GraphQL URL = %q, want %q", client.graphQLURL, tt.wantGraphQL)
+			}
+		})
+	}
+}
@@ -11,23 +11,23 @@ import (
 	"github.com/opentofu/registry-ui/pkg/telemetry"
 )
 
-// SyncRepositoryMetadata fetches and stores complete GitHub metadata for a repository.
+// SyncRepositoryMetadata fetches and stores complete metadata for a repository from its VCS host.
 // This includes stats (stars, forks, watchers), metadata (description, language, archived),
 // and fork information (is_fork, parent repository).
-func SyncRepositoryMetadata(ctx context.Context, pool *pgxpool.Pool, githubClient *Client, org, name string) error {
+func SyncRepositoryMetadata(ctx context.Context, pool *pgxpool.Pool, source MetadataSource, org, name string) error {
 	ctx, span := telemetry.Tracer().Start(ctx, "repository.sync_metadata")
 	defer span.End()
 	span.SetAttributes(attribute.String("repository", fmt.Sprintf("%s/%s", org, name)))
 
-	if githubClient == nil {
-		return fmt.Errorf("github client is required for repository sync")
+	if source == nil {
+		return fmt.Errorf("metadata source is required for repository sync")
 	}
 
-	slog.DebugContext(ctx, "Syncing repository metadata from GitHub",
+	slog.DebugContext(ctx, "Syncing repository metadata",
 		"repository", fmt.Sprintf("%s/%s", org, name))
 
-	// Fetch repository metadata from GitHub API
-	metadata, err := githubClient.GetRepositoryMetadata(ctx, org, name)
+	// Fetch repository metadata from the host API
+	metadata, err := source.GetRepositoryMetadata(ctx, org, name)
 	if err != nil {
 		return fmt.Errorf("failed to fetch repository metadata for %s/%s: %w", org, name, err)
 	}
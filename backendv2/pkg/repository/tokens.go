@@ -0,0 +1,470 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// Rate limit resources tracked for each credential, named like the X-RateLimit-Resource header
+const (
+	ResourceCore    = "core"
+	ResourceGraphQL = "graphql"
+)
+
+// installationTokenRefresh is how long before expiry an app installation token is replaced
+const installationTokenRefresh = 5 * time.Minute
+
+// unavailableRetry is how long a credential whose token can't be obtained is left out before trying it again
+const unavailableRetry = time.Minute
+
+// credential is a token or app installation of the pool with the last rate limits GitHub reported for it
+type credential struct {
+	// name identifies the credential in logs without revealing the token
+	name   string
+	source oauth2.TokenSource
+	limits map[string]rateLimit
+	// unavailableUntil is set when the token of the credential can't be obtained, e.g. an app installation token
+	unavailableUntil time.Time
+}
+
+type rateLimit struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// tokenPool is an http.RoundTripper that authenticates each request with the credential that has the most quota
+// left for its resource. A request rejected because the quota of its credential is exhausted is sent again with the
+// next credential, as is a request whose credential fails to provide a token. Requests wait for the rate limit to reset once every credential is down to the reserve, and GET
+// responses are revalidated with their ETag.
+type tokenPool struct {
+	mu          sync.Mutex
+	credentials []*credential
+	reserve     int
+	// pausedUntil is the end of the current pause by resource, to log each pause once
+	pausedUntil map[string]time.Time
+	cache       *etagCache
+	// base sends the requests, http.DefaultTransport when nil
+	base http.RoundTripper
+	now  func() time.Time
+}
+
+var (
+	sharedPoolsMu sync.Mutex
+	sharedPools   = map[string]*tokenPool{}
+)
+
+// sharedTokenPool returns the pool of the credentials in cfg for the GitHub API at apiURL, creating it on first use,
+// so all clients of the process using the same credentials share one rate limit budget
+func sharedTokenPool(cfg *config.GitHubConfig, apiURL string) *tokenPool {
+	key := credentialsKey(cfg, apiURL)
+
+	sharedPoolsMu.Lock()
+	defer sharedPoolsMu.Unlock()
+
+	pool, ok := sharedPools[key]
+	if !ok {
+		pool = newTokenPool(cfg, apiURL)
+		sharedPools[key] = pool
+	}
+	return pool
+}
+
+// credentialsKey identifies the credentials of cfg without keeping the tokens themselves around as map keys
+func credentialsKey(cfg *config.GitHubConfig, apiURL string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "api:%s\n", apiURL)
+	for _, token := range append([]string{cfg.Token}, cfg.Tokens...) {
+		fmt.Fprintf(h, "token:%s\n", token)
+	}
+	if cfg.App.Configured() {
+		fmt.Fprintf(h, "app:%d/%d\n", cfg.App.ID, cfg.App.InstallationID)
+	}
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// newTokenPool creates a pool of the credentials in cfg, app installation tokens are requested from apiURL
+func newTokenPool(cfg *config.GitHubConfig, apiURL string) *tokenPool {
+	pool := &tokenPool{
+		reserve:     cfg.RateLimitReserve,
+		pausedUntil: map[string]time.Time{},
+		cache:       newETagCache(),
+		now:         time.Now,
+	}
+
+	seen := map[string]bool{}
+	addToken := func(name, token string) {
+		if token == "" || seen[token] {
+			return
+		}
+		seen[token] = true
+		pool.add(name, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	}
+	addToken("github.token", cfg.Token)
+	for i, token := range cfg.Tokens {
+		addToken(fmt.Sprintf("github.tokens[%d]", i), token)
+	}
+
+	if cfg.App.Configured() {
+		src := &appTokenSource{
+			appID:          cfg.App.ID,
+			installationID: cfg.App.InstallationID,
+			baseURL:        apiURL,
+		}
+		src.key, src.keyErr = cfg.App.ParsePrivateKey()
+		pool.add("github.app", oauth2.ReuseTokenSourceWithExpiry(nil, src, installationTokenRefresh))
+	}
+
+	return pool
+}
+
+func (p *tokenPool) add(name string, source oauth2.TokenSource) {
+	p.credentials = append(p.credentials, &credential{name: name, source: source, limits: map[string]rateLimit{}})
+}
+
+func (p *tokenPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	resource := requestResource(req)
+	tried := map[*credential]bool{}
+
+	if err := p.wait(ctx, resource); err != nil {
+		return nil, err
+	}
+	cached := p.cache.lookup(req)
+
+	var resp *http.Response
+	var tokenErr error
+	for {
+		cred := p.pick(resource, tried)
+		if cred == nil {
+			if resp != nil {
+				return resp, nil
+			}
+			if tokenErr != nil {
+				return nil, tokenErr
+			}
+			return nil, fmt.Errorf("no GitHub credentials configured")
+		}
+
+		// Credentials failing to provide a token are left out by pick, so the next one is tried
+		token, err := cred.source.Token()
+		if err != nil {
+			tokenErr = fmt.Errorf("failed to get GitHub token for %s: %w", cred.name, err)
+			p.markUnavailable(ctx, cred, tokenErr)
+			continue
+		}
+
+		r := req.Clone(ctx)
+		if resp != nil {
+			// Only retry when the body can be sent again
+			if req.Body != nil && req.GetBody == nil {
+				return resp, nil
+			}
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return resp, nil
+				}
+				r.Body = body
+			}
+			slog.WarnContext(ctx, "GitHub rate limit exhausted, retrying with another credential",
+				"resource", resource, "credential", cred.name)
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		token.SetAuthHeader(r)
+		if cached != nil {
+			r.Header.Set("If-None-Match", cached.etag)
+		}
+
+		resp, err = p.transport().RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+		tried[cred] = true
+
+		if !p.update(ctx, cred, resource, resp.Header) || !isRateLimited(resp, resource) {
+			return p.cache.handle(req, resp, cached)
+		}
+	}
+}
+
+func (p *tokenPool) transport() http.RoundTripper {
+	if p.base != nil {
+		return p.base
+	}
+	return http.DefaultTransport
+}
+
+// pick returns the credential with the most quota left for resource, skipping the ones already tried or unavailable.
+// Credentials without known limits are preferred since they are most likely unused. When every credential is exhausted the one
+// that resets first is used so the request fails with GitHub's rate limit error, or nil if it has been tried.
+func (p *tokenPool) pick(resource string, tried map[*credential]bool) *credential {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var best, earliest *credential
+	bestRemaining := -1
+	for _, cred := range p.credentials {
+		if tried[cred] || cred.unavailableUntil.After(now) {
+			continue
+		}
+		rl, known := cred.limits[resource]
+		if !known || !rl.reset.After(now) {
+			return cred
+		}
+		if rl.remaining > bestRemaining {
+			best, bestRemaining = cred, rl.remaining
+		}
+		if earliest == nil || rl.reset.Before(earliest.limits[resource].reset) {
+			earliest = cred
+		}
+	}
+	if bestRemaining > 0 {
+		// Count the request against the quota right away so concurrent callers spread over the credentials
+		rl := best.limits[resource]
+		rl.remaining--
+		best.limits[resource] = rl
+		return best
+	}
+	if len(tried) == 0 {
+		return earliest
+	}
+	return nil
+}
+
+// markUnavailable leaves cred out of the pool for a while after its token could not be obtained
+func (p *tokenPool) markUnavailable(ctx context.Context, cred *credential, err error) {
+	p.mu.Lock()
+	cred.unavailableUntil = p.now().Add(unavailableRetry)
+	p.mu.Unlock()
+
+	slog.WarnContext(ctx, "GitHub credential unavailable, using the other credentials",
+		"credential", cred.name, "retry_in", unavailableRetry.String(), "error", err)
+}
+
+// update stores the rate limit headers of a response and reports whether the quota of the credential is exhausted
+func (p *tokenPool) update(ctx context.Context, cred *credential, resource string, h http.Header) bool {
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return false
+	}
+	limit, _ := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	resetUnix, _ := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if r := h.Get("X-RateLimit-Resource"); r != "" {
+		resource = r
+	}
+	reset := time.Unix(resetUnix, 0)
+
+	p.mu.Lock()
+	previous, known := cred.limits[resource]
+	cred.limits[resource] = rateLimit{limit: limit, remaining: remaining, reset: reset}
+	p.mu.Unlock()
+
+	telemetry.RecordGitHubRateLimit(ctx, cred.name, resource, remaining)
+
+	if remaining == 0 && (!known || previous.remaining > 0) {
+		slog.WarnContext(ctx, "GitHub credential rate limit exhausted",
+			"credential", cred.name, "resource", resource, "reset", reset.UTC().Format(time.RFC3339))
+	}
+	return remaining == 0
+}
+
+// wait blocks while every available credential has no more than the reserve left for resource, until the first of
+// them resets
+func (p *tokenPool) wait(ctx context.Context, resource string) error {
+	for {
+		p.mu.Lock()
+		now := p.now()
+		var resume time.Time
+		for _, cred := range p.credentials {
+			if cred.unavailableUntil.After(now) {
+				continue
+			}
+			rl, known := cred.limits[resource]
+			if !known || !rl.reset.After(now) || rl.remaining > p.reserve {
+				resume = time.Time{}
+				break
+			}
+			if resume.IsZero() || rl.reset.Before(resume) {
+				resume = rl.reset
+			}
+		}
+		logPause := !resume.IsZero() && !p.pausedUntil[resource].Equal(resume)
+		if logPause {
+			p.pausedUntil[resource] = resume
+		}
+		p.mu.Unlock()
+
+		if resume.IsZero() {
+			return nil
+		}
+		if logPause {
+			slog.WarnContext(ctx, "GitHub rate limit reserve reached on all credentials, pausing requests",
+				"resource", resource, "reserve", p.reserve, "resume", resume.UTC().Format(time.RFC3339))
+		}
+
+		// GitHub resets the window at the given second, wait a little longer to be on the safe side
+		timer := time.NewTimer(resume.Sub(now) + time.Second)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// remaining sums the quota left for resource over all credentials. It returns false while any credential has no
+// known limit or its window has reset, the pool isn't constrained then.
+func (p *tokenPool) remaining(resource string) (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	total := 0
+	for _, cred := range p.credentials {
+		rl, known := cred.limits[resource]
+		if !known || !rl.reset.After(now) {
+			return 0, false
+		}
+		total += rl.remaining
+	}
+	return total, len(p.credentials) > 0
+}
+
+func requestResource(req *http.Request) string {
+	if strings.HasSuffix(req.URL.Path, "/graphql") {
+		return ResourceGraphQL
+	}
+	return ResourceCore
+}
+
+// isRateLimited reports whether GitHub rejected the request because of its rate limit. The GraphQL API reports an
+// exhausted quota as a RATE_LIMITED error in a 200 response, the body is read to check for it and then restored.
+func isRateLimited(resp *http.Response, resource string) bool {
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resource != ResourceGraphQL || resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	var result struct {
+		Errors []struct {
+			Type string `json:"type"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &result) != nil {
+		return false
+	}
+	for _, e := range result.Errors {
+		if e.Type == "RATE_LIMITED" {
+			return true
+		}
+	}
+	return false
+}
+
+// appTokenSource creates installation access tokens of a GitHub App, authenticating with a JWT signed by the app's
+// private key
+type appTokenSource struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	keyErr         error
+	baseURL        string
+}
+
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	if s.keyErr != nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: %w", s.keyErr)
+	}
+
+	jwt, err := appJWT(s.appID, s.key, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", s.baseURL, s.installationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installation token request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to create installation token for installation %d: status %d: %s", s.installationID, resp.StatusCode, body)
+	}
+
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode installation token: %w", err)
+	}
+
+	return &oauth2.Token{AccessToken: result.Token, TokenType: "Bearer", Expiry: result.ExpiresAt}, nil
+}
+
+// appJWT creates the RS256 signed JWT that authenticates as the app itself. GitHub accepts at most 10 minutes of
+// validity and recommends backdating the issue time to allow for clock drift.
+func appJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,448 @@
+package repository
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+)
+
+func TestTokenPoolFailover(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+
+	var mu sync.Mutex
+	remaining := map[string]int{"a": 1, "b": 5}
+	var used []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		used = append(used, token)
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		if remaining[token] == 0 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		remaining[token]--
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining[token]))
+	}))
+	defer server.Close()
+
+	pool := newTokenPool(&config.GitHubConfig{}, config.DefaultGitHubAPIURL)
+	pool.add("a", oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "a"}))
+	pool.add("b", oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "b"}))
+	client := &http.Client{Transport: pool}
+
+	// "a" is used first and its quota runs out, "b" is used next as "a" has none left. Then "a" reports more quota
+	// than "b" but is exhausted by another process, so the request is sent again with "b" which is used from then on.
+	for i := range 4 {
+		if i == 2 {
+			mu.Lock()
+			remaining["a"] = 0
+			remaining["b"] = 10
+			mu.Unlock()
+			pool.mu.Lock()
+			pool.credentials[0].limits[ResourceCore] = rateLimit{remaining: 20, reset: time.Unix(reset, 0)}
+			pool.mu.Unlock()
+		}
+
+		resp, err := client.Get(server.URL + "/repos/o/r")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: status %d, want 200", i, resp.StatusCode)
+		}
+	}
+
+	want := []string{"a", "b", "a", "b", "b"}
+	if strings.Join(used, ",") != strings.Join(want, ",") {
+		t.Errorf("tokens used = %v, want %v", used, want)
+	}
+
+	if got, ok := pool.remaining(ResourceCore); !ok || got != 8 {
+		t.Errorf("remaining(core) = %d, %v, want 8, true", got, ok)
+	}
+	if _, ok := pool.remaining(ResourceGraphQL); ok {
+		t.Error("remaining(graphql) is known before any GraphQL request")
+	}
+}
+
+func TestTokenPoolGraphQLFailover(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+
+	var used []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		used = append(used, token)
+		w.Header().Set("X-RateLimit-Resource", ResourceGraphQL)
+		w.Header().Set("X-RateLimit-Reset", reset)
+		// GitHub answers an exhausted GraphQL quota with 200 and a RATE_LIMITED error
+		if token == "a" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			fmt.Fprint(w, `{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "100")
+		fmt.Fprint(w, `{"data":{"viewer":{"login":"b"}}}`)
+	}))
+	defer server.Close()
+
+	pool := newTokenPool(&config.GitHubConfig{}, config.DefaultGitHubAPIURL)
+	pool.add("a", oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "a"}))
+	pool.add("b", oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "b"}))
+	client := &http.Client{Transport: pool}
+
+	resp, err := client.Post(server.URL+"/graphql", "application/json", strings.NewReader(`{"query":"{viewer{login}}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != `{"data":{"viewer":{"login":"b"}}}` {
+		t.Errorf("body = %s, want the response for b", body)
+	}
+	if strings.Join(used, ",") != "a,b" {
+		t.Errorf("tokens used = %v, want [a b]", used)
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		resource string
+		body     string
+		want     bool
+	}{
+		{"forbidden", http.StatusForbidden, ResourceCore, "", true},
+		{"too many requests", http.StatusTooManyRequests, ResourceGraphQL, "", true},
+		{"graphql rate limited", http.StatusOK, ResourceGraphQL, `{"errors":[{"type":"RATE_LIMITED"}]}`, true},
+		{"graphql other error", http.StatusOK, ResourceGraphQL, `{"errors":[{"type":"NOT_FOUND"}]}`, false},
+		{"graphql data", http.StatusOK, ResourceGraphQL, `{"data":{}}`, false},
+		{"core body is not inspected", http.StatusOK, ResourceCore, `{"errors":[{"type":"RATE_LIMITED"}]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader(tt.body))}
+			if got := isRateLimited(resp, tt.resource); got != tt.want {
+				t.Errorf("isRateLimited() = %v, want %v", got, tt.want)
+			}
+			// The body is still readable by the caller
+			body, err := io.ReadAll(resp.Body)
+			if err != nil || string(body) != tt.body {
+				t.Errorf("body after isRateLimited() = %q, %v, want %q", body, err, tt.body)
+			}
+		})
+	}
+}
+
+// failingTokenSource stands in for an app credential whose installation token can't be created
+type failingTokenSource struct {
+	calls int
+}
+
+func (s *failingTokenSource) Token() (*oauth2.Token, error) {
+	s.calls++
+	return nil, errors.New("installation suspended")
+}
+
+func TestTokenPoolUnavailableCredential(t *testing.T) {
+	var used []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		used = append(used, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		w.Header().Set("X-RateLimit-Remaining", "100")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	app := &failingTokenSource{}
+	pool := newTokenPool(&config.GitHubConfig{}, config.DefaultGitHubAPIURL)
+	pool.now = func() time.Time { return now }
+	pool.add("github.app", app)
+	pool.add("github.token", oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "b"}))
+	client := &http.Client{Transport: pool}
+
+	for i := range 2 {
+		resp, err := client.Get(server.URL + "/repos/o/r")
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+	if strings.Join(used, ",") != "b,b" {
+		t.Errorf("tokens used = %v, want [b b]", used)
+	}
+	if app.calls != 1 {
+		t.Errorf("app token requested %d times, want 1 while it is unavailable", app.calls)
+	}
+
+	// The credential is tried again once the retry delay has passed
+	now = now.Add(unavailableRetry + time.Second)
+	resp, err := client.Get(server.URL + "/repos/o/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if app.calls != 2 {
+		t.Errorf("app token requested %d times, want 2 after the retry delay", app.calls)
+	}
+
+	// Without another credential the token error is returned
+	only := newTokenPool(&config.GitHubConfig{}, config.DefaultGitHubAPIURL)
+	only.add("github.app", &failingTokenSource{})
+	if _, err := (&http.Client{Transport: only}).Get(server.URL + "/repos/o/r"); err == nil || !strings.Contains(err.Error(), "installation suspended") {
+		t.Errorf("Get() error = %v, want the token error", err)
+	}
+}
+
+func TestTokenPoolWait(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		limits    rateLimit
+		wantPause bool
+	}{
+		{"above reserve", rateLimit{remaining: 11, reset: now.Add(time.Hour)}, false},
+		{"at reserve", rateLimit{remaining: 10, reset: now.Add(time.Hour)}, true},
+		{"window has reset", rateLimit{remaining: 0, reset: now.Add(-time.Second)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newTokenPool(&config.GitHubConfig{Token: "a", RateLimitReserve: 10}, config.DefaultGitHubAPIURL)
+			pool.credentials[0].limits[ResourceCore] = tt.limits
+
+			ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+			defer cancel()
+
+			err := pool.wait(ctx, ResourceCore)
+			if paused := errors.Is(err, context.DeadlineExceeded); paused != tt.wantPause {
+				t.Errorf("wait() = %v, want paused %v", err, tt.wantPause)
+			}
+		})
+	}
+}
+
+func TestTokenPoolConditionalRequests(t *testing.T) {
+	var conditional int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "100")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"name":"opentofu"}`)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newTokenPool(&config.GitHubConfig{Token: "a"}, config.DefaultGitHubAPIURL)}
+	for i := range 2 {
+		resp, err := client.Get(server.URL + "/repos/opentofu/opentofu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || string(body) != `{"name":"opentofu"}` {
+			t.Errorf("request %d = %d %s, want the cached repository", i, resp.StatusCode, body)
+		}
+	}
+	if conditional != 1 {
+		t.Errorf("conditional requests = %d, want 1", conditional)
+	}
+}
+
+// memoryETagStore is an etagStore shared by token pools standing in for separate runs
+type memoryETagStore struct {
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+func (s *memoryETagStore) load(_ context.Context, url string) (*cachedResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[url], nil
+}
+
+func (s *memoryETagStore) save(_ context.Context, url string, resp *cachedResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[url] = resp
+	return nil
+}
+
+func TestTokenPoolPersistedConditionalRequests(t *testing.T) {
+	var full, conditional int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"name":"opentofu"}`)
+	}))
+	defer server.Close()
+
+	store := &memoryETagStore{entries: map[string]*cachedResponse{}}
+	for run := range 3 {
+		// Every run starts with an empty in-memory cache
+		pool := newTokenPool(&config.GitHubConfig{Token: "a"}, config.DefaultGitHubAPIURL)
+		pool.cache.setStore(store)
+		client := &http.Client{Transport: pool}
+
+		resp, err := client.Get(server.URL + "/repos/opentofu/opentofu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || string(body) != `{"name":"opentofu"}` {
+			t.Errorf("run %d = %d %s, want the cached repository", run, resp.StatusCode, body)
+		}
+		if got := resp.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("run %d Content-Type = %q, want the cached header", run, got)
+		}
+	}
+	if full != 1 || conditional != 2 {
+		t.Errorf("full requests = %d, conditional requests = %d, want 1 and 2", full, conditional)
+	}
+}
+
+func TestSharedTokenPool(t *testing.T) {
+	a := sharedTokenPool(&config.GitHubConfig{Token: "shared-a", Tokens: []string{"shared-b"}}, config.DefaultGitHubAPIURL)
+	if b := sharedTokenPool(&config.GitHubConfig{Token: "shared-a", Tokens: []string{"shared-b"}}, config.DefaultGitHubAPIURL); a != b {
+		t.Error("configs with the same credentials got different pools")
+	}
+	if c := sharedTokenPool(&config.GitHubConfig{Token: "shared-a"}, config.DefaultGitHubAPIURL); a == c {
+		t.Error("configs with different credentials share a pool")
+	}
+	if d := sharedTokenPool(&config.GitHubConfig{Token: "shared-a", Tokens: []string{"shared-b"}}, "https://github.example.com/api/v3"); a == d {
+		t.Error("the same credentials for different GitHub hosts share a pool")
+	}
+}
+
+func TestRequestResource(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://api.github.com/graphql", ResourceGraphQL},
+		{"https://api.github.com/repos/opentofu/opentofu", ResourceCore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := requestResource(req); got != tt.want {
+				t.Errorf("requestResource(%s) = %s, want %s", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/42/access_tokens" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := verifyJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey, 7); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":"ghs_installation","expires_at":%q}`, expiry.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	src := &appTokenSource{appID: 7, installationID: 42, key: key, baseURL: server.URL}
+	token, err := src.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "ghs_installation" || !token.Expiry.Equal(expiry) {
+		t.Errorf("Token() = %q expiring %s, want ghs_installation expiring %s", token.AccessToken, token.Expiry, expiry)
+	}
+}
+
+func verifyJWT(jwt string, key *rsa.PublicKey, appID int64) error {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("JWT has %d parts", len(parts))
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	var claims struct {
+		Iss int64 `json:"iss"`
+		Iat int64 `json:"iat"`
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	if claims.Iss != appID || claims.Iat > now || claims.Exp <= now || claims.Exp-claims.Iat > 600 {
+		return fmt.Errorf("invalid claims %+v", claims)
+	}
+	return nil
+}
@@ -0,0 +1,6 @@
+// Package scrapeerr classifies the errors that make indexing a provider or module version fail, so that failures
+// caused by the upstream repository can be told apart from failures of the backend itself.
+//
+// The class of a failed version is stored in the error_class column of provider_versions and module_versions. Only
+// failures that may go away on their own are retried automatically, see Class.Retryable.
+package scrapeerr
@@ -0,0 +1,118 @@
+package scrapeerr
+
+import (
+	"errors"
+
+	"github.com/opentofu/registry-ui/pkg/git"
+	"github.com/opentofu/registry-ui/pkg/tofu"
+)
+
+// Class is the kind of failure that stopped a version from being indexed
+type Class string
+
+const (
+	// Clone means the repository could not be cloned or fetched
+	Clone Class = "clone"
+	// TagMissing means the tag of the version listed in the registry does not exist in the repository
+	TagMissing Class = "tag_missing"
+	// TofuShow means tofu show could not load the module
+	TofuShow Class = "tofu_show"
+	// LicenseDetection means the licenses of the repository could not be detected
+	LicenseDetection Class = "license_detection"
+	// Upload means writing generated files to the bucket failed
+	Upload Class = "upload"
+	// DB means a database query failed
+	DB Class = "db"
+	// Unknown is used for errors that were not classified
+	Unknown Class = "unknown"
+)
+
+// Classes lists every class in the order they are reported
+var Classes = []Class{Clone, TagMissing, TofuShow, LicenseDetection, Upload, DB, Unknown}
+
+// Upstream reports whether failures of this class are caused by the repository of the provider or module rather than
+// by the backend
+func (c Class) Upstream() bool {
+	switch c {
+	case Clone, TagMissing, TofuShow:
+		return true
+	default:
+		return false
+	}
+}
+
+// Retryable reports whether failures of this class may go away without anything changing in the repository. Tags
+// and the content at a tag do not change, so there is no point in retrying a missing tag, a module tofu cannot load or
+// a license that cannot be detected.
+func (c Class) Retryable() bool {
+	switch c {
+	case TagMissing, TofuShow, LicenseDetection:
+		return false
+	default:
+		return true
+	}
+}
+
+// Error is an error with a failure class
+type Error struct {
+	Class Class
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap classifies err, unless it already carries a class from further down the call chain, which is more specific.
+// It returns nil for a nil error.
+func Wrap(class Class, err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+	return &Error{Class: class, Err: err}
+}
+
+// Checkout classifies an error from checking out the tag of a version: TagMissing when the repository has no such
+// tag, Clone otherwise
+func Checkout(err error) error {
+	if errors.Is(err, git.ErrRefNotFound) {
+		return Wrap(TagMissing, err)
+	}
+	return Wrap(Clone, err)
+}
+
+// Show classifies an error from tofu show: TofuShow when tofu could not load the module. Failures to run tofu at all,
+// such as a missing binary or a cancelled context, are left unclassified so the version is retried.
+func Show(err error) error {
+	if errors.Is(err, tofu.ErrInvalidModule) {
+		return Wrap(TofuShow, err)
+	}
+	return err
+}
+
+// ClassOf returns the class of err, or Unknown when it was not classified
+func ClassOf(err error) Class {
+	var classified *Error
+	if errors.As(err, &classified) {
+		return classified.Class
+	}
+	return Unknown
+}
+
+// Parse returns the class stored in the database, treating empty and unrecognised values as Unknown
+func Parse(s string) Class {
+	for _, class := range Classes {
+		if string(class) == s {
+			return class
+		}
+	}
+	return Unknown
+}
@@ -0,0 +1,44 @@
+package scrapeerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/opentofu/registry-ui/pkg/git"
+	"github.com/opentofu/registry-ui/pkg/tofu"
+)
+
+func TestClassOf(t *testing.T) {
+	base := errors.New("boom")
+
+	tests := []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{"unclassified", base, Unknown},
+		{"classified", Wrap(Upload, base), Upload},
+		{"wrapped after classification", fmt.Errorf("failed to store: %w", Wrap(DB, base)), DB},
+		{"innermost class wins", Wrap(Clone, fmt.Errorf("checkout: %w", Wrap(TagMissing, base))), TagMissing},
+		{"checkout of missing tag", Checkout(fmt.Errorf("%w: v1.0.0: %w", git.ErrRefNotFound, base)), TagMissing},
+		{"checkout failure", Checkout(fmt.Errorf("clone: %w", base)), Clone},
+		{"tofu show of an invalid module", Show(fmt.Errorf("%w: tofu show failed: %w", tofu.ErrInvalidModule, base)), TofuShow},
+		{"tofu show could not run", Show(fmt.Errorf("tofu show failed: %w", base)), Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassOf(tt.err); got != tt.want {
+				t.Errorf("ClassOf() = %q, want %q", got, tt.want)
+			}
+			if !errors.Is(tt.err, base) {
+				t.Errorf("errors.Is(err, base) = false, the original error must stay reachable")
+			}
+		})
+	}
+
+	if Wrap(DB, nil) != nil {
+		t.Errorf("Wrap(DB, nil) != nil")
+	}
+}
@@ -0,0 +1,292 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+const (
+	providerPrefix = "providers"
+	modulePrefix   = "modules"
+)
+
+// docIndexTypes maps provider_documents.document_type to the search index type. Other document types
+// (guides, ephemeral resources, ...) are not searchable.
+var docIndexTypes = map[string]IndexType{
+	"resources":   IndexTypeProviderResource,
+	"datasources": IndexTypeProviderDatasource,
+	"functions":   IndexTypeProviderFunction,
+}
+
+// BuildItems collects every searchable item from the latest completed version of each provider and module.
+// Blocked addresses are left out. The returned map is keyed by item ID and LastUpdated is not set.
+func BuildItems(ctx context.Context, db *pgxpool.Pool) (map[string]IndexItem, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "search.build_items")
+	defer span.End()
+
+	items := map[string]IndexItem{}
+
+	if err := buildProviderItems(ctx, db, items); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if err := buildModuleItems(ctx, db, items); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("search.items", len(items)))
+	return items, nil
+}
+
+func buildProviderItems(ctx context.Context, db *pgxpool.Pool, items map[string]IndexItem) error {
+	rows, err := db.Query(ctx, `
+		WITH latest_stats AS (
+			SELECT DISTINCT ON (repo_organisation, repo_name)
+				repo_organisation, repo_name, stars
+			FROM repository_stats
+			ORDER BY repo_organisation, repo_name, recorded_at DESC
+		),
+		latest_versions AS (
+			SELECT DISTINCT ON (provider_namespace, provider_name)
+				provider_namespace, provider_name, version
+			FROM provider_versions
+			WHERE scrape_status = 'completed'
+			ORDER BY provider_namespace, provider_name, safe_to_semver(version) DESC
+		)
+		SELECT
+			lv.provider_namespace,
+			lv.provider_name,
+			lv.version,
+			COALESCE(s.stars, 0) as stars,
+			COALESCE(cardinality(p.warnings), 0) as warnings
+		FROM latest_versions lv
+		JOIN providers p ON p.namespace = lv.provider_namespace
+			AND p.name = lv.provider_name
+		LEFT JOIN latest_stats s ON s.repo_organisation = p.repo_organisation
+			AND s.repo_name = p.repo_name
+		WHERE NOT EXISTS (
+			SELECT 1 FROM blocklist b
+			WHERE b.entity_type = 'provider'
+				AND b.namespace = lower(lv.provider_namespace)
+				AND (b.name = lower(lv.provider_name) OR b.name = '')
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to query providers: %w", err)
+	}
+
+	// Latest version per provider, used to attach documents to their parent item
+	latest := map[string]IndexItem{}
+	for rows.Next() {
+		var namespace, name, version string
+		var stars, warnings int
+		if err := rows.Scan(&namespace, &name, &version, &stars, &warnings); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan provider row: %w", err)
+		}
+
+		item := newProviderItem(namespace, name, version, stars, warnings)
+		items[item.ID] = item
+		latest[item.Addr] = item
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating provider rows: %w", err)
+	}
+
+	rows, err = db.Query(ctx, `
+		WITH latest_versions AS (
+			SELECT DISTINCT ON (provider_namespace, provider_name)
+				provider_namespace, provider_name, version
+			FROM provider_versions
+			WHERE scrape_status = 'completed'
+			ORDER BY provider_namespace, provider_name, safe_to_semver(version) DESC
+		)
+		SELECT
+			d.provider_namespace,
+			d.provider_name,
+			d.document_type,
+			d.document_name,
+			COALESCE(NULLIF(d.title, ''), d.document_name) as title,
+			COALESCE(d.description, '') as description
+		FROM provider_documents d
+		JOIN latest_versions lv ON lv.provider_namespace = d.provider_namespace
+			AND lv.provider_name = d.provider_name
+			AND lv.version = d.version
+		WHERE d.language = 'default'
+			AND d.document_type IN ('resources', 'datasources', 'functions')`)
+	if err != nil {
+		return fmt.Errorf("failed to query provider documents: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var namespace, name, docType, docName, title, description string
+		if err := rows.Scan(&namespace, &name, &docType, &docName, &title, &description); err != nil {
+			return fmt.Errorf("failed to scan provider document row: %w", err)
+		}
+
+		parent, ok := latest[fmt.Sprintf("%s/%s", namespace, name)]
+		if !ok {
+			// Provider is blocked
+			continue
+		}
+
+		item := newProviderDocItem(parent, namespace, name, docType, docName, title, description)
+		items[item.ID] = item
+	}
+
+	return rows.Err()
+}
+
+func buildModuleItems(ctx context.Context, db *pgxpool.Pool, items map[string]IndexItem) error {
+	rows, err := db.Query(ctx, `
+		WITH latest_stats AS (
+			SELECT DISTINCT ON (repo_organisation, repo_name)
+				repo_organisation, repo_name, stars
+			FROM repository_stats
+			ORDER BY repo_organisation, repo_name, recorded_at DESC
+		),
+		latest_versions AS (
+			SELECT DISTINCT ON (module_namespace, module_name, module_target)
+				module_namespace, module_name, module_target, version
+			FROM module_versions
+			WHERE scrape_status = 'completed'
+			ORDER BY module_namespace, module_name, module_target, safe_to_semver(version) DESC
+		)
+		SELECT
+			lv.module_namespace,
+			lv.module_name,
+			lv.module_target,
+			lv.version,
+			COALESCE(r.description, '') as description,
+			COALESCE(s.stars, 0) as stars,
+			COALESCE(array_agg(ms.submodule_name ORDER BY ms.submodule_name)
+				FILTER (WHERE ms.submodule_name IS NOT NULL), '{}') as submodules
+		FROM latest_versions lv
+		JOIN modules m ON m.namespace = lv.module_namespace
+			AND m.name = lv.module_name
+			AND m.target = lv.module_target
+		LEFT JOIN repositories r ON r.organisation = m.repo_organisation
+			AND r.name = m.repo_name
+		LEFT JOIN latest_stats s ON s.repo_organisation = m.repo_organisation
+			AND s.repo_name = m.repo_name
+		LEFT JOIN module_submodules ms ON ms.module_namespace = lv.module_namespace
+			AND ms.module_name = lv.module_name
+			AND ms.module_target = lv.module_target
+			AND ms.version = lv.version
+		WHERE NOT EXISTS (
+			SELECT 1 FROM blocklist b
+			WHERE b.entity_type = 'module'
+				AND b.namespace = lower(lv.module_namespace)
+				AND ((b.name = lower(lv.module_name) AND b.target = lower(lv.module_target))
+					OR (b.name = '' AND b.target = ''))
+		)
+		GROUP BY lv.module_namespace, lv.module_name, lv.module_target, lv.version, r.description, s.stars`)
+	if err != nil {
+		return fmt.Errorf("failed to query modules: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var namespace, name, target, version, description string
+		var stars int
+		var submodules []string
+		if err := rows.Scan(&namespace, &name, &target, &version, &description, &stars, &submodules); err != nil {
+			return fmt.Errorf("failed to scan module row: %w", err)
+		}
+
+		for _, item := range newModuleItems(namespace, name, target, version, description, stars, submodules) {
+			items[item.ID] = item
+		}
+	}
+
+	return rows.Err()
+}
+
+// newProviderItem creates the item of a provider, laid out like the legacy backend's
+func newProviderItem(namespace, name, version string, popularity, warnings int) IndexItem {
+	addr := fmt.Sprintf("%s/%s", namespace, name)
+	return IndexItem{
+		ID:      providerPrefix + "/" + addr,
+		Type:    IndexTypeProvider,
+		Addr:    addr,
+		Version: version,
+		Title:   name,
+		LinkVariables: map[string]string{
+			"namespace": namespace,
+			"name":      name,
+			"version":   version,
+		},
+		Popularity: popularity,
+		Warnings:   warnings,
+	}
+}
+
+// newProviderDocItem creates the item of a resource, data source or function document of the provider item parent
+func newProviderDocItem(parent IndexItem, namespace, name, docType, docName, title, description string) IndexItem {
+	return IndexItem{
+		ID:          parent.ID + "/" + docType + "/" + docName,
+		Type:        docIndexTypes[docType],
+		Addr:        parent.Addr,
+		Version:     parent.Version,
+		Title:       title,
+		Description: description,
+		LinkVariables: map[string]string{
+			"namespace": namespace,
+			"name":      name,
+			"version":   parent.Version,
+			"id":        docName,
+		},
+		ParentID:   parent.ID,
+		Popularity: parent.Popularity,
+		Warnings:   parent.Warnings,
+	}
+}
+
+// newModuleItems creates the item of a module followed by the items of its submodules
+func newModuleItems(namespace, name, target, version, description string, popularity int, submodules []string) []IndexItem {
+	addr := fmt.Sprintf("%s/%s/%s", namespace, name, target)
+	item := IndexItem{
+		ID:          modulePrefix + "/" + addr,
+		Type:        IndexTypeModule,
+		Addr:        addr,
+		Version:     version,
+		Title:       target,
+		Description: description,
+		LinkVariables: map[string]string{
+			"namespace":     namespace,
+			"name":          name,
+			"target_system": target,
+			"version":       version,
+		},
+		Popularity: popularity,
+	}
+
+	items := []IndexItem{item}
+	for _, submodule := range submodules {
+		items = append(items, IndexItem{
+			ID:          item.ID + "/" + submodule,
+			Type:        IndexTypeModuleSubmodule,
+			Addr:        addr,
+			Version:     version,
+			Title:       submodule,
+			Description: description,
+			LinkVariables: map[string]string{
+				"namespace":     namespace,
+				"name":          name,
+				"target_system": target,
+				"version":       version,
+				"submodule":     submodule,
+			},
+			ParentID:   item.ID,
+			Popularity: popularity,
+		})
+	}
+	return items
+}
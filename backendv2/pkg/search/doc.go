@@ -0,0 +1,3 @@
+// Package search builds the search.ndjson feed consumed by the search indexer from the database and answers
+// search queries against the entities table the indexer populates.
+package search
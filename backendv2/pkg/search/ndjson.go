@@ -0,0 +1,248 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// deletionRetention is how long deletions are kept in the feed so that indexers which have not run in a while
+// still pick them up. This matches the legacy backend.
+const deletionRetention = 30 * 24 * time.Hour
+
+// Stats summarises what changed in the search index during a run
+type Stats struct {
+	Items   int
+	Added   int
+	Updated int
+	Deleted int
+}
+
+// SyncItems records the current set of items in the search_index_items table. Items whose contents changed get a new
+// last_updated timestamp, items that are no longer present are marked as deleted and deletions older than the
+// retention period are purged.
+func SyncItems(ctx context.Context, db *pgxpool.Pool, items map[string]IndexItem) (*Stats, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "search.sync_items")
+	defer span.End()
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	existing := map[string]string{}
+	rows, err := tx.Query(ctx, `SELECT id, checksum FROM search_index_items WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query search index items: %w", err)
+	}
+	for rows.Next() {
+		var id, checksum string
+		if err := rows.Scan(&id, &checksum); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan search index item: %w", err)
+		}
+		existing[id] = checksum
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search index items: %w", err)
+	}
+
+	stats := &Stats{Items: len(items)}
+	batch := &pgx.Batch{}
+
+	for id, item := range items {
+		contents, checksum, err := itemContents(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal search index item %s: %w", id, err)
+		}
+
+		previous, ok := existing[id]
+		if ok && previous == checksum {
+			continue
+		}
+		if ok {
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+
+		batch.Queue(`
+			INSERT INTO search_index_items (id, item, checksum, last_updated, deleted_at)
+			VALUES ($1, $2, $3, NOW(), NULL)
+			ON CONFLICT (id)
+			DO UPDATE SET
+				item = EXCLUDED.item,
+				checksum = EXCLUDED.checksum,
+				last_updated = NOW(),
+				deleted_at = NULL,
+				updated_at = NOW()`,
+			id, contents, checksum)
+	}
+
+	for id := range existing {
+		if _, ok := items[id]; ok {
+			continue
+		}
+		stats.Deleted++
+		batch.Queue(`UPDATE search_index_items SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1`, id)
+	}
+
+	if batch.Len() > 0 {
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return nil, fmt.Errorf("failed to store search index items: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM search_index_items WHERE deleted_at < $1`, time.Now().Add(-deletionRetention))
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge old search index deletions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	span.SetAttributes(
+		attribute.Int("search.items", stats.Items),
+		attribute.Int("search.added", stats.Added),
+		attribute.Int("search.updated", stats.Updated),
+		attribute.Int("search.deleted", stats.Deleted),
+	)
+
+	return stats, nil
+}
+
+// itemContents returns the contents of an item as stored in search_index_items and their checksum. LastUpdated is left
+// out so that only changes to the contents change the checksum.
+func itemContents(item IndexItem) ([]byte, string, error) {
+	item.LastUpdated = time.Time{}
+	contents, err := json.Marshal(item)
+	if err != nil {
+		return nil, "", err
+	}
+	hash := md5.Sum(contents)
+	return contents, hex.EncodeToString(hash[:]), nil
+}
+
+// storedItem is a row of search_index_items
+type storedItem struct {
+	ID          string
+	Contents    []byte
+	LastUpdated time.Time
+	DeletedAt   *time.Time
+}
+
+// GenerateNDJSON renders the search_index_items table as a search.ndjson feed: a header line, followed by one line
+// per retained deletion and one line per live item.
+func GenerateNDJSON(ctx context.Context, db *pgxpool.Pool) ([]byte, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "search.generate_ndjson")
+	defer span.End()
+
+	// Deletions come first so that an item which was removed and re-added ends up present
+	rows, err := db.Query(ctx, `
+		SELECT id, item, last_updated, deleted_at
+		FROM search_index_items
+		ORDER BY deleted_at IS NULL, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query search index items: %w", err)
+	}
+	defer rows.Close()
+
+	var stored []storedItem
+	var deletions int
+	for rows.Next() {
+		var item storedItem
+		if err := rows.Scan(&item.ID, &item.Contents, &item.LastUpdated, &item.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan search index item: %w", err)
+		}
+		if item.DeletedAt != nil {
+			deletions++
+		}
+		stored = append(stored, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search index items: %w", err)
+	}
+
+	data, err := encodeNDJSON(time.Now(), stored)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("search.additions", len(stored)-deletions),
+		attribute.Int("search.deletions", deletions),
+		attribute.Int("search.bytes", len(data)),
+	)
+
+	return data, nil
+}
+
+// RenderItems renders items as a search.ndjson feed without recording them in search_index_items. Every item is an
+// addition last updated at now and the feed has no deletions, so it is a full snapshot of the current items.
+func RenderItems(items map[string]IndexItem, now time.Time) ([]byte, error) {
+	stored := make([]storedItem, 0, len(items))
+	for _, item := range items {
+		contents, _, err := itemContents(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal search index item %s: %w", item.ID, err)
+		}
+		stored = append(stored, storedItem{ID: item.ID, Contents: contents, LastUpdated: now})
+	}
+	slices.SortFunc(stored, func(a, b storedItem) int { return strings.Compare(a.ID, b.ID) })
+
+	return encodeNDJSON(now, stored)
+}
+
+// encodeNDJSON writes the header line with lastUpdated, followed by a delete or add line for every stored item in order
+func encodeNDJSON(lastUpdated time.Time, stored []storedItem) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(GeneratedIndexItem{
+		Type: GeneratedIndexItemHeader,
+		Header: &GeneratedIndexHeader{
+			LastUpdated: lastUpdated.UTC(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for _, row := range stored {
+		line := GeneratedIndexItem{}
+		if row.DeletedAt != nil {
+			line.Type = GeneratedIndexItemDelete
+			line.Deletion = &ItemDeletion{ID: row.ID, DeletedAt: row.DeletedAt.UTC()}
+		} else {
+			var item IndexItem
+			if err := json.Unmarshal(row.Contents, &item); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal search index item %s: %w", row.ID, err)
+			}
+			item.LastUpdated = row.LastUpdated.UTC()
+			line.Type = GeneratedIndexItemAdd
+			line.Addition = &item
+		}
+
+		if err := encoder.Encode(line); err != nil {
+			return nil, fmt.Errorf("failed to write search index item %s: %w", row.ID, err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
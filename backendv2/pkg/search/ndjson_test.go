@@ -0,0 +1,165 @@
+package search
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// legacyFeed is search.ndjson as written by the legacy backend (backend/internal/search) for the same registry
+// contents. The legacy backend escapes HTML characters, which decodes to the same values.
+const legacyFeed = `{"type":"header","header":{"last_updated":"2026-05-01T10:00:00Z"}}
+{"type":"delete","deletion":{"id":"providers/example/old","deleted_at":"2026-04-30T09:00:00Z"}}
+{"type":"add","addition":{"id":"modules/terraform-aws-modules/vpc/aws","type":"module","addr":"terraform-aws-modules/vpc/aws","version":"v5.0.0","title":"aws","description":"Terraform module to create AWS VPC \u0026 subnets","link":{"name":"vpc","namespace":"terraform-aws-modules","target_system":"aws","version":"v5.0.0"},"parent_id":"","last_updated":"2026-05-01T08:00:00Z","popularity":3000,"warnings":0}}
+{"type":"add","addition":{"id":"modules/terraform-aws-modules/vpc/aws/vpc-endpoints","type":"module/submodule","addr":"terraform-aws-modules/vpc/aws","version":"v5.0.0","title":"vpc-endpoints","description":"Terraform module to create AWS VPC \u0026 subnets","link":{"name":"vpc","namespace":"terraform-aws-modules","submodule":"vpc-endpoints","target_system":"aws","version":"v5.0.0"},"parent_id":"modules/terraform-aws-modules/vpc/aws","last_updated":"2026-05-01T08:00:00Z","popularity":3000,"warnings":0}}
+{"type":"add","addition":{"id":"providers/hashicorp/aws","type":"provider","addr":"hashicorp/aws","version":"v5.0.0","title":"aws","description":"","link":{"name":"aws","namespace":"hashicorp","version":"v5.0.0"},"parent_id":"","last_updated":"2026-05-01T09:00:00Z","popularity":10,"warnings":1}}
+{"type":"add","addition":{"id":"providers/hashicorp/aws/resources/instance","type":"provider/resource","addr":"hashicorp/aws","version":"v5.0.0","title":"aws_instance","description":"Provides an EC2 instance \u003cresource\u003e.","link":{"id":"instance","name":"aws","namespace":"hashicorp","version":"v5.0.0"},"parent_id":"providers/hashicorp/aws","last_updated":"2026-05-01T09:00:00Z","popularity":10,"warnings":1}}
+`
+
+func TestGenerateNDJSONMatchesLegacyFormat(t *testing.T) {
+	providerUpdated := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	moduleUpdated := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	deleted := time.Date(2026, 4, 30, 11, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	provider := newProviderItem("hashicorp", "aws", "v5.0.0", 10, 1)
+	items := []IndexItem{
+		provider,
+		newProviderDocItem(provider, "hashicorp", "aws", "resources", "instance", "aws_instance", "Provides an EC2 instance <resource>."),
+	}
+	modules := newModuleItems("terraform-aws-modules", "vpc", "aws", "v5.0.0", "Terraform module to create AWS VPC & subnets", 3000, []string{"vpc-endpoints"})
+
+	// Rows in the order GenerateNDJSON reads them: deletions first, then by ID
+	stored := []storedItem{{ID: "providers/example/old", DeletedAt: &deleted}}
+	for _, item := range append(modules, items...) {
+		contents, _, err := itemContents(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lastUpdated := providerUpdated
+		if strings.HasPrefix(item.ID, modulePrefix+"/") {
+			lastUpdated = moduleUpdated
+		}
+		stored = append(stored, storedItem{ID: item.ID, Contents: contents, LastUpdated: lastUpdated})
+	}
+
+	data, err := encodeNDJSON(time.Date(2026, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60)), stored)
+	if err != nil {
+		t.Fatalf("encodeNDJSON() error = %v", err)
+	}
+
+	got := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	want := strings.Split(strings.TrimSuffix(legacyFeed, "\n"), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(got), len(want), data)
+	}
+	for i := range want {
+		var gotLine, wantLine any
+		if err := json.Unmarshal([]byte(got[i]), &gotLine); err != nil {
+			t.Fatalf("line %d is not JSON: %v\n%s", i+1, err, got[i])
+		}
+		if err := json.Unmarshal([]byte(want[i]), &wantLine); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(gotLine, wantLine) {
+			t.Errorf("line %d =\n%s\nwant\n%s", i+1, got[i], want[i])
+		}
+	}
+
+	// Every line decodes into the feed types without unknown fields
+	for i, line := range got {
+		var item GeneratedIndexItem
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&item); err != nil {
+			t.Errorf("line %d does not decode strictly: %v", i+1, err)
+		}
+	}
+}
+
+func TestItemContents(t *testing.T) {
+	item := newProviderItem("hashicorp", "aws", "v5.0.0", 10, 0)
+	_, checksum, err := itemContents(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(*IndexItem)
+		wantChanged bool
+	}{
+		{"last updated is ignored", func(i *IndexItem) { i.LastUpdated = time.Now() }, false},
+		{"new version", func(i *IndexItem) { i.Version = "v5.1.0"; i.LinkVariables = map[string]string{"version": "v5.1.0"} }, true},
+		{"popularity", func(i *IndexItem) { i.Popularity = 11 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := item
+			tt.modify(&modified)
+			contents, got, err := itemContents(modified)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed := got != checksum; changed != tt.wantChanged {
+				t.Errorf("checksum changed = %v, want %v", changed, tt.wantChanged)
+			}
+			var decoded IndexItem
+			if err := json.Unmarshal(contents, &decoded); err != nil || !decoded.LastUpdated.IsZero() {
+				t.Errorf("stored contents = %s, want an item without last_updated", contents)
+			}
+		})
+	}
+}
+
+func TestRenderItems(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	provider := newProviderItem("hashicorp", "aws", "v5.0.0", 10, 0)
+	doc := newProviderDocItem(provider, "hashicorp", "aws", "resources", "instance", "aws_instance", "")
+
+	tests := []struct {
+		name    string
+		items   map[string]IndexItem
+		wantIDs []string
+	}{
+		{name: "no items"},
+		{
+			name:    "items are sorted by ID",
+			items:   map[string]IndexItem{doc.ID: doc, provider.ID: provider},
+			wantIDs: []string{"providers/hashicorp/aws", "providers/hashicorp/aws/resources/instance"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := RenderItems(tt.items, now)
+			if err != nil {
+				t.Fatalf("RenderItems() error = %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			var header GeneratedIndexItem
+			if err := json.Unmarshal([]byte(lines[0]), &header); err != nil || header.Header == nil || !header.Header.LastUpdated.Equal(now) {
+				t.Errorf("header = %s, want last_updated %s", lines[0], now)
+			}
+
+			var gotIDs []string
+			for _, line := range lines[1:] {
+				var item GeneratedIndexItem
+				if err := json.Unmarshal([]byte(line), &item); err != nil {
+					t.Fatal(err)
+				}
+				if item.Type != GeneratedIndexItemAdd || !item.Addition.LastUpdated.Equal(now) {
+					t.Errorf("line = %s, want an addition last updated at %s", line, now)
+					continue
+				}
+				gotIDs = append(gotIDs, item.Addition.ID)
+			}
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("RenderItems() IDs = %v, want %v", gotIDs, tt.wantIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// Entity is a row of the entities table populated by the search indexer (search/pg-indexer)
+type Entity struct {
+	ID            string          `json:"id"`
+	LastUpdated   time.Time       `json:"last_updated"`
+	Type          string          `json:"type"`
+	Addr          string          `json:"addr"`
+	Version       string          `json:"version"`
+	Title         string          `json:"title"`
+	Description   *string         `json:"description,omitempty"`
+	LinkVariables json.RawMessage `json:"link_variables,omitempty"`
+	Popularity    int             `json:"popularity"`
+	Warnings      int             `json:"warnings"`
+}
+
+// TopProvider is a single result of TopProviders
+type TopProvider struct {
+	Addr       string `json:"addr"`
+	Version    string `json:"version"`
+	Popularity int    `json:"popularity"`
+}
+
+// searchQuery ranks entities the same way as the search worker (search/worker/src/query.ts), keep them in sync.
+// It requires the pg_trgm extension for similarity().
+const searchQuery = `
+  WITH search_terms AS (
+    SELECT unnest(regexp_split_to_array($1, '[ /]+')) AS term
+  ),
+  term_matches AS (
+    SELECT e.*
+    FROM entities e
+    INNER JOIN search_terms st
+      ON e.addr ILIKE '%' || st.term || '%'
+      OR e.description ILIKE '%' || st.term || '%'
+    GROUP BY id, last_updated, type, addr, version, title, description, link_variables, document, popularity, warnings
+  ),
+  max_popularity AS (
+    SELECT max(popularity) AS max_popularity
+    FROM term_matches tm
+  ),
+  ranked_entities AS (
+    SELECT *,
+      /* The rank fudge ranks providers and modules higher than their resources/submodules (excluding archived terraform-providers) */
+      CASE
+        WHEN (type = 'provider' OR type = 'module')
+          AND addr NOT LIKE 'terraform-providers/%'
+          AND addr NOT LIKE 'opentofu/%' THEN 1
+        ELSE 0
+      END AS type_rank_fudge,
+      0 AS warnings_rank_fudge,
+      /* Give a slight boost to providers with a higher star rating. */
+      tm.popularity / (SELECT CASE WHEN max_popularity > 0 THEN max_popularity ELSE 1 END FROM max_popularity) AS popularity_rank,
+      /* Text similarity rankings, each taking a value from 0 to 1. */
+      similarity(tm.addr, $1) AS title_sim,
+      similarity(tm.description, $1) AS description_sim,
+      similarity(link_variables ->> 'name', $1) AS name_sim
+    FROM term_matches tm
+  ),
+  providers AS (
+    SELECT *
+    FROM ranked_entities
+    WHERE type LIKE 'provider%'
+    ORDER BY (type_rank_fudge + warnings_rank_fudge + 1) * (popularity_rank + title_sim + name_sim + description_sim / 0.5) DESC
+    LIMIT 5
+  ),
+  modules AS (
+    SELECT *
+    FROM ranked_entities
+    WHERE type LIKE 'module%'
+    ORDER BY (type_rank_fudge + warnings_rank_fudge + 1) * (popularity_rank + title_sim + name_sim + description_sim / 0.5) DESC
+    LIMIT 5
+  )
+  SELECT id, last_updated, type, addr, version, title, description, link_variables, popularity, warnings
+  FROM providers
+  UNION ALL
+  SELECT id, last_updated, type, addr, version, title, description, link_variables, popularity, warnings
+  FROM modules`
+
+// topProvidersQuery sorts providers by popularity and removes forks with the same title, preferring the hashicorp
+// namespace, the same way as the search worker
+const topProvidersQuery = `
+  SELECT DISTINCT ON (popularity, lower(title))
+    addr, version, popularity
+  FROM entities
+  WHERE type = 'provider'
+  ORDER BY popularity DESC, lower(title),
+    CASE
+      WHEN addr LIKE 'hashicorp/%' THEN 0
+      ELSE 1
+    END
+  LIMIT $1`
+
+// Query returns the best matching providers and modules (up to 5 each) for a search term
+func Query(ctx context.Context, db *pgxpool.Pool, term string) ([]Entity, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "search.query")
+	defer span.End()
+
+	rows, err := db.Query(ctx, searchQuery, term)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query search entities: %w", err)
+	}
+
+	entities, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Entity, error) {
+		var e Entity
+		var linkVariables []byte
+		err := row.Scan(&e.ID, &e.LastUpdated, &e.Type, &e.Addr, &e.Version, &e.Title, &e.Description, &linkVariables, &e.Popularity, &e.Warnings)
+		e.LinkVariables = linkVariables
+		return e, err
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to scan search entities: %w", err)
+	}
+
+	return entities, nil
+}
+
+// TopProviders returns the most popular providers
+func TopProviders(ctx context.Context, db *pgxpool.Pool, limit int) ([]TopProvider, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "search.top_providers")
+	defer span.End()
+
+	rows, err := db.Query(ctx, topProvidersQuery, limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query top providers: %w", err)
+	}
+
+	providers, err := pgx.CollectRows(rows, pgx.RowToStructByPos[TopProvider])
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to scan top providers: %w", err)
+	}
+
+	return providers, nil
+}
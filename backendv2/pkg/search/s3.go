@@ -0,0 +1,65 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/opentofu/registry-ui/pkg/bucket"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// UploadNDJSON uploads a generated search.ndjson feed to the bucket
+func UploadNDJSON(ctx context.Context, store bucket.Store, key string, data []byte) error {
+	ctx, span := telemetry.Tracer().Start(ctx, "search.upload_ndjson")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("key", key),
+		attribute.Int64("size", int64(len(data))),
+	)
+
+	err := store.Put(ctx, bucket.Object{
+		Key:         key,
+		Body:        data,
+		ContentType: "application/x-ndjson",
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// Publish rebuilds the search items from the database, records the changes and uploads the resulting search.ndjson
+// feed to key, for commands that change what is searchable outside of generate-search-index
+func Publish(ctx context.Context, db *pgxpool.Pool, store bucket.Store, key string) (*Stats, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "search.publish")
+	defer span.End()
+
+	items, err := BuildItems(ctx, db)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to build search items: %w", err)
+	}
+
+	stats, err := SyncItems(ctx, db, items)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to record search items: %w", err)
+	}
+
+	data, err := GenerateNDJSON(ctx, db)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to generate search index: %w", err)
+	}
+
+	if err := UploadNDJSON(ctx, store, key, data); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to upload search index: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,60 @@
+package search
+
+import "time"
+
+// IndexType is the kind of entity a search index item describes
+type IndexType string
+
+const (
+	IndexTypeProvider           IndexType = "provider"
+	IndexTypeProviderResource   IndexType = "provider/resource"
+	IndexTypeProviderDatasource IndexType = "provider/datasource"
+	IndexTypeProviderFunction   IndexType = "provider/function"
+	IndexTypeModule             IndexType = "module"
+	IndexTypeModuleSubmodule    IndexType = "module/submodule"
+)
+
+// GeneratedIndexItemType is the type of a single line in search.ndjson
+type GeneratedIndexItemType string
+
+const (
+	GeneratedIndexItemHeader GeneratedIndexItemType = "header"
+	GeneratedIndexItemAdd    GeneratedIndexItemType = "add"
+	GeneratedIndexItemDelete GeneratedIndexItemType = "delete"
+)
+
+// IndexItem is a single searchable entity. The JSON layout matches the legacy backend so the existing
+// search indexer can consume the feed without changes.
+type IndexItem struct {
+	// ID does not contain the version so newer versions replace older ones in the search index
+	ID            string            `json:"id"`
+	Type          IndexType         `json:"type"`
+	Addr          string            `json:"addr"`
+	Version       string            `json:"version"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	LinkVariables map[string]string `json:"link"`
+	ParentID      string            `json:"parent_id"`
+	LastUpdated   time.Time         `json:"last_updated"`
+	Popularity    int               `json:"popularity"`
+	Warnings      int               `json:"warnings"`
+}
+
+// GeneratedIndexHeader is the first line of search.ndjson
+type GeneratedIndexHeader struct {
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// ItemDeletion signals that an item with the given ID must be removed from the search index
+type ItemDeletion struct {
+	ID        string    `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// GeneratedIndexItem is a single line of search.ndjson
+type GeneratedIndexItem struct {
+	Type     GeneratedIndexItemType `json:"type"`
+	Header   *GeneratedIndexHeader  `json:"header,omitempty"`
+	Addition *IndexItem             `json:"addition,omitempty"`
+	Deletion *ItemDeletion          `json:"deletion,omitempty"`
+}
@@ -0,0 +1,187 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+
+	database "github.com/opentofu/registry-ui/pkg/db"
+	"github.com/opentofu/registry-ui/pkg/scrapeerr"
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// Kind selects the versions table and how addresses are built from it
+type Kind struct {
+	Name    string
+	table   string
+	address string
+}
+
+var (
+	Providers = Kind{
+		Name:    "providers",
+		table:   "provider_versions",
+		address: "provider_namespace || '/' || provider_name",
+	}
+	Modules = Kind{
+		Name:    "modules",
+		table:   "module_versions",
+		address: "module_namespace || '/' || module_name || '/' || module_target",
+	}
+)
+
+// LoadSummary queries the status counts, skip reasons and failures of a kind of versions. recent limits the number
+// of recent failures.
+func LoadSummary(ctx context.Context, db *pgxpool.Pool, kind Kind, recent int) (*Summary, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "status.load_summary")
+	defer span.End()
+	span.SetAttributes(attribute.String("kind", kind.Name))
+
+	summary := &Summary{
+		Statuses:        make(map[string]int),
+		SkipReasons:     make(map[string]int),
+		FailuresByClass: []ClassCount{},
+		FailuresByError: []ErrorCount{},
+		RecentFailures:  []Failure{},
+	}
+
+	err := database.QueryEach(ctx, db, fmt.Sprintf(`
+		SELECT scrape_status, COALESCE(skip_reason, ''), COUNT(*)
+		FROM %s
+		GROUP BY 1, 2`, kind.table), nil, func(rows pgx.Rows) error {
+		var status, reason string
+		var count int
+		if err := rows.Scan(&status, &reason, &count); err != nil {
+			return err
+		}
+		summary.Statuses[status] += count
+		if status == "skipped" && reason != "" {
+			summary.SkipReasons[reason] += count
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to count %s by status: %w", kind.Name, err)
+	}
+
+	err = database.QueryEach(ctx, db, fmt.Sprintf(`
+		SELECT COALESCE(error_class, ''), COUNT(*)
+		FROM %s
+		WHERE scrape_status = 'failed'
+		GROUP BY 1
+		ORDER BY 2 DESC, 1`, kind.table), nil, func(rows pgx.Rows) error {
+		var class string
+		var count int
+		if err := rows.Scan(&class, &count); err != nil {
+			return err
+		}
+		c := scrapeerr.Parse(class)
+		summary.FailuresByClass = append(summary.FailuresByClass, ClassCount{Class: string(c), Upstream: c.Upstream(), Count: count})
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to count %s failures by class: %w", kind.Name, err)
+	}
+
+	// Error messages contain versions and paths, the first part names the step that failed
+	err = database.QueryEach(ctx, db, fmt.Sprintf(`
+		SELECT COALESCE(error_class, ''), split_part(COALESCE(error_message, ''), ': ', 1), COUNT(*), MIN(COALESCE(error_message, ''))
+		FROM %s
+		WHERE scrape_status = 'failed'
+		GROUP BY 1, 2
+		ORDER BY 3 DESC, 1, 2`, kind.table), nil, func(rows pgx.Rows) error {
+		var e ErrorCount
+		if err := rows.Scan(&e.Class, &e.Error, &e.Count, &e.Example); err != nil {
+			return err
+		}
+		e.Class = string(scrapeerr.Parse(e.Class))
+		summary.FailuresByError = append(summary.FailuresByError, e)
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to count %s failures by error: %w", kind.Name, err)
+	}
+
+	err = database.QueryEach(ctx, db, fmt.Sprintf(`
+		SELECT %s, version, COALESCE(error_class, ''), COALESCE(error_message, ''), attempt_count,
+		       COALESCE(last_attempt_at, updated_at, NOW())
+		FROM %s
+		WHERE scrape_status = 'failed'
+		ORDER BY 6 DESC
+		LIMIT $1`, kind.address, kind.table), []any{recent}, func(rows pgx.Rows) error {
+		var f Failure
+		if err := rows.Scan(&f.Address, &f.Version, &f.ErrorClass, &f.ErrorMessage, &f.Attempts, &f.LastAttemptAt); err != nil {
+			return err
+		}
+		f.ErrorClass = string(scrapeerr.Parse(f.ErrorClass))
+		summary.RecentFailures = append(summary.RecentFailures, f)
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query recent %s failures: %w", kind.Name, err)
+	}
+
+	return summary, nil
+}
+
+// LoadStoredVersions returns the versions in the database by address, excluding versions removed from the registry
+func LoadStoredVersions(ctx context.Context, db *pgxpool.Pool, kind Kind) (map[string]map[string]bool, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "status.load_stored_versions")
+	defer span.End()
+	span.SetAttributes(attribute.String("kind", kind.Name))
+
+	stored := make(map[string]map[string]bool)
+	err := database.QueryEach(ctx, db, fmt.Sprintf(`
+		SELECT %s, version
+		FROM %s
+		WHERE scrape_status <> 'removed'`, kind.address, kind.table), nil, func(rows pgx.Rows) error {
+		var addr, version string
+		if err := rows.Scan(&addr, &version); err != nil {
+			return err
+		}
+		if stored[addr] == nil {
+			stored[addr] = make(map[string]bool)
+		}
+		stored[addr][version] = true
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to load stored %s versions: %w", kind.Name, err)
+	}
+	return stored, nil
+}
+
+// LoadRepositoryStats counts the repositories whose stats were not synced within staleAfter
+func LoadRepositoryStats(ctx context.Context, db *pgxpool.Pool, staleAfter time.Duration) (*RepositoryStats, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "status.load_repository_stats")
+	defer span.End()
+
+	stats := &RepositoryStats{StaleAfter: staleAfter.String()}
+	err := db.QueryRow(ctx, `
+		WITH latest AS (
+			SELECT r.organisation, r.name, MAX(s.recorded_at) AS recorded_at
+			FROM repositories r
+			LEFT JOIN repository_stats s
+				ON s.repo_organisation = r.organisation AND s.repo_name = r.name
+			GROUP BY r.organisation, r.name
+		)
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE recorded_at IS NOT NULL AND recorded_at <= NOW() - make_interval(secs => $1)),
+		       COUNT(*) FILTER (WHERE recorded_at IS NULL),
+		       MIN(recorded_at)
+		FROM latest`, staleAfter.Seconds()).Scan(&stats.Repositories, &stats.Stale, &stats.NeverSynced, &stats.OldestSyncedAt)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query repository stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,4 @@
+// Package status summarises the health of the index: how many provider and module versions were scraped, skipped
+// and failed, why they failed, which repositories have stale stats and which registry versions are missing from the
+// database.
+package status
@@ -0,0 +1,86 @@
+package status
+
+import (
+	"slices"
+	"time"
+)
+
+// Report is the health summary printed by the status command
+type Report struct {
+	GeneratedAt     time.Time        `json:"generated_at"`
+	Providers       *Summary         `json:"providers"`
+	Modules         *Summary         `json:"modules"`
+	RepositoryStats *RepositoryStats `json:"repository_stats"`
+}
+
+// Summary is the health of the provider or module versions
+type Summary struct {
+	// Statuses counts versions by scrape_status
+	Statuses map[string]int `json:"statuses"`
+	// SkipReasons counts skipped versions by skip_reason
+	SkipReasons map[string]int `json:"skip_reasons"`
+	// FailuresByClass counts failed versions by error_class
+	FailuresByClass []ClassCount `json:"failures_by_class"`
+	// FailuresByError counts failed versions by the step that failed, the first part of the error message
+	FailuresByError []ErrorCount `json:"failures_by_error"`
+	RecentFailures  []Failure    `json:"recent_failures"`
+	// MissingVersions lists the versions in the registry that are not in the database, nil when the registry was not
+	// compared
+	MissingVersions []string `json:"missing_versions"`
+}
+
+// Failed returns the number of failed versions
+func (s *Summary) Failed() int {
+	return s.Statuses["failed"]
+}
+
+// ClassCount is the number of failed versions of an error class
+type ClassCount struct {
+	Class string `json:"class"`
+	// Upstream is true when failures of this class are caused by the provider or module repository
+	Upstream bool `json:"upstream"`
+	Count    int  `json:"count"`
+}
+
+// ErrorCount is the number of failed versions with the same error
+type ErrorCount struct {
+	Class   string `json:"class"`
+	Error   string `json:"error"`
+	Count   int    `json:"count"`
+	Example string `json:"example"`
+}
+
+// Failure is a failed version
+type Failure struct {
+	Address       string    `json:"address"`
+	Version       string    `json:"version"`
+	ErrorClass    string    `json:"error_class"`
+	ErrorMessage  string    `json:"error_message"`
+	Attempts      int       `json:"attempts"`
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+}
+
+// RepositoryStats summarises how up to date the repository stats (stars, forks, ...) are
+type RepositoryStats struct {
+	Repositories int    `json:"repositories"`
+	StaleAfter   string `json:"stale_after"`
+	// Stale counts repositories whose latest stats are older than StaleAfter, NeverSynced those without any stats
+	Stale          int        `json:"stale"`
+	NeverSynced    int        `json:"never_synced"`
+	OldestSyncedAt *time.Time `json:"oldest_synced_at,omitempty"`
+}
+
+// MissingVersions returns the versions listed in the registry that are not stored, as address@version sorted by
+// address. listed and stored are keyed by address (namespace/name for providers, namespace/name/target for modules).
+func MissingVersions(listed map[string][]string, stored map[string]map[string]bool) []string {
+	missing := []string{}
+	for addr, versions := range listed {
+		for _, version := range versions {
+			if !stored[addr][version] {
+				missing = append(missing, addr+"@"+version)
+			}
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
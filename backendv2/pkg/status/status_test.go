@@ -0,0 +1,47 @@
+package status
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMissingVersions(t *testing.T) {
+	tests := []struct {
+		name   string
+		listed map[string][]string
+		stored map[string]map[string]bool
+		want   []string
+	}{
+		{
+			name:   "nothing stored",
+			listed: map[string][]string{"hashicorp/aws": {"1.0.0", "1.1.0"}},
+			stored: map[string]map[string]bool{},
+			want:   []string{"hashicorp/aws@1.0.0", "hashicorp/aws@1.1.0"},
+		},
+		{
+			name:   "everything stored",
+			listed: map[string][]string{"hashicorp/aws": {"1.0.0"}},
+			stored: map[string]map[string]bool{"hashicorp/aws": {"1.0.0": true}},
+			want:   []string{},
+		},
+		{
+			name: "only new versions missing",
+			listed: map[string][]string{
+				"hashicorp/aws":                 {"1.0.0", "2.0.0"},
+				"terraform-aws-modules/vpc/aws": {"5.0.0"},
+			},
+			stored: map[string]map[string]bool{
+				"hashicorp/aws": {"1.0.0": true, "0.9.0": true},
+			},
+			want: []string{"hashicorp/aws@2.0.0", "terraform-aws-modules/vpc/aws@5.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MissingVersions(tt.listed, tt.stored); !slices.Equal(got, tt.want) {
+				t.Errorf("MissingVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
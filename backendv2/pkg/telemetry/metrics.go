@@ -0,0 +1,175 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/grpc/encoding/gzip"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+)
+
+const meterName = "github.com/opentofu/registry-ui"
+
+// durationBuckets are histogram boundaries in seconds, sized for git and tofu operations
+// which take anywhere from a fraction of a second to several minutes
+var durationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+// Instruments are created from the global meter provider, which forwards them to the
+// provider installed by SetupMetrics. Without it they are no-ops.
+var (
+	meter = otel.Meter(meterName)
+
+	versionsIndexed = instrument(meter.Int64Counter("registry.versions.indexed",
+		metric.WithDescription("Provider and module versions stored, by scrape status"),
+		metric.WithUnit("{version}")))
+	tofuShowDuration = instrument(meter.Float64Histogram("tofu.show.duration",
+		metric.WithDescription("Duration of tofu show runs"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...)))
+	gitDuration = instrument(meter.Float64Histogram("git.operation.duration",
+		metric.WithDescription("Duration of git clones and fetches"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...)))
+	bytesUploaded = instrument(meter.Int64Counter("bucket.uploaded",
+		metric.WithDescription("Bytes written to the bucket"),
+		metric.WithUnit("By")))
+	githubRemaining = instrument(meter.Int64Gauge("github.rate_limit.remaining",
+		metric.WithDescription("GitHub API requests (GraphQL points for the graphql resource) remaining in the current rate limit window"),
+		metric.WithUnit("{request}")))
+)
+
+func instrument[T any](i T, err error) T {
+	if err != nil {
+		otel.Handle(err)
+	}
+	return i
+}
+
+// RecordVersionIndexed counts a version stored with the given scrape status.
+// reason is the skip reason or error class, empty for completed versions.
+func RecordVersionIndexed(ctx context.Context, kind, status, reason string) {
+	versionsIndexed.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("registry.kind", kind),
+		attribute.String("registry.scrape_status", status),
+		attribute.String("registry.reason", reason),
+	))
+}
+
+// RecordTofuShow records how long a tofu show run took
+func RecordTofuShow(ctx context.Context, start time.Time, err error) {
+	tofuShowDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.Bool("success", err == nil),
+	))
+}
+
+// RecordGitOperation records how long a git clone or fetch took
+func RecordGitOperation(ctx context.Context, operation string, start time.Time, err error) {
+	gitDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("git.operation", operation),
+		attribute.Bool("success", err == nil),
+	))
+}
+
+// RecordUpload counts bytes written to the bucket
+func RecordUpload(ctx context.Context, bucketType string, size int) {
+	bytesUploaded.Add(ctx, int64(size), metric.WithAttributes(
+		attribute.String("bucket.type", bucketType),
+	))
+}
+
+// RecordGitHubRateLimit records the quota a GitHub credential has left for a rate limit resource such as core or graphql
+func RecordGitHubRateLimit(ctx context.Context, credential, resource string, remaining int) {
+	githubRemaining.Record(ctx, int64(remaining), metric.WithAttributes(
+		attribute.String("github.credential", credential),
+		attribute.String("github.resource", resource),
+	))
+}
+
+// SetupMetrics installs a meter provider that exports over OTLP when telemetry and metrics are
+// enabled, and serves a Prometheus endpoint when an address is configured. The returned function
+// flushes the remaining metrics and stops the endpoint.
+func SetupMetrics(ctx context.Context, config config.TelemetryConfig) (func(), error) {
+	otlpEnabled := config.Enabled && config.Exporter != "" && config.Metrics
+	if !otlpEnabled && config.PrometheusAddr == "" {
+		slog.InfoContext(ctx, "OpenTelemetry metrics are disabled")
+		return func() {}, nil
+	}
+
+	otelResource, err := newResource(ctx, config)
+	if err != nil {
+		return func() {}, err
+	}
+	opts := []sdkmetric.Option{sdkmetric.WithResource(otelResource)}
+
+	if otlpEnabled {
+		exporterOpts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(config.Endpoint),
+			otlpmetricgrpc.WithHeaders(config.Headers),
+			otlpmetricgrpc.WithCompressor(gzip.Name),
+		}
+		if config.Insecure {
+			exporterOpts = append(exporterOpts, otlpmetricgrpc.WithInsecure())
+		}
+
+		exporter, err := otlpmetricgrpc.New(ctx, exporterOpts...)
+		if err != nil {
+			return func() {}, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+		}
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)))
+		slog.InfoContext(ctx, "OpenTelemetry metrics enabled", "exporter", config.Exporter)
+	}
+
+	var server *http.Server
+	if config.PrometheusAddr != "" {
+		exporter, err := prometheus.New()
+		if err != nil {
+			return func() {}, fmt.Errorf("failed to create Prometheus exporter: %w", err)
+		}
+		opts = append(opts, sdkmetric.WithReader(exporter))
+
+		// Listen before returning so a taken port fails the command instead of silently dropping metrics
+		listener, err := net.Listen("tcp", config.PrometheusAddr)
+		if err != nil {
+			return func() {}, fmt.Errorf("failed to listen on %s for Prometheus metrics: %w", config.PrometheusAddr, err)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.ErrorContext(ctx, "Prometheus metrics endpoint stopped", "error", err)
+			}
+		}()
+		slog.InfoContext(ctx, "Serving Prometheus metrics", "addr", listener.Addr().String(), "path", "/metrics")
+	}
+
+	meterProvider := sdkmetric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(meterProvider)
+
+	shutdown := func() {
+		if err := meterProvider.Shutdown(ctx); err != nil {
+			slog.Error("Failed to shutdown meter provider", "error", err)
+		}
+		if server != nil {
+			if err := server.Shutdown(ctx); err != nil {
+				slog.Error("Failed to shutdown Prometheus metrics endpoint", "error", err)
+			}
+		}
+	}
+
+	return shutdown, nil
+}
@@ -1,4 +1,4 @@
-// Package telemetry sets up OpenTelemetry tracing and logging for the application.
+// Package telemetry sets up OpenTelemetry tracing, logging and metrics for the application.
 package telemetry
 
 import (
@@ -22,9 +22,10 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/encoding/gzip"
 
@@ -55,28 +56,9 @@ func SetupTelemetry(ctx context.Context, config config.TelemetryConfig) (context
 
 	slog.InfoContext(ctx, "OpenTelemetry tracing enabled", "exporter", config.Exporter)
 
-	// Use service name from config or default
-	serviceName := DefaultServiceName
-	if config.ServiceName != "" {
-		slog.InfoContext(ctx, "Using service name from config", "service_name", config.ServiceName)
-		serviceName = config.ServiceName
-	}
-
-	otelResource, err := resource.New(ctx,
-		resource.WithOS(),
-		resource.WithHost(),
-		resource.WithProcess(),
-		resource.WithSchemaURL(semconv.SchemaURL),
-		resource.WithAttributes(
-			semconv.ServiceName(serviceName),
-			semconv.ServiceVersion("2.0.0"),
-			semconv.TelemetrySDKName("opentelemetry"),
-			semconv.TelemetrySDKLanguageGo,
-			semconv.TelemetrySDKVersion(sdk.Version()),
-		),
-	)
+	otelResource, err := newResource(ctx, config)
 	if err != nil {
-		return ctx, func() {}, fmt.Errorf("failed to create resource: %w", err)
+		return ctx, func() {}, err
 	}
 
 	// Create OTLP grpc exporter using config
@@ -172,6 +154,34 @@ func SetupTelemetry(ctx context.Context, config config.TelemetryConfig) (context
 	return ctx, shutdown, nil
 }
 
+// newResource describes this process for all exporters
+func newResource(ctx context.Context, config config.TelemetryConfig) (*resource.Resource, error) {
+	// Use service name from config or default
+	serviceName := DefaultServiceName
+	if config.ServiceName != "" {
+		slog.InfoContext(ctx, "Using service name from config", "service_name", config.ServiceName)
+		serviceName = config.ServiceName
+	}
+
+	otelResource, err := resource.New(ctx,
+		resource.WithOS(),
+		resource.WithHost(),
+		resource.WithProcess(),
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion("2.0.0"),
+			semconv.TelemetrySDKName("opentelemetry"),
+			semconv.TelemetrySDKLanguageGo,
+			semconv.TelemetrySDKVersion(sdk.Version()),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+	return otelResource, nil
+}
+
 // Tracer is intended to be the primary way of creating a tracer in this application,
 // It will attempt to set the name of the tracer to that of the function calling this method.
 // Please ensure that your methods that call this are nicely named!
@@ -257,7 +267,7 @@ func LinkedSpanStart(ctx context.Context, name string, opts ...trace.SpanStartOp
 	return Tracer().Start(ctx, name, allOpts...)
 }
 
-// TestOTLPConnection tests connectivity to the OTLP endpoint by flushing traces and logs.
+// TestOTLPConnection tests connectivity to the OTLP endpoint by flushing traces, logs and metrics.
 // Call this after SetupTelemetry to verify the endpoint is reachable.
 func TestOTLPConnection(ctx context.Context, cfg config.TelemetryConfig) error {
 	// Test trace connectivity
@@ -282,5 +292,17 @@ func TestOTLPConnection(ctx context.Context, cfg config.TelemetryConfig) error {
 		slog.InfoContext(ctx, "OTLP log connection test successful")
 	}
 
+	// Test metric connectivity if metrics are enabled
+	if cfg.Metrics {
+		mp, ok := otel.GetMeterProvider().(*sdkmetric.MeterProvider)
+		if !ok {
+			return fmt.Errorf("meter provider is not configured")
+		}
+		if err := mp.ForceFlush(ctx); err != nil {
+			return fmt.Errorf("failed to flush metrics to OTLP endpoint: %w", err)
+		}
+		slog.InfoContext(ctx, "OTLP metric connection test successful")
+	}
+
 	return nil
 }
@@ -0,0 +1,183 @@
+package tofu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/opentofu/registry-ui/pkg/telemetry"
+)
+
+// ProviderSchemas is the output of `tofu providers schema -json`
+type ProviderSchemas struct {
+	FormatVersion string                     `json:"format_version"`
+	Schemas       map[string]*ProviderSchema `json:"provider_schemas,omitempty"`
+}
+
+// ProviderSchema is the schema of a single provider
+type ProviderSchema struct {
+	Provider                 *Schema                       `json:"provider,omitempty"`
+	ResourceSchemas          map[string]*Schema            `json:"resource_schemas,omitempty"`
+	DataSourceSchemas        map[string]*Schema            `json:"data_source_schemas,omitempty"`
+	EphemeralResourceSchemas map[string]*Schema            `json:"ephemeral_resource_schemas,omitempty"`
+	Functions                map[string]*FunctionSignature `json:"functions,omitempty"`
+}
+
+// Schema is the schema of a provider configuration, resource, data source or ephemeral resource
+type Schema struct {
+	Version uint64       `json:"version"`
+	Block   *SchemaBlock `json:"block,omitempty"`
+}
+
+type SchemaBlock struct {
+	Attributes      map[string]*SchemaAttribute `json:"attributes,omitempty"`
+	BlockTypes      map[string]*SchemaBlockType `json:"block_types,omitempty"`
+	Description     string                      `json:"description,omitempty"`
+	DescriptionKind string                      `json:"description_kind,omitempty"`
+	Deprecated      bool                        `json:"deprecated,omitempty"`
+}
+
+type SchemaBlockType struct {
+	NestingMode string       `json:"nesting_mode,omitempty"`
+	Block       *SchemaBlock `json:"block,omitempty"`
+	MinItems    uint64       `json:"min_items,omitempty"`
+	MaxItems    uint64       `json:"max_items,omitempty"`
+}
+
+type SchemaAttribute struct {
+	// Type is the cty type of the attribute in its JSON representation, unset when NestedType is used
+	Type            json.RawMessage            `json:"type,omitempty"`
+	NestedType      *SchemaNestedAttributeType `json:"nested_type,omitempty"`
+	Description     string                     `json:"description,omitempty"`
+	DescriptionKind string                     `json:"description_kind,omitempty"`
+	Deprecated      bool                       `json:"deprecated,omitempty"`
+	Required        bool                       `json:"required,omitempty"`
+	Optional        bool                       `json:"optional,omitempty"`
+	Computed        bool                       `json:"computed,omitempty"`
+	Sensitive       bool                       `json:"sensitive,omitempty"`
+}
+
+type SchemaNestedAttributeType struct {
+	Attributes  map[string]*SchemaAttribute `json:"attributes,omitempty"`
+	NestingMode string                      `json:"nesting_mode,omitempty"`
+	MinItems    uint64                      `json:"min_items,omitempty"`
+	MaxItems    uint64                      `json:"max_items,omitempty"`
+}
+
+// FunctionSignature is the signature of a provider defined function
+type FunctionSignature struct {
+	Description        string               `json:"description,omitempty"`
+	Summary            string               `json:"summary,omitempty"`
+	DeprecationMessage string               `json:"deprecation_message,omitempty"`
+	ReturnType         json.RawMessage      `json:"return_type"`
+	Parameters         []*FunctionParameter `json:"parameters,omitempty"`
+	VariadicParameter  *FunctionParameter   `json:"variadic_parameter,omitempty"`
+}
+
+type FunctionParameter struct {
+	Name        string          `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	IsNullable  bool            `json:"is_nullable,omitempty"`
+	Type        json.RawMessage `json:"type"`
+}
+
+// ProvidersSchema installs a single provider from pluginDir into an empty configuration in workDir and
+// executes tofu providers schema -json on it. pluginDir must contain the provider package in the packed layout
+// (HOSTNAME/NAMESPACE/TYPE/terraform-provider-TYPE_VERSION_OS_ARCH.zip).
+// Returns (schema, stderr, error) — stderr is returned for callers to store if needed.
+func ProvidersSchema(ctx context.Context, workDir, pluginDir, source, version string) (*ProviderSchema, string, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "tofu.providers_schema")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("provider.source", source),
+		attribute.String("provider.version", version),
+	)
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, "", fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	binary := path.Join(cwd, BinaryName)
+
+	config := fmt.Sprintf(`terraform {
+  required_providers {
+    provider = {
+      source  = %q
+      version = %q
+    }
+  }
+}
+`, source, version)
+	if err := os.WriteFile(filepath.Join(workDir, "main.tf"), []byte(config), 0o644); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, "", fmt.Errorf("failed to write configuration: %w", err)
+	}
+
+	// Install the provider only from the plugin dir so nothing is fetched from the network
+	initCmd := exec.CommandContext(ctx, binary, "init", "-backend=false", "-input=false", "-no-color", "-plugin-dir="+pluginDir)
+	initCmd.Dir = workDir
+
+	slog.DebugContext(ctx, "Executing tofu init", "cmd", initCmd.String(), "dir", workDir)
+
+	var initStderr strings.Builder
+	initCmd.Stderr = &initStderr
+	if err := initCmd.Run(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, initStderr.String(), fmt.Errorf("tofu init failed: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, binary, "providers", "schema", "-json")
+	cmd.Dir = workDir
+
+	slog.DebugContext(ctx, "Executing tofu providers schema", "cmd", cmd.String(), "dir", workDir)
+
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	stderrStr := stderr.String()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, stderrStr, fmt.Errorf("tofu providers schema failed: %w", err)
+	}
+
+	output := stdout.String()
+	span.SetAttributes(
+		attribute.Int("tofu.output_size", len(output)),
+	)
+
+	var schemas ProviderSchemas
+	if err := json.Unmarshal([]byte(output), &schemas); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, stderrStr, fmt.Errorf("failed to parse tofu JSON output: %w", err)
+	}
+
+	// The output is keyed by the fully qualified source address
+	for address, schema := range schemas.Schemas {
+		if strings.EqualFold(address, source) {
+			return schema, stderrStr, nil
+		}
+	}
+
+	err = fmt.Errorf("schema for %s not found in tofu output", source)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return nil, stderrStr, err
+}
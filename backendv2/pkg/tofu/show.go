@@ -3,12 +3,14 @@ package tofu
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path"
 	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -16,6 +18,9 @@ import (
 	"github.com/opentofu/registry-ui/pkg/telemetry"
 )
 
+// ErrInvalidModule is returned by Show when tofu ran but could not load the module, as opposed to tofu failing to run
+var ErrInvalidModule = errors.New("tofu could not load the module")
+
 // Show executes tofu show -json -module=DIR and returns the parsed Config.
 // Returns (config, stderr, error) — stderr is returned for callers to store in SchemaError if needed.
 func Show(ctx context.Context, moduleDir string) (*Config, string, error) {
@@ -43,7 +48,9 @@ func Show(ctx context.Context, moduleDir string) (*Config, string, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
+	start := time.Now()
 	err = cmd.Run()
+	telemetry.RecordTofuShow(ctx, start, err)
 
 	// Return stderr to caller for storage in SchemaError (don't log it to avoid large traces)
 	stderrStr := stderr.String()
@@ -51,6 +58,11 @@ func Show(ctx context.Context, moduleDir string) (*Config, string, error) {
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		// Only an exit code from tofu itself means the module is invalid, not a kill by a signal or the context
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && ctx.Err() == nil {
+			return nil, stderrStr, fmt.Errorf("%w: tofu show failed: %w", ErrInvalidModule, err)
+		}
 		return nil, stderrStr, fmt.Errorf("tofu show failed: %w", err)
 	}
 
@@ -64,7 +76,7 @@ func Show(ctx context.Context, moduleDir string) (*Config, string, error) {
 	if err := json.Unmarshal([]byte(output), &config); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, stderrStr, fmt.Errorf("failed to parse tofu JSON output: %w", err)
+		return nil, stderrStr, fmt.Errorf("%w: failed to parse tofu JSON output: %w", ErrInvalidModule, err)
 	}
 
 	slog.DebugContext(ctx, "Successfully executed tofu show",
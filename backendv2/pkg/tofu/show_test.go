@@ -0,0 +1,68 @@
+package tofu
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShowErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string // contents of the fake tofu binary, none when empty
+		cancel      bool
+		wantInvalid bool
+	}{
+		{
+			name:        "module rejected",
+			script:      "#!/bin/sh\necho 'Error: Unsupported block type' >&2\nexit 1\n",
+			wantInvalid: true,
+		},
+		{
+			name:        "unparseable output",
+			script:      "#!/bin/sh\necho 'not json'\n",
+			wantInvalid: true,
+		},
+		{
+			name:   "binary missing",
+			script: "",
+		},
+		{
+			name:   "context cancelled",
+			script: "#!/bin/sh\nexit 1\n",
+			cancel: true,
+		},
+		{
+			name:   "killed",
+			script: "#!/bin/sh\nkill -9 $$\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cwd := t.TempDir()
+			t.Chdir(cwd)
+			if tt.script != "" {
+				if err := os.WriteFile(filepath.Join(cwd, BinaryName), []byte(tt.script), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			_, _, err := Show(ctx, t.TempDir())
+			if err == nil {
+				t.Fatal("Show() error = nil, want an error")
+			}
+			if got := errors.Is(err, ErrInvalidModule); got != tt.wantInvalid {
+				t.Errorf("errors.Is(err, ErrInvalidModule) = %v, want %v (err: %v)", got, tt.wantInvalid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+// Package vcs abstracts the source code hosts that provider and module repositories live on. A Host derives
+// clone URLs and web links to files and directories for the repositories on it.
+package vcs
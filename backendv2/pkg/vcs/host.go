@@ -0,0 +1,117 @@
+package vcs
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+)
+
+// Repo identifies a repository on a host
+type Repo struct {
+	Owner string
+	Name  string
+}
+
+func (r Repo) String() string {
+	return r.Owner + "/" + r.Name
+}
+
+// ProviderRepo returns the repository of a provider following the registry naming convention
+func ProviderRepo(namespace, name string) Repo {
+	return Repo{Owner: namespace, Name: "terraform-provider-" + name}
+}
+
+// ModuleRepo returns the repository of a module following the registry naming convention
+func ModuleRepo(namespace, name, target string) Repo {
+	return Repo{Owner: namespace, Name: fmt.Sprintf("terraform-%s-%s", target, name)}
+}
+
+// Host derives URLs for the repositories on a source code host
+type Host interface {
+	// Type returns the configured host type, one of the config.VCSType* values
+	Type() string
+	// RepoURL returns the web URL of a repository, which is also used as its identity in the registry
+	RepoURL(repo Repo) string
+	// CloneURL returns the URL to clone a repository from
+	CloneURL(repo Repo) string
+	// BlobURL returns the web URL of a file at a ref, or "" if the host has no web interface
+	BlobURL(repo Repo, ref, filePath string) string
+	// TreeURL returns the web URL of a directory at a ref, or "" if the host has no web interface
+	TreeURL(repo Repo, ref, dirPath string) string
+	// ParseURL extracts the repository from a web or clone URL on this host
+	ParseURL(rawURL string) (Repo, bool)
+}
+
+// New returns the host for the configured type
+func New(cfg config.VCSConfig) (Host, error) {
+	switch cfg.Type {
+	case "", config.VCSTypeGitHub:
+		return &GitHub{base: newBase(cfg.BaseURL, "https://github.com")}, nil
+	case config.VCSTypeGitLab:
+		return &GitLab{base: newBase(cfg.BaseURL, "https://gitlab.com")}, nil
+	case config.VCSTypeBitbucket:
+		return &Bitbucket{base: newBase(cfg.BaseURL, "https://bitbucket.org")}, nil
+	case config.VCSTypeGit:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("a base URL is required for plain git hosts")
+		}
+		return &Git{base: newBase(cfg.BaseURL, "")}, nil
+	default:
+		return nil, fmt.Errorf("unsupported VCS type %q", cfg.Type)
+	}
+}
+
+// Default returns the public GitHub host, which all repositories in the public registry live on
+func Default() Host {
+	return &GitHub{base: newBase("", "https://github.com")}
+}
+
+// base implements the parts that are the same for every host
+type base struct {
+	url string
+}
+
+func newBase(configured, fallback string) base {
+	if configured == "" {
+		configured = fallback
+	}
+	return base{url: strings.TrimSuffix(configured, "/")}
+}
+
+func (b base) RepoURL(repo Repo) string {
+	return fmt.Sprintf("%s/%s/%s", b.url, repo.Owner, repo.Name)
+}
+
+func (b base) ParseURL(rawURL string) (Repo, bool) {
+	rawURL = strings.TrimSuffix(rawURL, ".git")
+
+	var repoPath string
+	if strings.HasPrefix(rawURL, b.url+"/") {
+		repoPath = strings.TrimPrefix(rawURL, b.url+"/")
+	} else if u, err := url.Parse(b.url); err == nil {
+		// SSH clone URLs, e.g. git@github.com:owner/repo.git
+		after, ok := strings.CutPrefix(rawURL, "git@"+u.Host+":")
+		if !ok {
+			return Repo{}, false
+		}
+		repoPath = after
+	}
+
+	parts := strings.Split(repoPath, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return Repo{}, false
+	}
+	return Repo{Owner: parts[0], Name: parts[1]}, true
+}
+
+func joinURL(parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p = strings.Trim(p, "/"); p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, "/")
+}
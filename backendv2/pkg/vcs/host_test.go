@@ -0,0 +1,157 @@
+package vcs
+
+import (
+	"testing"
+
+	"github.com/opentofu/registry-ui/pkg/config"
+)
+
+func TestHostURLs(t *testing.T) {
+	repo := ModuleRepo("example", "vpc", "aws")
+
+	tests := []struct {
+		name      string
+		cfg       config.VCSConfig
+		wantRepo  string
+		wantClone string
+		wantBlob  string
+		wantTree  string
+	}{
+		{
+			name:      "github default",
+			cfg:       config.VCSConfig{},
+			wantRepo:  "https://github.com/example/terraform-aws-vpc",
+			wantClone: "https://github.com/example/terraform-aws-vpc",
+			wantBlob:  "https://github.com/example/terraform-aws-vpc/blob/v1.0.0/modules/a/README.md",
+			wantTree:  "https://github.com/example/terraform-aws-vpc/tree/v1.0.0",
+		},
+		{
+			name:      "self-managed gitlab",
+			cfg:       config.VCSConfig{Type: config.VCSTypeGitLab, BaseURL: "https://gitlab.example.com/"},
+			wantRepo:  "https://gitlab.example.com/example/terraform-aws-vpc",
+			wantClone: "https://gitlab.example.com/example/terraform-aws-vpc.git",
+			wantBlob:  "https://gitlab.example.com/example/terraform-aws-vpc/-/blob/v1.0.0/modules/a/README.md",
+			wantTree:  "https://gitlab.example.com/example/terraform-aws-vpc/-/tree/v1.0.0",
+		},
+		{
+			name:      "bitbucket",
+			cfg:       config.VCSConfig{Type: config.VCSTypeBitbucket},
+			wantRepo:  "https://bitbucket.org/example/terraform-aws-vpc",
+			wantClone: "https://bitbucket.org/example/terraform-aws-vpc.git",
+			wantBlob:  "https://bitbucket.org/example/terraform-aws-vpc/src/v1.0.0/modules/a/README.md",
+			wantTree:  "https://bitbucket.org/example/terraform-aws-vpc/src/v1.0.0",
+		},
+		{
+			name:      "plain git",
+			cfg:       config.VCSConfig{Type: config.VCSTypeGit, BaseURL: "https://git.example.com"},
+			wantRepo:  "https://git.example.com/example/terraform-aws-vpc",
+			wantClone: "https://git.example.com/example/terraform-aws-vpc.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, err := New(tt.cfg)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if got := host.RepoURL(repo); got != tt.wantRepo {
+				t.Errorf("RepoURL() = %q, want %q", got, tt.wantRepo)
+			}
+			if got := host.CloneURL(repo); got != tt.wantClone {
+				t.Errorf("CloneURL() = %q, want %q", got, tt.wantClone)
+			}
+			if got := host.BlobURL(repo, "v1.0.0", "modules/a/README.md"); got != tt.wantBlob {
+				t.Errorf("BlobURL() = %q, want %q", got, tt.wantBlob)
+			}
+			if got := host.TreeURL(repo, "v1.0.0", ""); got != tt.wantTree {
+				t.Errorf("TreeURL() = %q, want %q", got, tt.wantTree)
+			}
+
+			for _, rawURL := range []string{tt.wantRepo, tt.wantClone, tt.wantRepo + "/tree/main"} {
+				parsed, ok := host.ParseURL(rawURL)
+				if !ok || parsed != repo {
+					t.Errorf("ParseURL(%q) = %v, %v, want %v", rawURL, parsed, ok, repo)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSSHURL(t *testing.T) {
+	got, ok := Default().ParseURL("git@github.com:example/terraform-aws-vpc.git")
+	if want := ModuleRepo("example", "vpc", "aws"); !ok || got != want {
+		t.Errorf("ParseURL() = %v, %v, want %v", got, ok, want)
+	}
+}
+
+func TestRegistryRepos(t *testing.T) {
+	tests := []struct {
+		got  Repo
+		want string
+	}{
+		{ModuleRepo("terraform-aws-modules", "vpc", "aws"), "terraform-aws-modules/terraform-aws-vpc"},
+		{ModuleRepo("Azure", "aks", "azurerm"), "Azure/terraform-azurerm-aks"},
+		{ProviderRepo("hashicorp", "aws"), "hashicorp/terraform-provider-aws"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.got.String(); got != tt.want {
+				t.Errorf("repository = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexLinks(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          config.VCSConfig
+		wantProvider string
+		wantFork     string
+	}{
+		{
+			name:         "github",
+			cfg:          config.VCSConfig{Type: config.VCSTypeGitHub},
+			wantProvider: "https://github.com/example/terraform-provider-aws",
+			wantFork:     "https://github.com/upstream/terraform-provider-aws",
+		},
+		{
+			name:         "gitlab",
+			cfg:          config.VCSConfig{Type: config.VCSTypeGitLab},
+			wantProvider: "https://gitlab.com/example/terraform-provider-aws",
+			wantFork:     "https://gitlab.com/upstream/terraform-provider-aws",
+		},
+		{
+			name:         "bitbucket server",
+			cfg:          config.VCSConfig{Type: config.VCSTypeBitbucket, BaseURL: "https://bitbucket.example.com/scm"},
+			wantProvider: "https://bitbucket.example.com/scm/example/terraform-provider-aws",
+			wantFork:     "https://bitbucket.example.com/scm/upstream/terraform-provider-aws",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, err := New(tt.cfg)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if got := host.RepoURL(ProviderRepo("example", "aws")); got != tt.wantProvider {
+				t.Errorf("provider link = %q, want %q", got, tt.wantProvider)
+			}
+			if got := host.RepoURL(Repo{Owner: "upstream", Name: "terraform-provider-aws"}); got != tt.wantFork {
+				t.Errorf("fork link = %q, want %q", got, tt.wantFork)
+			}
+
+			// Links to other hosts don't belong to this one
+			for _, other := range []string{"https://example.org/example/terraform-provider-aws", "git@example.org:example/repo.git"} {
+				if repo, ok := host.ParseURL(other); ok {
+					t.Errorf("ParseURL(%q) = %v, want no match", other, repo)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package vcs
+
+import (
+	"github.com/opentofu/registry-ui/pkg/config"
+)
+
+// GitHub links to github.com or a GitHub Enterprise instance
+type GitHub struct {
+	base
+}
+
+func (h *GitHub) Type() string { return config.VCSTypeGitHub }
+
+// CloneURL returns the repository URL, which go-git clones from directly
+func (h *GitHub) CloneURL(repo Repo) string {
+	return h.RepoURL(repo)
+}
+
+func (h *GitHub) BlobURL(repo Repo, ref, filePath string) string {
+	return joinURL(h.RepoURL(repo), "blob", ref, filePath)
+}
+
+func (h *GitHub) TreeURL(repo Repo, ref, dirPath string) string {
+	return joinURL(h.RepoURL(repo), "tree", ref, dirPath)
+}
+
+// GitLab links to gitlab.com or a self-managed GitLab instance
+type GitLab struct {
+	base
+}
+
+func (h *GitLab) Type() string { return config.VCSTypeGitLab }
+
+func (h *GitLab) CloneURL(repo Repo) string {
+	return h.RepoURL(repo) + ".git"
+}
+
+func (h *GitLab) BlobURL(repo Repo, ref, filePath string) string {
+	return joinURL(h.RepoURL(repo), "-", "blob", ref, filePath)
+}
+
+func (h *GitLab) TreeURL(repo Repo, ref, dirPath string) string {
+	return joinURL(h.RepoURL(repo), "-", "tree", ref, dirPath)
+}
+
+// Bitbucket links to bitbucket.org. Bitbucket uses the same src URL for files and directories.
+type Bitbucket struct {
+	base
+}
+
+func (h *Bitbucket) Type() string { return config.VCSTypeBitbucket }
+
+func (h *Bitbucket) CloneURL(repo Repo) string {
+	return h.RepoURL(repo) + ".git"
+}
+
+func (h *Bitbucket) BlobURL(repo Repo, ref, filePath string) string {
+	return joinURL(h.RepoURL(repo), "src", ref, filePath)
+}
+
+func (h *Bitbucket) TreeURL(repo Repo, ref, dirPath string) string {
+	return joinURL(h.RepoURL(repo), "src", ref, dirPath)
+}
+
+// Git is a plain git server without a web interface, only cloning is supported
+type Git struct {
+	base
+}
+
+func (h *Git) Type() string { return config.VCSTypeGit }
+
+func (h *Git) CloneURL(repo Repo) string {
+	return h.RepoURL(repo) + ".git"
+}
+
+func (h *Git) BlobURL(Repo, string, string) string { return "" }
+
+func (h *Git) TreeURL(Repo, string, string) string { return "" }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// databaseSource reads the items that generate-search-index stored in the search_index_items table of a backendv2
+// database. These are the items it publishes as search.ndjson, so the import is the same as from the feed.
+type databaseSource struct {
+	db          *sql.DB
+	rows        *sql.Rows
+	lastUpdated time.Time
+}
+
+func newDatabaseSource(connString string) (*databaseSource, error) {
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like the header of search.ndjson, the newest change of any item
+	var lastUpdated sql.NullTime
+	err = db.QueryRow("SELECT MAX(GREATEST(last_updated, deleted_at)) FROM search_index_items").Scan(&lastUpdated)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to query search_index_items, run generate-search-index first: %w", err)
+	}
+
+	// Deletions come first so that an item which was removed and re-added ends up present, like in search.ndjson
+	rows, err := db.Query(`
+		SELECT id, item, last_updated, deleted_at
+		FROM search_index_items
+		ORDER BY deleted_at IS NULL, id`)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to query search_index_items: %w", err)
+	}
+
+	return &databaseSource{db: db, rows: rows, lastUpdated: lastUpdated.Time}, nil
+}
+
+func (s *databaseSource) LastUpdated() time.Time {
+	return s.lastUpdated
+}
+
+func (s *databaseSource) Next(batchSize int) ([]SearchIndexItem, error) {
+	batchItems := make([]SearchIndexItem, 0, batchSize)
+	for len(batchItems) < batchSize && s.rows.Next() {
+		var id string
+		var contents []byte
+		var lastUpdated time.Time
+		var deletedAt sql.NullTime
+		if err := s.rows.Scan(&id, &contents, &lastUpdated, &deletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan search index item: %w", err)
+		}
+
+		item, err := storedItem(id, contents, lastUpdated, deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		batchItems = append(batchItems, item)
+	}
+	if err := s.rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search index items: %w", err)
+	}
+	return batchItems, nil
+}
+
+func (s *databaseSource) Close() error {
+	_ = s.rows.Close()
+	return s.db.Close()
+}
+
+// storedItem converts a row of search_index_items to the line search.ndjson has for it. The item column holds the
+// addition without its last_updated, which is kept in its own column.
+func storedItem(id string, contents []byte, lastUpdated time.Time, deletedAt sql.NullTime) (SearchIndexItem, error) {
+	var item SearchIndexItem
+	if deletedAt.Valid {
+		item.Type = "delete"
+		item.Deletion.ID = id
+		item.Deletion.DeletedAt = deletedAt.Time
+		return item, nil
+	}
+
+	item.Type = "add"
+	if err := json.Unmarshal(contents, &item.Addition); err != nil {
+		return item, fmt.Errorf("failed to unmarshal search index item %s: %w", id, err)
+	}
+	item.Addition.LastUpdated = lastUpdated
+	return item, nil
+}
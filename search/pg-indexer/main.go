@@ -2,13 +2,13 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -16,10 +16,22 @@ import (
 	"github.com/lib/pq"
 )
 
+const defaultSearchIndexURL = "https://api.opentofu.org/registry/docs/search.ndjson"
+
 func main() {
 	connString := os.Getenv("PG_CONNECTION_STRING")
 	var batchSize int
+	var url, file, bucket, bucketKey, bucketEndpoint, bucketRegion string
+	var fromDB, incremental bool
 	flag.IntVar(&batchSize, "batch-size", 1000, "Batch size for inserts")
+	flag.StringVar(&url, "url", defaultSearchIndexURL, "URL of the search.ndjson to import")
+	flag.StringVar(&file, "file", "", "Import a local search.ndjson file instead of downloading it")
+	flag.StringVar(&bucket, "bucket", "", "Import search.ndjson from this S3 compatible bucket instead of downloading it, credentials are read from the AWS_* environment variables")
+	flag.StringVar(&bucketKey, "bucket-key", "search.ndjson", "Key of the search index in the bucket")
+	flag.StringVar(&bucketEndpoint, "bucket-endpoint", "", "S3 compatible endpoint URL, leave empty for AWS S3")
+	flag.StringVar(&bucketRegion, "bucket-region", "auto", "Region of the bucket")
+	flag.BoolVar(&fromDB, "from-db", false, "Import the search index items generate-search-index stored in the backendv2 database set in BACKEND_PG_CONNECTION_STRING")
+	flag.BoolVar(&incremental, "incremental", false, "Only import the items updated since the newest imported one, deletions are always applied")
 	flag.Parse()
 
 	if connString == "" {
@@ -30,11 +42,20 @@ func main() {
 		log.Fatal("The batch size must be greater than 0.")
 	}
 
-	body, err := downloadSearchMetaIndex()
-	if err != nil {
-		log.Fatal(err)
+	sources := 0
+	for _, set := range []bool{file != "", bucket != "", fromDB} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		log.Fatal("Only one of -file, -bucket and -from-db can be used.")
+	}
+
+	backendConnString := os.Getenv("BACKEND_PG_CONNECTION_STRING")
+	if fromDB && backendConnString == "" {
+		log.Fatal("Please set the BACKEND_PG_CONNECTION_STRING environment variable when using -from-db.")
 	}
-	defer func() { _ = body.Close() }()
 
 	db, err := sql.Open("postgres", connString)
 	if err != nil {
@@ -54,27 +75,42 @@ func main() {
 		os.Exit(0)
 	}
 
-	scanner := bufio.NewScanner(body)
-	if !scanner.Scan() {
-		log.Fatal("no data in search index")
+	// Only items changed after the newest one we already have need to be applied
+	var since time.Time
+	if incremental {
+		since, err = getLastImportedUpdate(db)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	header, err := readSearchHeader(scanner.Bytes())
+	var source itemSource
+	switch {
+	case fromDB:
+		source, err = newDatabaseSource(backendConnString)
+	case file != "":
+		source, err = openNDJSONSource(openFile(file))
+	case bucket != "":
+		source, err = openNDJSONSource(openBucketKey(context.Background(), bucket, bucketKey, bucketEndpoint, bucketRegion))
+	default:
+		source, err = openNDJSONSource(openURL(url))
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer func() { _ = source.Close() }()
 
 	shouldRun := false
 	if mostRecentJob == nil {
 		shouldRun = true
-	} else if mostRecentJob.CreatedAt.Time.Before(header.Header.LastUpdated) {
+	} else if mostRecentJob.CreatedAt.Time.Before(source.LastUpdated()) {
 		shouldRun = true
 	}
 
 	if !shouldRun {
 		log.Println("No new data to import, exiting")
 		log.Printf("\tLast import completed at %s\n", mostRecentJob.CreatedAt.Time.Format(time.RFC3339Nano))
-		log.Printf("\tLast data update was at %s\n", header.Header.LastUpdated)
+		log.Printf("\tLast data update was at %s\n", source.LastUpdated())
 		os.Exit(0)
 	}
 
@@ -89,8 +125,8 @@ func main() {
 		log.Fatal(err)
 	}
 
-	handled := 0
-	batchItems, err := readItems(scanner, batchSize)
+	handled, unchanged := 0, 0
+	batchItems, err := source.Next(batchSize)
 	if err != nil {
 		_ = tx.Rollback()
 		log.Fatal(err)
@@ -101,6 +137,11 @@ func main() {
 		toDelete := make([]SearchIndexItem, 0, batchSize)
 
 		for _, item := range batchItems {
+			if !changedSince(item, since) {
+				unchanged++
+				continue
+			}
+
 			switch item.Type {
 			case "add":
 				toInsert = append(toInsert, item)
@@ -123,7 +164,7 @@ func main() {
 			log.Fatal(err)
 		}
 
-		batchItems, err = readItems(scanner, batchSize)
+		batchItems, err = source.Next(batchSize)
 		if err != nil {
 			_ = tx.Rollback()
 			log.Fatal(err)
@@ -135,7 +176,7 @@ func main() {
 	if err := tx.Commit(); err != nil {
 		log.Fatalf("Failed to commit transaction: %v", err)
 	}
-	log.Printf("Complete: Handled %d items\n", handled)
+	log.Printf("Complete: Handled %d items, skipped %d unchanged items\n", handled, unchanged)
 
 	err = completeJob(db, jobID)
 	if err != nil {
@@ -231,19 +272,31 @@ func completeJob(db *sql.DB, id int) error {
 	return err
 }
 
-func downloadSearchMetaIndex() (io.ReadCloser, error) {
-	// Get the data
-	resp, err := http.Get("https://api.opentofu.org/registry/docs/search.ndjson")
+func openNDJSONSource(body io.ReadCloser, err error) (itemSource, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newNDJSONSource(body)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		defer func() { _ = resp.Body.Close() }()
-		return nil, fmt.Errorf("failed to download search index: %s", resp.Status)
+// getLastImportedUpdate returns the newest last_updated of the imported entities. Every feed item updated after it
+// has not been imported yet.
+func getLastImportedUpdate(db *sql.DB) (time.Time, error) {
+	var lastUpdated sql.NullTime
+	if err := db.QueryRow("SELECT MAX(last_updated) FROM entities").Scan(&lastUpdated); err != nil {
+		return time.Time{}, err
 	}
+	return lastUpdated.Time, nil
+}
 
-	return resp.Body, nil
+// changedSince reports whether an item has to be applied when everything up to since was already imported.
+// Deletions are always applied: since only tells which additions were imported, and deleting an entity that is
+// already gone does nothing.
+func changedSince(item SearchIndexItem, since time.Time) bool {
+	if since.IsZero() || item.Type != "add" {
+		return true
+	}
+	return item.Addition.LastUpdated.After(since)
 }
 
 func deleteItems(tx *sql.Tx, items []SearchIndexItem) error {
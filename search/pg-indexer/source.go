@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// itemSource provides the search index items to import, either from a search.ndjson feed or straight from the
+// backendv2 database.
+type itemSource interface {
+	// LastUpdated returns when the data in the source last changed
+	LastUpdated() time.Time
+	// Next returns up to batchSize items, an empty slice means the source is exhausted
+	Next(batchSize int) ([]SearchIndexItem, error)
+	Close() error
+}
+
+// ndjsonSource reads items from a search.ndjson feed
+type ndjsonSource struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	header  *SearchHeader
+}
+
+func newNDJSONSource(body io.ReadCloser) (*ndjsonSource, error) {
+	scanner := bufio.NewScanner(body)
+	if !scanner.Scan() {
+		_ = body.Close()
+		return nil, fmt.Errorf("no data in search index")
+	}
+
+	header, err := readSearchHeader(scanner.Bytes())
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+
+	return &ndjsonSource{body: body, scanner: scanner, header: header}, nil
+}
+
+func (s *ndjsonSource) LastUpdated() time.Time {
+	return s.header.Header.LastUpdated
+}
+
+func (s *ndjsonSource) Next(batchSize int) ([]SearchIndexItem, error) {
+	return readItems(s.scanner, batchSize)
+}
+
+func (s *ndjsonSource) Close() error {
+	return s.body.Close()
+}
+
+func openURL(url string) (io.ReadCloser, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer func() { _ = resp.Body.Close() }()
+		return nil, fmt.Errorf("failed to download search index: %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+func openFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open search index: %w", err)
+	}
+	return f, nil
+}
+
+// openBucketKey reads a search index from an S3 compatible bucket. Credentials are taken from the standard AWS
+// environment variables.
+func openBucketKey(ctx context.Context, bucket, key, endpoint, region string) (io.ReadCloser, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get s3://%s/%s: %w", bucket, key, err)
+	}
+
+	return out.Body, nil
+}
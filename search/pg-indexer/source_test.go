@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testFeed = `{"type":"header","header":{"last_updated":"2026-05-01T10:00:00Z"}}
+{"type":"delete","deletion":{"id":"providers/example/old","deleted_at":"2026-04-30T09:00:00Z"}}
+{"type":"add","addition":{"id":"providers/hashicorp/aws","type":"provider","addr":"hashicorp/aws","version":"v5.0.0","title":"aws","description":"","link":{"namespace":"hashicorp","name":"aws","version":"v5.0.0"},"parent_id":"","last_updated":"2026-05-01T09:00:00Z","popularity":10,"warnings":0}}
+`
+
+// readAll drains a source in batches of two
+func readAll(t *testing.T, source itemSource) []SearchIndexItem {
+	t.Helper()
+	var items []SearchIndexItem
+	for {
+		batch, err := source.Next(2)
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if len(batch) == 0 {
+			return items
+		}
+		items = append(items, batch...)
+	}
+}
+
+func checkFeed(t *testing.T, source itemSource) {
+	t.Helper()
+	defer func() { _ = source.Close() }()
+
+	if want := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC); !source.LastUpdated().Equal(want) {
+		t.Errorf("LastUpdated() = %v, want %v", source.LastUpdated(), want)
+	}
+
+	items := readAll(t, source)
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	if items[0].Type != "delete" || items[0].Deletion.ID != "providers/example/old" {
+		t.Errorf("first item = %+v, want the deletion of providers/example/old", items[0])
+	}
+	if items[1].Type != "add" || items[1].Addition.Link.Namespace != "hashicorp" || items[1].Addition.Popularity != 10 {
+		t.Errorf("second item = %+v, want the addition of hashicorp/aws", items[1])
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.ndjson")
+	if err := os.WriteFile(path, []byte(testFeed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := openNDJSONSource(openFile(path))
+	if err != nil {
+		t.Fatalf("openNDJSONSource() error = %v", err)
+	}
+	checkFeed(t, source)
+
+	if _, err := openNDJSONSource(openFile(filepath.Join(t.TempDir(), "missing.ndjson"))); err == nil {
+		t.Error("openNDJSONSource() of a missing file succeeded")
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.ndjson")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openNDJSONSource(openFile(empty)); err == nil {
+		t.Error("openNDJSONSource() of an empty file succeeded")
+	}
+}
+
+func TestBucketSource(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/registry/search/search.ndjson" {
+			http.Error(w, `<Error><Code>NoSuchKey</Code></Error>`, http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, testFeed)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	source, err := openNDJSONSource(openBucketKey(ctx, "registry", "search/search.ndjson", server.URL, "auto"))
+	if err != nil {
+		t.Fatalf("openNDJSONSource() error = %v", err)
+	}
+	checkFeed(t, source)
+
+	if _, err := openNDJSONSource(openBucketKey(ctx, "registry", "missing.ndjson", server.URL, "auto")); err == nil {
+		t.Error("openNDJSONSource() of a missing key succeeded")
+	}
+}
+
+func TestStoredItem(t *testing.T) {
+	updated := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	deleted := time.Date(2026, 4, 30, 9, 0, 0, 0, time.UTC)
+	// The item column as written by generate-search-index, without last_updated
+	contents := []byte(`{"id":"providers/hashicorp/aws/resources/instance","type":"provider/resource","addr":"hashicorp/aws","version":"v5.0.0","title":"aws_instance","description":"Provides an EC2 instance.","link":{"id":"instance","name":"aws","namespace":"hashicorp","version":"v5.0.0"},"parent_id":"providers/hashicorp/aws","last_updated":"0001-01-01T00:00:00Z","popularity":10,"warnings":1}`)
+
+	addition, err := storedItem("providers/hashicorp/aws/resources/instance", contents, updated, sql.NullTime{})
+	if err != nil {
+		t.Fatalf("storedItem() error = %v", err)
+	}
+	want := SearchIndexAddition{
+		ID: "providers/hashicorp/aws/resources/instance", Type: "provider/resource", Addr: "hashicorp/aws",
+		Version: "v5.0.0", Title: "aws_instance", Description: "Provides an EC2 instance.",
+		Link:     LinkVariables{ID: "instance", Name: "aws", Namespace: "hashicorp", Version: "v5.0.0"},
+		ParentID: "providers/hashicorp/aws", LastUpdated: updated, Popularity: 10, Warnings: 1,
+	}
+	if addition.Type != "add" || !reflect.DeepEqual(addition.Addition, want) {
+		t.Errorf("storedItem() = %+v, want the addition %+v", addition, want)
+	}
+
+	deletion, err := storedItem("providers/example/old", contents, updated, sql.NullTime{Time: deleted, Valid: true})
+	if err != nil {
+		t.Fatalf("storedItem() error = %v", err)
+	}
+	if deletion.Type != "delete" || deletion.Deletion.ID != "providers/example/old" || !deletion.Deletion.DeletedAt.Equal(deleted) {
+		t.Errorf("storedItem() = %+v, want the deletion of providers/example/old", deletion)
+	}
+
+	if _, err := storedItem("providers/broken", []byte("{"), updated, sql.NullTime{}); err == nil {
+		t.Error("storedItem() of invalid contents succeeded")
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	since := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	add := func(updated time.Time) SearchIndexItem {
+		item := SearchIndexItem{Type: "add"}
+		item.Addition.LastUpdated = updated
+		return item
+	}
+	deletion := func(deleted time.Time) SearchIndexItem {
+		item := SearchIndexItem{Type: "delete"}
+		item.Deletion.DeletedAt = deleted
+		return item
+	}
+
+	tests := []struct {
+		name  string
+		item  SearchIndexItem
+		since time.Time
+		want  bool
+	}{
+		{"full import", add(since.Add(-time.Hour)), time.Time{}, true},
+		{"updated after the last import", add(since.Add(time.Minute)), since, true},
+		{"already imported", add(since), since, false},
+		{"old deletion", deletion(since.Add(-time.Hour)), since, true},
+		{"new deletion", deletion(since.Add(time.Hour)), since, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changedSince(tt.item, tt.since); got != tt.want {
+				t.Errorf("changedSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}